@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/openkcm/common-sdk/pkg/commoncfg"
-
 	slogctx "github.com/veqryn/slog-context"
 
 	sessionmanager "github.com/openkcm/session-manager"
@@ -23,6 +21,12 @@ func Main(ctx context.Context, cfg *config.Config) error {
 
 	c = config.WithContext(c, cfg)
 
+	// The modules create session managers when they are provisioned, so the
+	// secrets must be loaded before
+	if err := cfg.SessionManager.LoadSecrets(); err != nil {
+		return fmt.Errorf("loading session manager secrets: %w", err)
+	}
+
 	// Build one graph: the shared top-level modules plus every app and its
 	// service children. LoadAll validates the whole graph, then provisions in
 	// topological order (dependencies before dependents, services before the
@@ -51,8 +55,9 @@ func Main(ctx context.Context, cfg *config.Config) error {
 		return fmt.Errorf("starting apps: %w", err)
 	}
 
-	// errChan captures the first error and triggers shutdown.
-	errChan := make(chan error, 1)
+	// errChan captures the first error and triggers shutdown. It buffers the
+	// results of all goroutines, so none of them blocks on shutdown.
+	errChan := make(chan error, 2)
 
 	// wg is used to wait for all goroutines to shutdown.
 	var wg sync.WaitGroup
@@ -62,6 +67,14 @@ func Main(ctx context.Context, cfg *config.Config) error {
 		errChan <- publicMain(c, cfg)
 	})
 
+	// run the house keeping jobs next to the server, e.g. for a session store
+	// which is not shared with a housekeeper process
+	if cfg.Housekeeper.InProcess {
+		wg.Go(func() {
+			errChan <- runHousekeeping(c, cfg)
+		})
+	}
+
 	// wait for any error to initiate the shutdown
 	err = <-errChan
 	if err != nil {
@@ -78,16 +91,6 @@ func Main(ctx context.Context, cfg *config.Config) error {
 
 // publicMain starts the HTTP REST public API server.
 func publicMain(ctx *sessionmanager.Context, cfg *config.Config) error {
-	csrfSecret, err := commoncfg.LoadValueFromSourceRef(cfg.SessionManager.CSRFSecret)
-	if err != nil {
-		return fmt.Errorf("loading csrf token from source ref: %w", err)
-	}
-	if len(csrfSecret) < 32 {
-		return errors.New("CSRF secret must be at least 32 bytes")
-	}
-
-	cfg.SessionManager.CSRFSecretParsed = csrfSecret
-
 	trust, err := sessionmanager.GetModuleAs[sessionmanager.Trust](ctx, cfg.Trust.Module())
 	if err != nil {
 		return fmt.Errorf("getting trust module: %w", err)
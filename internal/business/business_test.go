@@ -1,46 +1,53 @@
 package business
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/openkcm/common-sdk/pkg/commoncfg"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
 
 	sessionmanager "github.com/openkcm/session-manager"
 	"github.com/openkcm/session-manager/internal/config"
+	_ "github.com/openkcm/session-manager/modules/standard"
 )
 
-func TestPublicMain_InvalidCSRFSecret(t *testing.T) {
+func TestMain_InvalidCSRFSecret(t *testing.T) {
 	cfg := &config.Config{
 		SessionManager: config.SessionManager{
 			CSRFSecret: commoncfg.SourceRef{Source: "file", File: commoncfg.CredentialFile{Path: "/nonexistent/file"}},
 		},
 	}
 
-	ctx, cancel := sessionmanager.NewContext(t.Context())
-	defer cancel(nil)
-
-	err := publicMain(ctx, cfg)
+	err := Main(t.Context(), cfg)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "loading csrf token from source ref")
 }
 
-func TestPublicMain_ShortCSRFSecret(t *testing.T) {
+func TestMain_ShortCSRFSecret(t *testing.T) {
 	cfg := &config.Config{
 		SessionManager: config.SessionManager{
 			CSRFSecret: commoncfg.SourceRef{Source: "embedded", Value: "short"},
 		},
 	}
 
-	ctx, cancel := sessionmanager.NewContext(t.Context())
-	defer cancel(nil)
-
-	err := publicMain(ctx, cfg)
+	err := Main(t.Context(), cfg)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "CSRF secret must be at least 32 bytes")
 }
 
-func TestMain_InvalidCSRFSecret(t *testing.T) {
+func TestMain_PublicServerInvalidCSRF(t *testing.T) {
 	cfg := &config.Config{
 		SessionManager: config.SessionManager{
 			CSRFSecret: commoncfg.SourceRef{Source: "file", File: commoncfg.CredentialFile{Path: "/nonexistent/file"}},
@@ -51,13 +58,138 @@ func TestMain_InvalidCSRFSecret(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestMain_PublicServerInvalidCSRF(t *testing.T) {
-	cfg := &config.Config{
-		SessionManager: config.SessionManager{
-			CSRFSecret: commoncfg.SourceRef{Source: "file", File: commoncfg.CredentialFile{Path: "/nonexistent/file"}},
-		},
+// stubModule is a module without any behaviour, e.g. a database which is not
+// used by any other module.
+type stubModule struct{ id string }
+
+func (s *stubModule) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  s.id,
+		New: func() sessionmanager.Module { return s },
 	}
+}
 
-	err := Main(t.Context(), cfg)
-	assert.Error(t, err)
+// stubTrustModule is a trust module without any trusts.
+type stubTrustModule struct{ id string }
+
+func (s *stubTrustModule) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  s.id,
+		New: func() sessionmanager.Module { return s },
+	}
+}
+
+func (*stubTrustModule) Apply(context.Context, *trustv1.Trust) error { return nil }
+func (*stubTrustModule) Block(context.Context, string) error         { return nil }
+func (*stubTrustModule) Remove(context.Context, string) error        { return nil }
+func (*stubTrustModule) Unblock(context.Context, string) error       { return nil }
+func (*stubTrustModule) Get(context.Context, string) (*trustv1.Trust, error) {
+	return nil, errors.New("no trust")
+}
+func (*stubTrustModule) ListByIssuer(context.Context, string) ([]*trustv1.Trust, error) {
+	return nil, nil
+}
+
+// loadTestConfig loads a config which uses the in-memory session store and
+// enables request objects. The public HTTP server listens on the returned unix
+// socket.
+func loadTestConfig(t *testing.T) (*config.Config, string) {
+	t.Helper()
+
+	dbID := "database.module.test." + t.Name()
+	sessionmanager.RegisterModule(&stubModule{id: dbID})
+	trustID := "trust.module.test." + t.Name()
+	sessionmanager.RegisterModule(&stubTrustModule{id: trustID})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "request-object.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	socket := filepath.Join(dir, "http.sock")
+
+	yaml := `
+http:
+  address: unix://` + socket + `
+grpc:
+  address: 127.0.0.1:0
+database:
+  module: ` + dbID + `
+trust:
+  module: ` + trustID + `
+valkey:
+  module: sessionstore.module.memory
+  prefix: ` + t.Name() + `
+sessionManager:
+  callbackURL: http://localhost/sm/callback
+  clientAuth:
+    type: insecure
+  csrfSecret:
+    source: embedded
+    value: 0123456789abcdef0123456789abcdef
+  requestObject:
+    enabled: true
+    signingKey:
+      source: file
+      file:
+        path: ` + keyFile + `
+housekeeper:
+  triggerInterval: 1m
+apps:
+  grpc:
+    module: app.module.grpcserver
+    services:
+      - module: service.module.grpc.session
+        trust: ` + trustID + `
+        sessionStore: sessionstore.module.memory
+      - module: service.module.grpc.extauthz
+        trust: ` + trustID + `
+        sessionStore: sessionstore.module.memory
+        tenantHeader: x-tenant-id
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0o600))
+
+	cfg, err := config.Load("", dir)
+	require.NoError(t, err)
+
+	return cfg, socket
+}
+
+func TestMain_RequestObjectEnabled(t *testing.T) {
+	cfg, socket := loadTestConfig(t)
+
+	stop := startMain(t, cfg, socket)
+	assert.NoError(t, stop())
+}
+
+// startMain runs Main until the public server listens on the socket. The
+// returned function stops Main and returns its error.
+func startMain(t *testing.T, cfg *config.Config, socket string) func() error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- Main(ctx, cfg)
+	}()
+
+	// The public server listens once the modules are provisioned
+	for _, err := os.Stat(socket); err != nil; _, err = os.Stat(socket) {
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+			require.FailNow(t, "Main returned before the server started")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	return func() error {
+		cancel()
+		return <-errChan
+	}
 }
@@ -19,6 +19,10 @@ func HousekeeperMain(ctx context.Context, cfg *config.Config) error {
 
 	c = config.WithContext(c, cfg)
 
+	if err := cfg.SessionManager.LoadSecrets(); err != nil {
+		return fmt.Errorf("loading session manager secrets: %w", err)
+	}
+
 	if err := c.LoadAll([]sessionmanager.LoadSpec{
 		{Cfg: &cfg.Database},
 		{Cfg: &cfg.Trust},
@@ -28,12 +32,17 @@ func HousekeeperMain(ctx context.Context, cfg *config.Config) error {
 		return fmt.Errorf("loading shared modules: %w", err)
 	}
 
-	trust, err := sessionmanager.GetModuleAs[sessionmanager.Trust](c, cfg.Trust.Module())
+	return runHousekeeping(c, cfg)
+}
+
+// runHousekeeping runs the house keeping jobs until the context is done.
+func runHousekeeping(ctx *sessionmanager.Context, cfg *config.Config) error {
+	trust, err := sessionmanager.GetModuleAs[sessionmanager.Trust](ctx, cfg.Trust.Module())
 	if err != nil {
 		return fmt.Errorf("getting trust module: %w", err)
 	}
 
-	sessionManager, closeFn, err := sessionwiring.InitSessionManager(c, cfg, trust)
+	sessionManager, closeFn, err := sessionwiring.InitSessionManager(ctx, cfg, trust)
 	if err != nil {
 		return fmt.Errorf("failed to initialise the session manager: %w", err)
 	}
@@ -44,7 +53,7 @@ func HousekeeperMain(ctx context.Context, cfg *config.Config) error {
 	refreshTriggerInterval := cfg.Housekeeper.TokenRefreshTriggerInterval
 	concurrencyLimit := cfg.Housekeeper.ConcurrencyLimit
 	for {
-		err := sessionManager.TriggerHousekeeping(c, concurrencyLimit, refreshTriggerInterval)
+		err := sessionManager.TriggerHousekeeping(ctx, concurrencyLimit, refreshTriggerInterval)
 		if err != nil {
 			slogctx.Error(ctx, "Error during session housekeeping", "error", err)
 		}
@@ -52,7 +61,7 @@ func HousekeeperMain(ctx context.Context, cfg *config.Config) error {
 		select {
 		case <-tick:
 			continue
-		case <-c.Done():
+		case <-ctx.Done():
 			return nil
 		}
 	}
@@ -3,11 +3,15 @@ package business
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/openkcm/common-sdk/pkg/commoncfg"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	sessionmanager "github.com/openkcm/session-manager"
 	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/session"
 )
 
 func TestHousekeeperMain_CancelledContext(t *testing.T) {
@@ -18,6 +22,7 @@ func TestHousekeeperMain_CancelledContext(t *testing.T) {
 			Password: commoncfg.SourceRef{Source: "embedded", Value: "pass"},
 		},
 		SessionManager: config.SessionManager{
+			CSRFSecret: commoncfg.SourceRef{Source: "embedded", Value: "0123456789abcdef0123456789abcdef"},
 			ClientAuth: config.ClientAuth{
 				Type: "insecure",
 			},
@@ -32,3 +37,52 @@ func TestHousekeeperMain_CancelledContext(t *testing.T) {
 	// When context is already cancelled, initSessionManager will fail
 	assert.Error(t, err)
 }
+
+func TestHousekeeperMain_ShortCSRFSecret(t *testing.T) {
+	cfg := &config.Config{
+		SessionManager: config.SessionManager{
+			CSRFSecret: commoncfg.SourceRef{Source: "embedded", Value: "short"},
+		},
+	}
+
+	err := HousekeeperMain(t.Context(), cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CSRF secret must be at least 32 bytes")
+}
+
+func TestHousekeeperMain_RequestObjectEnabled(t *testing.T) {
+	cfg, _ := loadTestConfig(t)
+
+	// Use an already cancelled context to stop after the first run
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := HousekeeperMain(ctx, cfg)
+	assert.NoError(t, err)
+}
+
+func TestMain_InProcessHousekeeping(t *testing.T) {
+	cfg, socket := loadTestConfig(t)
+	cfg.Housekeeper.InProcess = true
+
+	// The in-memory session store is shared by the modules of one process
+	c, cancel := sessionmanager.NewContext(t.Context())
+	defer cancel(nil)
+	require.NoError(t, c.LoadAll([]sessionmanager.LoadSpec{{Cfg: &cfg.ValKey}}))
+	repo, err := sessionmanager.GetModuleAs[session.Repository](c, cfg.ValKey.Module())
+	require.NoError(t, err)
+
+	// Idle sessions are deleted by the housekeeping jobs
+	require.NoError(t, repo.StoreSession(c, session.Session{
+		ID:       "idle-session",
+		TenantID: "tenant-123",
+		Expiry:   time.Now().Add(time.Hour),
+	}))
+
+	stop := startMain(t, cfg, socket)
+	assert.Eventually(t, func() bool {
+		sessions, err := repo.ListSessions(c)
+		return err == nil && len(sessions) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, stop())
+}
@@ -40,5 +40,33 @@ func MigrateMain(ctx context.Context, cfg *config.Config) error {
 		return fmt.Errorf("executing migrations: %w", err)
 	}
 
+	// Session stores keeping their sessions in the database, e.g.
+	// sessionstore.module.postgres, migrate their own tables. Other stores are
+	// not loaded so that no connection is opened to them.
+	storeInfo, err := sessionmanager.GetModule(cfg.ValKey.Module())
+	if err != nil {
+		return fmt.Errorf("getting session store module: %w", err)
+	}
+	if _, ok := storeInfo.New().(sessionmanager.Migrate); !ok {
+		return nil
+	}
+
+	slogctx.Debug(c, "loading session store module")
+	if err = c.LoadAll([]sessionmanager.LoadSpec{
+		{Cfg: &cfg.ValKey},
+	}); err != nil {
+		return fmt.Errorf("loading session store module: %w", err)
+	}
+
+	storeMigrate, err := sessionmanager.GetModuleAs[sessionmanager.Migrate](c, cfg.ValKey.Module())
+	if err != nil {
+		return fmt.Errorf("getting session store module: %w", err)
+	}
+
+	slogctx.Debug(c, "executing session store migration")
+	if err := storeMigrate.Migrate(ctx); err != nil {
+		return fmt.Errorf("executing session store migrations: %w", err)
+	}
+
 	return nil
 }
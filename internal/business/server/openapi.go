@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/openkcm/common-sdk/pkg/csrf"
 	"go.opentelemetry.io/otel"
@@ -22,7 +24,7 @@ import (
 // sessionManager defines the interface for session management operations
 // used by the OpenAPI server.
 type sessionManager interface {
-	MakeAuthURI(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error)
+	MakeAuthURI(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error)
 	FinaliseOIDCLogin(ctx context.Context, state, code string) (session.OIDCSessionData, error)
 	MakeSessionCookie(ctx context.Context, tenantID, sessionID string) (*http.Cookie, error)
 	MakeCSRFCookie(ctx context.Context, tenantID, csrfToken string) (*http.Cookie, error)
@@ -30,6 +32,7 @@ type sessionManager interface {
 	LoadState(ctx context.Context, stateID string) (session.State, error)
 	Logout(ctx context.Context, sessionID, postLogoutRedirectURL string) (string, error)
 	BCLogout(ctx context.Context, logoutToken string) error
+	FCLogout(ctx context.Context, issuer, sid string) (string, error)
 }
 
 // openAPIServer is an implementation of the OpenAPI interface.
@@ -87,7 +90,7 @@ func (s *openAPIServer) Auth(ctx context.Context, request openapi.AuthRequestObj
 		return s.authErrorResponse(ctx, errorURI, svcerr), nil
 	}
 
-	url, csrfToken, err := s.sManager.MakeAuthURI(ctx, request.Params.TenantID, request.Params.RequestURI, errorURI)
+	url, csrfToken, err := s.sManager.MakeAuthURI(ctx, request.Params.TenantID, request.Params.RequestURI, errorURI, stepUpFromParams(request.Params))
 	if err != nil {
 		serviceerr.RecordAndLogError(ctx, span, err, "error", err)
 		return s.authErrorResponse(ctx, errorURI, err), nil
@@ -108,6 +111,23 @@ func (s *openAPIServer) Auth(ctx context.Context, request openapi.AuthRequestObj
 	}, nil
 }
 
+// stepUpFromParams returns the step-up requirements requested by the auth parameters.
+func stepUpFromParams(params openapi.AuthParams) session.StepUp {
+	var stepUp session.StepUp
+	if params.AcrValues != nil {
+		stepUp.ACRValues = strings.Fields(*params.AcrValues)
+	}
+	if params.MaxAge != nil {
+		maxAge := time.Duration(*params.MaxAge) * time.Second
+		stepUp.MaxAge = &maxAge
+	}
+	if params.Prompt != nil && *params.Prompt == openapi.Login {
+		stepUp.ForceLogin = true
+	}
+
+	return stepUp
+}
+
 // authErrorResponse returns either a redirect to the error page or a JSON error response for the Auth endpoint.
 func (s *openAPIServer) authErrorResponse(ctx context.Context, errorURI string, err error) openapi.AuthResponseObject {
 	if redirectURL := s.buildErrorRedirectURL(ctx, errorURI, err); redirectURL != "" {
@@ -335,14 +355,129 @@ func (s *openAPIServer) Bclogout(ctx context.Context, request openapi.BclogoutRe
 	slogctx.Debug(ctx, "Bclogout() called")
 	defer slogctx.Debug(ctx, "Bclogout() completed")
 
+	if request.Body == nil || request.Body.LogoutToken == "" {
+		body, _ := newBadRequest("missing logout_token")
+		return newBclogout400Response(body), nil
+	}
+
 	if err := s.sManager.BCLogout(ctx, request.Body.LogoutToken); err != nil {
 		serviceerr.RecordAndLogError(ctx, span, err, "error", err)
+
+		// The provider only learns that the logout token was rejected or that
+		// the logout failed otherwise and may be retried. Any details would only
+		// reveal internals of the session manager.
+		var serviceErr *serviceerr.Error
+		if !errors.As(err, &serviceErr) {
+			return openapi.Bclogout500JSONResponse{
+				Body:    openapi.ErrorModel{Error: string(serviceerr.CodeServerError)},
+				Headers: openapi.Bclogout500ResponseHeaders{CacheControl: "no-store"},
+			}, nil
+		}
+
+		body := openapi.ErrorModel{Error: string(serviceerr.CodeInvalidRequest)}
+		if serviceErr.Err == serviceerr.CodeInvalidRequest && serviceErr.Description != "" {
+			body.ErrorDescription = &serviceErr.Description
+		}
+
+		return newBclogout400Response(body), nil
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return openapi.Bclogout200Response{
+		Headers: openapi.Bclogout200ResponseHeaders{CacheControl: "no-store"},
+	}, nil
+}
+
+// newBclogout400Response returns the error response of the back-channel logout
+// as described in https://openid.net/specs/openid-connect-backchannel-1_0.html#BCResponse.
+func newBclogout400Response(body openapi.ErrorModel) openapi.Bclogout400JSONResponse {
+	return openapi.Bclogout400JSONResponse{
+		Body:    body,
+		Headers: openapi.Bclogout400ResponseHeaders{CacheControl: "no-store"},
+	}
+}
+
+// Fclogout implements openapi.StrictServerInterface.
+func (s *openAPIServer) Fclogout(ctx context.Context, request openapi.FclogoutRequestObject) (openapi.FclogoutResponseObject, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "fc_logout")
+	defer span.End()
+
+	slogctx.Debug(ctx, "Fclogout() called", "issuer", request.Params.Iss)
+	defer slogctx.Debug(ctx, "Fclogout() completed")
+
+	frameAncestor, err := frameAncestorSource(request.Params.Iss)
+	if err != nil {
+		serviceerr.RecordAndLogError(ctx, span, err, "error", err)
+		body, _ := newBadRequest("invalid 'iss' parameter")
+		return newFclogout400Response(body), nil
+	}
+
+	rw, err := middleware.ResponseWriterFromContext(ctx)
+	if err != nil {
+		serviceerr.RecordAndLogError(ctx, span, err, "error", err)
+		body, _ := s.toErrorModel(serviceerr.ErrUnknown)
+		return newFclogout400Response(body), nil
+	}
+
+	tenantID, err := s.sManager.FCLogout(ctx, request.Params.Iss, request.Params.Sid)
+	if err != nil {
+		serviceerr.RecordAndLogError(ctx, span, err, "error", err)
 		body, _ := s.toErrorModel(err)
-		return openapi.Bclogout400JSONResponse(body), nil
+		return newFclogout400Response(body), nil
+	}
+
+	if tenantID != "" {
+		for _, makeCookie := range []func(ctx context.Context, tenantID, value string) (*http.Cookie, error){
+			s.sManager.MakeSessionCookie,
+			s.sManager.MakeCSRFCookie,
+		} {
+			cookie, err := makeCookie(ctx, tenantID, "")
+			if err != nil {
+				serviceerr.RecordAndLogError(ctx, span, err, "error", err)
+				continue
+			}
+
+			cookie.MaxAge = -1
+			http.SetCookie(rw, cookie)
+		}
 	}
 
+	// The provider renders the page in an iframe. The frame-ancestors directive
+	// supersedes the global X-Frame-Options: DENY, which is removed for browsers
+	// without CSP support.
+	rw.Header().Del("X-Frame-Options")
+
 	span.SetStatus(codes.Ok, "")
-	return openapi.Bclogout200Response{}, nil
+	return openapi.Fclogout200TextHTMLResponse{
+		Body: strings.NewReader(""),
+		Headers: openapi.Fclogout200ResponseHeaders{
+			CacheControl:          "no-store",
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors " + frameAncestor + "; base-uri 'none'; form-action 'none';",
+		},
+	}, nil
+}
+
+func newFclogout400Response(body openapi.ErrorModel) openapi.Fclogout400JSONResponse {
+	return openapi.Fclogout400JSONResponse{
+		Body:    body,
+		Headers: openapi.Fclogout400ResponseHeaders{CacheControl: "no-store"},
+	}
+}
+
+// frameAncestorSource returns the origin of the issuer as a source expression
+// of the frame-ancestors directive.
+func frameAncestorSource(issuer string) (string, error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return "", fmt.Errorf("parsing issuer: %w", err)
+	}
+
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || strings.ContainsAny(u.Host, " ;,'\"") {
+		return "", fmt.Errorf("issuer %q is not a valid origin", issuer)
+	}
+
+	return u.Scheme + "://" + u.Host, nil
 }
 
 func (s *openAPIServer) toErrorModel(err error) (model openapi.ErrorModel, httpStatus int) {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/openkcm/common-sdk/pkg/csrf"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +26,7 @@ const (
 
 // mockSessionManager is a mock implementation of sessionManager interface for testing
 type mockSessionManager struct {
-	makeAuthURIFunc         func(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error)
+	makeAuthURIFunc         func(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error)
 	finaliseOIDCLoginFunc   func(ctx context.Context, state, code string) (session.OIDCSessionData, error)
 	makeSessionCookieFunc   func(ctx context.Context, tenantID, sessionID string) (*http.Cookie, error)
 	makeCSRFCookieFunc      func(ctx context.Context, tenantID, csrfToken string) (*http.Cookie, error)
@@ -33,11 +34,12 @@ type mockSessionManager struct {
 	loadStateFunc           func(ctx context.Context, stateID string) (session.State, error)
 	logoutFunc              func(ctx context.Context, sessionID, postLogoutRedirectURL string) (string, error)
 	bcLogoutFunc            func(ctx context.Context, logoutToken string) error
+	fcLogoutFunc            func(ctx context.Context, issuer, sid string) (string, error)
 }
 
-func (m *mockSessionManager) MakeAuthURI(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error) {
+func (m *mockSessionManager) MakeAuthURI(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error) {
 	if m.makeAuthURIFunc != nil {
-		return m.makeAuthURIFunc(ctx, tenantID, requestURI, errorURI)
+		return m.makeAuthURIFunc(ctx, tenantID, requestURI, errorURI, stepUp)
 	}
 	return "", "", errors.New("not implemented")
 }
@@ -91,6 +93,13 @@ func (m *mockSessionManager) BCLogout(ctx context.Context, logoutToken string) e
 	return errors.New("not implemented")
 }
 
+func (m *mockSessionManager) FCLogout(ctx context.Context, issuer, sid string) (string, error) {
+	if m.fcLogoutFunc != nil {
+		return m.fcLogoutFunc(ctx, issuer, sid)
+	}
+	return "", errors.New("not implemented")
+}
+
 func TestNewOpenAPIServer(t *testing.T) {
 	t.Run("creates server with all parameters", func(t *testing.T) {
 		csrfSecret := []byte("test-secret")
@@ -129,7 +138,7 @@ func TestOpenAPIServer_Auth_DisallowedRequestURI(t *testing.T) {
 
 func TestOpenAPIServer_Auth_MakeAuthURI_NilManager(t *testing.T) {
 	mock := &mockSessionManager{
-		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error) {
+		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error) {
 			return "", "", errors.New("context canceled")
 		},
 	}
@@ -150,7 +159,7 @@ func TestOpenAPIServer_Auth_MakeAuthURI_NilManager(t *testing.T) {
 
 func TestOpenAPIServer_Auth_MakeAuthURI_Failed(t *testing.T) {
 	mock := &mockSessionManager{
-		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error) {
+		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error) {
 			return "", "", errors.New("error")
 		},
 	}
@@ -171,7 +180,7 @@ func TestOpenAPIServer_Auth_MakeAuthURI_Failed(t *testing.T) {
 
 func TestOpenAPIServer_Auth_MakeCSRFCookie_Failed(t *testing.T) {
 	mock := &mockSessionManager{
-		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error) {
+		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error) {
 			return "https://example.com/redirect", "token", nil
 		},
 		makeLoginCSRFCookieFunc: func(ctx context.Context, csrfToken string) (*http.Cookie, error) {
@@ -194,7 +203,7 @@ func TestOpenAPIServer_Auth_MakeCSRFCookie_Failed(t *testing.T) {
 
 func TestOpenAPIServer_Auth_MakeAuthURI_Success(t *testing.T) {
 	mock := &mockSessionManager{
-		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error) {
+		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error) {
 			return "https://example.com/redirect", "token", nil
 		},
 		makeLoginCSRFCookieFunc: func(ctx context.Context, csrfToken string) (*http.Cookie, error) {
@@ -218,6 +227,38 @@ func TestOpenAPIServer_Auth_MakeAuthURI_Success(t *testing.T) {
 	assert.Equal(t, "csrf-token=token", r.Headers.SetCookie)
 }
 
+func TestOpenAPIServer_Auth_StepUp(t *testing.T) {
+	var gotStepUp session.StepUp
+	mock := &mockSessionManager{
+		makeAuthURIFunc: func(ctx context.Context, tenantID, requestURI, errorURI string, stepUp session.StepUp) (string, string, error) {
+			gotStepUp = stepUp
+			return "https://example.com/redirect", "token", nil
+		},
+		makeLoginCSRFCookieFunc: func(ctx context.Context, csrfToken string) (*http.Cookie, error) {
+			return &http.Cookie{Name: "csrf-token", Value: csrfToken}, nil
+		},
+	}
+	server := newOpenAPIServer(mock, nil, "", "", []string{"https://example.com"})
+	prompt := openapi.Login
+	req := openapi.AuthRequestObject{
+		Params: openapi.AuthParams{
+			RequestURI: "https://example.com/redirect",
+			AcrValues:  new(" urn:mfa  urn:hwk "),
+			MaxAge:     new(300),
+			Prompt:     &prompt,
+		},
+	}
+	resp, err := server.Auth(t.Context(), req)
+	assert.NoError(t, err)
+	assert.IsType(t, openapi.Auth302Response{}, resp)
+
+	assert.Equal(t, session.StepUp{
+		ACRValues:  []string{"urn:mfa", "urn:hwk"},
+		MaxAge:     new(5 * time.Minute),
+		ForceLogin: true,
+	}, gotStepUp)
+}
+
 func TestOpenAPIServer_Callback_ContextCanceled(t *testing.T) {
 	ctx, cancel := context.WithCancel(t.Context())
 	cancel()
@@ -735,33 +776,79 @@ func TestOpenAPIServer_Bclogout_Success(t *testing.T) {
 		resp, err := server.Bclogout(context.Background(), bclogoutReq)
 
 		require.NoError(t, err)
-		assert.IsType(t, openapi.Bclogout200Response{}, resp)
+		assert.Equal(t, openapi.Bclogout200Response{
+			Headers: openapi.Bclogout200ResponseHeaders{CacheControl: "no-store"},
+		}, resp)
 	})
 }
 
 func TestOpenAPIServer_Bclogout_Error(t *testing.T) {
-	t.Run("returns 400 when BCLogout fails", func(t *testing.T) {
+	tests := []struct {
+		name            string
+		logoutToken     string
+		err             error
+		wantDescription *string
+	}{
+		{
+			name:            "Missing logout token",
+			wantDescription: new("missing logout_token"),
+		},
+		{
+			name:        "Invalid logout token",
+			logoutToken: "invalid-token",
+			err:         serviceerr.ErrInvalidRequest,
+		},
+		{
+			name:        "Other service error",
+			logoutToken: "invalid-token",
+			err:         serviceerr.ErrInvalidCSRFToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockSessionManager{
+				bcLogoutFunc: func(ctx context.Context, logoutToken string) error {
+					assert.Equal(t, tt.logoutToken, logoutToken)
+					return tt.err
+				},
+			}
+			server := newOpenAPIServer(mock, nil, "", "", []string{allowedBaseURL})
+
+			bclogoutReq := openapi.BclogoutRequestObject{
+				Body: &openapi.BclogoutFormdataRequestBody{
+					LogoutToken: tt.logoutToken,
+				},
+			}
+
+			resp, err := server.Bclogout(context.Background(), bclogoutReq)
+			require.NoError(t, err)
+
+			r, ok := resp.(openapi.Bclogout400JSONResponse)
+			require.True(t, ok)
+			assert.Equal(t, string(serviceerr.CodeInvalidRequest), r.Body.Error)
+			assert.Equal(t, tt.wantDescription, r.Body.ErrorDescription)
+			assert.Equal(t, "no-store", r.Headers.CacheControl)
+		})
+	}
+
+	t.Run("Internal error", func(t *testing.T) {
 		mock := &mockSessionManager{
 			bcLogoutFunc: func(ctx context.Context, logoutToken string) error {
-				return serviceerr.ErrInvalidCSRFToken
+				return errors.New("valkey unavailable")
 			},
 		}
 		server := newOpenAPIServer(mock, nil, "", "", []string{allowedBaseURL})
 
-		bclogoutReq := openapi.BclogoutRequestObject{
-			Body: &openapi.BclogoutFormdataRequestBody{
-				LogoutToken: "invalid-token",
-			},
-		}
-
-		resp, err := server.Bclogout(context.Background(), bclogoutReq)
-
+		resp, err := server.Bclogout(context.Background(), openapi.BclogoutRequestObject{
+			Body: &openapi.BclogoutFormdataRequestBody{LogoutToken: "logout-token"},
+		})
 		require.NoError(t, err)
-		assert.IsType(t, openapi.Bclogout400JSONResponse{}, resp)
 
-		r, ok := resp.(openapi.Bclogout400JSONResponse)
-		require.True(t, ok)
-		assert.Equal(t, string(serviceerr.CodeInvalidCSRFToken), r.Error)
+		r, ok := resp.(openapi.Bclogout500JSONResponse)
+		require.True(t, ok, "internal errors must let the provider retry the logout")
+		assert.Equal(t, string(serviceerr.CodeServerError), r.Body.Error)
+		assert.Nil(t, r.Body.ErrorDescription)
+		assert.Equal(t, "no-store", r.Headers.CacheControl)
 	})
 }
 
@@ -810,6 +897,101 @@ func TestOpenAPIServer_Logout_Success(t *testing.T) {
 	})
 }
 
+func TestOpenAPIServer_Fclogout(t *testing.T) {
+	const (
+		issuer   = "https://idp.example.com/realms/tenant"
+		sid      = "provider-sid"
+		tenantID = "tenant-1"
+	)
+
+	makeCookie := func(ctx context.Context, tenantID, value string) (*http.Cookie, error) {
+		return &http.Cookie{Name: "cookie-" + tenantID, Value: value, Path: "/"}, nil
+	}
+
+	tests := []struct {
+		name        string
+		issuer      string
+		tenantID    string
+		err         error
+		wantStatus  int
+		wantCSP     string
+		wantCookies int
+	}{
+		{
+			name:        "Session terminated",
+			issuer:      issuer,
+			tenantID:    tenantID,
+			wantStatus:  http.StatusOK,
+			wantCSP:     "default-src 'none'; frame-ancestors https://idp.example.com; base-uri 'none'; form-action 'none';",
+			wantCookies: 2,
+		},
+		{
+			name:       "Session not open",
+			issuer:     issuer,
+			wantStatus: http.StatusOK,
+			wantCSP:    "default-src 'none'; frame-ancestors https://idp.example.com; base-uri 'none'; form-action 'none';",
+		},
+		{
+			name:       "Untrusted issuer",
+			issuer:     issuer,
+			err:        serviceerr.ErrInvalidRequest,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid issuer",
+			issuer:     "https://idp.example.com;script-src *",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Issuer without origin",
+			issuer:     "urn:idp",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockSessionManager{
+				fcLogoutFunc: func(ctx context.Context, gotIssuer, gotSID string) (string, error) {
+					assert.Equal(t, tt.issuer, gotIssuer)
+					assert.Equal(t, sid, gotSID)
+					return tt.tenantID, tt.err
+				},
+				makeSessionCookieFunc: makeCookie,
+				makeCSRFCookieFunc:    makeCookie,
+			}
+			server := newOpenAPIServer(mock, nil, "session-id", "csrf-token", []string{allowedBaseURL})
+
+			rw := httptest.NewRecorder()
+			rw.Header().Set("X-Frame-Options", "DENY")
+			ctx := context.WithValue(context.Background(), middleware.ResponseWriterKey, rw)
+
+			resp, err := server.Fclogout(ctx, openapi.FclogoutRequestObject{
+				Params: openapi.FclogoutParams{Iss: tt.issuer, Sid: sid},
+			})
+			require.NoError(t, err)
+			require.NoError(t, resp.VisitFclogoutResponse(rw))
+
+			assert.Equal(t, tt.wantStatus, rw.Code)
+			assert.Equal(t, "no-store", rw.Header().Get("Cache-Control"))
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.wantCSP, rw.Header().Get("Content-Security-Policy"))
+			assert.Empty(t, rw.Header().Get("X-Frame-Options"))
+			assert.Empty(t, rw.Body.String())
+
+			cookies := rw.Result().Cookies()
+			assert.Len(t, cookies, tt.wantCookies)
+			for _, cookie := range cookies {
+				assert.Equal(t, "cookie-"+tenantID, cookie.Name)
+				assert.Equal(t, -1, cookie.MaxAge)
+				assert.Empty(t, cookie.Value)
+			}
+		})
+	}
+}
+
 func Test_urlWithErrorCodeAndDescription(t *testing.T) {
 	t.Run("absolute with hash based routing", func(t *testing.T) {
 		result := urlWithErrorCodeAndDescription("https://my.domain/#/foo/bar", "code", "desc")
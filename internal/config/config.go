@@ -123,6 +123,9 @@ type Housekeeper struct {
 	// TokenRefreshTriggerInterval defines the duration before token expiry when a token refresh should be triggered.
 	// This should at least match the TriggerInterval to ensure that expiring tokens are refreshed in time.
 	TokenRefreshTriggerInterval time.Duration `yaml:"tokenRefreshTriggerInterval" default:"15m"`
+	// InProcess runs the housekeeper jobs in the api-server process as well. This
+	// is required for session stores which are not shared between processes.
+	InProcess bool `yaml:"inProcess" default:"false"`
 }
 
 type HTTPServer struct {
@@ -229,6 +232,40 @@ type SessionManager struct {
 	// mock IdP such as Dex during development). Defaults to false so production
 	// only trusts https:// issuers.
 	AllowHttpScheme bool `yaml:"allowHttpScheme" default:"false"`
+
+	// IDTokenLeeway is the clock skew tolerated when validating the time based claims of ID tokens.
+	IDTokenLeeway time.Duration `yaml:"idTokenLeeway" default:"1m"`
+
+	// LogoutTokenMaxAge is the maximum age of back-channel logout tokens. The IDs of
+	// accepted logout tokens are remembered for this long to detect replays.
+	LogoutTokenMaxAge time.Duration `yaml:"logoutTokenMaxAge" default:"5m"`
+
+	// RequestObject configures signed authorization request objects (JAR, RFC 9101).
+	RequestObject RequestObject `yaml:"requestObject"`
+
+	// Scopes are requested in the authorization requests of trusts which do not define their own scopes.
+	Scopes []string `yaml:"scopes" default:"[\"openid\",\"profile\",\"email\",\"groups\"]"`
+}
+
+// RequestObject configures how the authorization request parameters are passed
+// to the provider as a signed and optionally encrypted `request` parameter.
+type RequestObject struct {
+	// Enabled wraps the authorization request parameters into a request object.
+	Enabled bool `yaml:"enabled" default:"false"`
+	// SigningKey is the source of the PEM encoded private key used to sign the request objects.
+	SigningKey       commoncfg.SourceRef `yaml:"signingKey"`
+	SigningKeyParsed []byte              `yaml:"-"`
+	// SigningKeyID is set as the `kid` header of the request objects (optional).
+	SigningKeyID string `yaml:"signingKeyID"`
+	// SigningAlgorithm is the JWS algorithm used to sign the request objects.
+	SigningAlgorithm string `yaml:"signingAlgorithm" default:"PS256"`
+	// Encrypt additionally encrypts the signed request objects to an encryption
+	// key published in the provider's JWKS.
+	Encrypt bool `yaml:"encrypt" default:"false"`
+	// KeyEncryptionAlgorithm is the JWE key management algorithm used when Encrypt is set.
+	KeyEncryptionAlgorithm string `yaml:"keyEncryptionAlgorithm" default:"RSA-OAEP-256"`
+	// ContentEncryptionAlgorithm is the JWE content encryption algorithm used when Encrypt is set.
+	ContentEncryptionAlgorithm string `yaml:"contentEncryptionAlgorithm" default:"A256GCM"`
 }
 
 type CookieSameSiteValue string
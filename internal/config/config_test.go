@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -156,3 +157,75 @@ apps:
 	require.NoError(t, app.Services[1].UnmarshalExtension(svc1))
 	assert.Equal(t, "trust.module.alt", svc1.Trust)
 }
+
+func TestSessionManager_LoadSecrets(t *testing.T) {
+	const csrfSecret = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		cfg     SessionManager
+		wantErr string
+	}{
+		{
+			name: "CSRF secret",
+			cfg: SessionManager{
+				CSRFSecret: commoncfg.SourceRef{Source: "embedded", Value: csrfSecret},
+			},
+		},
+		{
+			name: "Request object signing key",
+			cfg: SessionManager{
+				CSRFSecret: commoncfg.SourceRef{Source: "embedded", Value: csrfSecret},
+				RequestObject: RequestObject{
+					Enabled:    true,
+					SigningKey: commoncfg.SourceRef{Source: "embedded", Value: "signing-key"},
+				},
+			},
+		},
+		{
+			name: "Missing CSRF secret",
+			cfg: SessionManager{
+				CSRFSecret: commoncfg.SourceRef{Source: "file", File: commoncfg.CredentialFile{Path: "/nonexistent/file"}},
+			},
+			wantErr: "loading csrf token from source ref",
+		},
+		{
+			name: "Short CSRF secret",
+			cfg: SessionManager{
+				CSRFSecret: commoncfg.SourceRef{Source: "embedded", Value: "short"},
+			},
+			wantErr: "CSRF secret must be at least 32 bytes",
+		},
+		{
+			name: "Missing request object signing key",
+			cfg: SessionManager{
+				CSRFSecret: commoncfg.SourceRef{Source: "embedded", Value: csrfSecret},
+				RequestObject: RequestObject{
+					Enabled:    true,
+					SigningKey: commoncfg.SourceRef{Source: "file", File: commoncfg.CredentialFile{Path: "/nonexistent/file"}},
+				},
+			},
+			wantErr: "loading request object signing key from source ref",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.LoadSecrets()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, []byte(csrfSecret), tt.cfg.CSRFSecretParsed)
+			if tt.cfg.RequestObject.Enabled {
+				assert.Equal(t, []byte("signing-key"), tt.cfg.RequestObject.SigningKeyParsed)
+			}
+
+			// Loaded secrets are kept
+			tt.cfg.CSRFSecret = commoncfg.SourceRef{}
+			require.NoError(t, tt.cfg.LoadSecrets())
+			assert.Equal(t, []byte(csrfSecret), tt.cfg.CSRFSecretParsed)
+		})
+	}
+}
@@ -78,6 +78,38 @@ func Load(buildInfo string, paths ...string) (*Config, error) {
 	return cfg, nil
 }
 
+// minCSRFSecretLength is the minimum length of the CSRF secret in bytes.
+const minCSRFSecretLength = 32
+
+// LoadSecrets loads the CSRF secret and, if request objects are enabled, their
+// signing key from the source refs. It must be called before the session
+// manager is created. Secrets which are loaded already are kept, so it is safe
+// to call it more than once.
+func (c *SessionManager) LoadSecrets() error {
+	if c.CSRFSecretParsed == nil {
+		csrfSecret, err := commoncfg.LoadValueFromSourceRef(c.CSRFSecret)
+		if err != nil {
+			return fmt.Errorf("loading csrf token from source ref: %w", err)
+		}
+		if len(csrfSecret) < minCSRFSecretLength {
+			return fmt.Errorf("CSRF secret must be at least %d bytes", minCSRFSecretLength)
+		}
+
+		c.CSRFSecretParsed = csrfSecret
+	}
+
+	if c.RequestObject.Enabled && c.RequestObject.SigningKeyParsed == nil {
+		signingKey, err := commoncfg.LoadValueFromSourceRef(c.RequestObject.SigningKey)
+		if err != nil {
+			return fmt.Errorf("loading request object signing key from source ref: %w", err)
+		}
+
+		c.RequestObject.SigningKeyParsed = signingKey
+	}
+
+	return nil
+}
+
 var koanfSetterType = reflect.TypeFor[koanfSetter]()
 
 func setKoanf(v reflect.Value, ko *koanf.Koanf) {
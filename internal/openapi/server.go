@@ -9,12 +9,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/oapi-codegen/runtime"
 	strictnethttp "github.com/oapi-codegen/runtime/strictmiddleware/nethttp"
 )
 
+// Defines values for AuthParamsPrompt.
+const (
+	Login AuthParamsPrompt = "login"
+)
+
 // ErrorModel defines model for ErrorModel.
 type ErrorModel struct {
 	Error            string  `json:"error"`
@@ -31,8 +37,23 @@ type AuthParams struct {
 	// with an appended errorCode query parameter instead of returning a JSON error body.
 	// Example: https://openkcm.com/#/tenantID/forbidden
 	ErrorURI *string `form:"error_uri,omitempty" json:"error_uri,omitempty"`
+
+	// AcrValues Space separated authentication context class references in order of preference.
+	// The ID token returned by the OIDC provider must carry one of them in the acr claim.
+	AcrValues *string `form:"acr_values,omitempty" json:"acr_values,omitempty"`
+
+	// MaxAge Maximum number of seconds since the user last actively authenticated with the OIDC provider.
+	// The ID token returned by the OIDC provider must carry an auth_time claim satisfying it.
+	MaxAge *int `form:"max_age,omitempty" json:"max_age,omitempty"`
+
+	// Prompt When set to login, the OIDC provider must re-authenticate the user even if the user
+	// is already logged in at the provider.
+	Prompt *AuthParamsPrompt `form:"prompt,omitempty" json:"prompt,omitempty"`
 }
 
+// AuthParamsPrompt defines parameters for Auth.
+type AuthParamsPrompt string
+
 // BclogoutFormdataBody defines parameters for Bclogout.
 type BclogoutFormdataBody struct {
 	// LogoutToken Logout token
@@ -46,6 +67,15 @@ type CallbackParams struct {
 	UnderscoreUnderscoreHostLoginCSRF string `form:"__Host-LoginCSRF" json:"__Host-LoginCSRF"`
 }
 
+// FclogoutParams defines parameters for Fclogout.
+type FclogoutParams struct {
+	// Iss Issuer of the OIDC provider
+	Iss string `form:"iss" json:"iss"`
+
+	// Sid OIDC session ID
+	Sid string `form:"sid" json:"sid"`
+}
+
 // LogoutParams defines parameters for Logout.
 type LogoutParams struct {
 	TenantID              string `form:"tenant_id" json:"tenant_id"`
@@ -68,6 +98,9 @@ type ServerInterface interface {
 	// (GET /sm/callback)
 	Callback(w http.ResponseWriter, r *http.Request, params CallbackParams)
 
+	// (GET /sm/fclogout)
+	Fclogout(w http.ResponseWriter, r *http.Request, params FclogoutParams)
+
 	// (GET /sm/logout)
 	Logout(w http.ResponseWriter, r *http.Request, params LogoutParams)
 }
@@ -127,6 +160,30 @@ func (siw *ServerInterfaceWrapper) Auth(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// ------------- Optional query parameter "acr_values" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "acr_values", r.URL.Query(), &params.AcrValues)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "acr_values", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "max_age" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "max_age", r.URL.Query(), &params.MaxAge)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "max_age", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "prompt" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "prompt", r.URL.Query(), &params.Prompt)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "prompt", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.Auth(w, r, params)
 	}))
@@ -219,6 +276,55 @@ func (siw *ServerInterfaceWrapper) Callback(w http.ResponseWriter, r *http.Reque
 	handler.ServeHTTP(w, r)
 }
 
+// Fclogout operation middleware
+func (siw *ServerInterfaceWrapper) Fclogout(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FclogoutParams
+
+	// ------------- Required query parameter "iss" -------------
+
+	if paramValue := r.URL.Query().Get("iss"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "iss"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "iss", r.URL.Query(), &params.Iss)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "iss", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "sid" -------------
+
+	if paramValue := r.URL.Query().Get("sid"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "sid"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "sid", r.URL.Query(), &params.Sid)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sid", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Fclogout(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // Logout operation middleware
 func (siw *ServerInterfaceWrapper) Logout(w http.ResponseWriter, r *http.Request) {
 
@@ -416,6 +522,7 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 	m.HandleFunc("GET "+options.BaseURL+"/sm/auth", wrapper.Auth)
 	m.HandleFunc("POST "+options.BaseURL+"/sm/bclogout", wrapper.Bclogout)
 	m.HandleFunc("GET "+options.BaseURL+"/sm/callback", wrapper.Callback)
+	m.HandleFunc("GET "+options.BaseURL+"/sm/fclogout", wrapper.Fclogout)
 	m.HandleFunc("GET "+options.BaseURL+"/sm/logout", wrapper.Logout)
 
 	return m
@@ -479,13 +586,38 @@ func (response Bclogout200Response) VisitBclogoutResponse(w http.ResponseWriter)
 	return nil
 }
 
-type Bclogout400JSONResponse ErrorModel
+type Bclogout400ResponseHeaders struct {
+	CacheControl string
+}
+
+type Bclogout400JSONResponse struct {
+	Body    ErrorModel
+	Headers Bclogout400ResponseHeaders
+}
 
 func (response Bclogout400JSONResponse) VisitBclogoutResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
 	w.WriteHeader(400)
 
-	return json.NewEncoder(w).Encode(response)
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type Bclogout500ResponseHeaders struct {
+	CacheControl string
+}
+
+type Bclogout500JSONResponse struct {
+	Body    ErrorModel
+	Headers Bclogout500ResponseHeaders
+}
+
+func (response Bclogout500JSONResponse) VisitBclogoutResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response.Body)
 }
 
 type CallbackRequestObject struct {
@@ -522,6 +654,58 @@ func (response CallbackdefaultJSONResponse) VisitCallbackResponse(w http.Respons
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
+type FclogoutRequestObject struct {
+	Params FclogoutParams
+}
+
+type FclogoutResponseObject interface {
+	VisitFclogoutResponse(w http.ResponseWriter) error
+}
+
+type Fclogout200ResponseHeaders struct {
+	CacheControl          string
+	ContentSecurityPolicy string
+}
+
+type Fclogout200TextHTMLResponse struct {
+	Body          io.Reader
+	Headers       Fclogout200ResponseHeaders
+	ContentLength int64
+}
+
+func (response Fclogout200TextHTMLResponse) VisitFclogoutResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/html")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.Header().Set("Content-Security-Policy", fmt.Sprint(response.Headers.ContentSecurityPolicy))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type Fclogout400ResponseHeaders struct {
+	CacheControl string
+}
+
+type Fclogout400JSONResponse struct {
+	Body    ErrorModel
+	Headers Fclogout400ResponseHeaders
+}
+
+func (response Fclogout400JSONResponse) VisitFclogoutResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type LogoutRequestObject struct {
 	Params LogoutParams
 }
@@ -568,6 +752,9 @@ type StrictServerInterface interface {
 	// (GET /sm/callback)
 	Callback(ctx context.Context, request CallbackRequestObject) (CallbackResponseObject, error)
 
+	// (GET /sm/fclogout)
+	Fclogout(ctx context.Context, request FclogoutRequestObject) (FclogoutResponseObject, error)
+
 	// (GET /sm/logout)
 	Logout(ctx context.Context, request LogoutRequestObject) (LogoutResponseObject, error)
 }
@@ -688,6 +875,32 @@ func (sh *strictHandler) Callback(w http.ResponseWriter, r *http.Request, params
 	}
 }
 
+// Fclogout operation middleware
+func (sh *strictHandler) Fclogout(w http.ResponseWriter, r *http.Request, params FclogoutParams) {
+	var request FclogoutRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Fclogout(ctx, request.(FclogoutRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Fclogout")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(FclogoutResponseObject); ok {
+		if err := validResponse.VisitFclogoutResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // Logout operation middleware
 func (sh *strictHandler) Logout(w http.ResponseWriter, r *http.Request, params LogoutParams) {
 	var request LogoutRequestObject
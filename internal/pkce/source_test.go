@@ -21,6 +21,13 @@ func TestSource_State(t *testing.T) {
 	assert.Len(t, state, 64, "State should be 64 characters long")
 }
 
+func TestSource_Nonce(t *testing.T) {
+	p := Source{}
+	nonce := p.Nonce()
+	assert.Len(t, nonce, 32, "Nonce should be 32 characters long")
+	assert.NotEqual(t, nonce, p.Nonce(), "Nonces should be unique")
+}
+
 func TestSource_SessionID(t *testing.T) {
 	p := Source{}
 	sessionID := p.SessionID()
@@ -0,0 +1,240 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
+)
+
+// ClaimMapping selects the session claims from the claims issued by a provider.
+type ClaimMapping struct {
+	subject    claimPath
+	userUUID   claimPath
+	givenName  claimPath
+	familyName claimPath
+	email      claimPath
+	groups     claimPath
+}
+
+// ClaimMappingOf parses the claim mapping of the OIDC trust. It returns nil if
+// the trust does not map any claims.
+func ClaimMappingOf(oidc *oidcv1.OIDC) (*ClaimMapping, error) {
+	//nolint:forcetypeassert
+	return ParseClaimMapping(proto.GetExtension(oidc, smoidcv1.E_ClaimMapping).(*smoidcv1.ClaimMapping))
+}
+
+// ParseClaimMapping parses the claim mapping of a trust. It returns nil if
+// the trust does not map any claims.
+func ParseClaimMapping(m *smoidcv1.ClaimMapping) (*ClaimMapping, error) {
+	if m == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	var (
+		cm     ClaimMapping
+		mapped bool
+		errs   []error
+	)
+	for _, field := range []struct {
+		name string
+		expr *string
+		path *claimPath
+	}{
+		{name: "subject", expr: m.Subject, path: &cm.subject},
+		{name: "user_uuid", expr: m.UserUuid, path: &cm.userUUID},
+		{name: "given_name", expr: m.GivenName, path: &cm.givenName},
+		{name: "family_name", expr: m.FamilyName, path: &cm.familyName},
+		{name: "email", expr: m.Email, path: &cm.email},
+		{name: "groups", expr: m.Groups, path: &cm.groups},
+	} {
+		if field.expr == nil {
+			continue
+		}
+
+		path, err := parseClaimPath(*field.expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.name, err))
+			continue
+		}
+
+		*field.path = path
+		mapped = true
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if !mapped {
+		return nil, nil //nolint:nilnil
+	}
+
+	return &cm, nil
+}
+
+// Apply sets the mapped fields of the claims from the given raw claims. Fields
+// whose claim is not present in the raw claims are left unchanged.
+func (cm *ClaimMapping) Apply(raw map[string]any, claims *Claims) error {
+	if cm == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, field := range []struct {
+		name string
+		path claimPath
+		dst  *string
+	}{
+		{name: "subject", path: cm.subject, dst: &claims.Subject},
+		{name: "user_uuid", path: cm.userUUID, dst: &claims.UserUUID},
+		{name: "given_name", path: cm.givenName, dst: &claims.GivenName},
+		{name: "family_name", path: cm.familyName, dst: &claims.FamilyName},
+		{name: "email", path: cm.email, dst: &claims.Email},
+	} {
+		v, ok := field.path.lookup(raw)
+		if !ok {
+			continue
+		}
+
+		s, err := claimString(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.name, err))
+			continue
+		}
+
+		*field.dst = s
+	}
+
+	if v, ok := cm.groups.lookup(raw); ok {
+		groups, err := claimStrings(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("groups: %w", err))
+		} else {
+			claims.Groups = groups
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func claimString(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unexpected claim type %T", v)
+	}
+}
+
+func claimStrings(v any) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			s, err := claimString(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, s)
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unexpected claim type %T", v)
+	}
+}
+
+// claimPath is the sequence of member names selecting a nested claim.
+type claimPath []string
+
+// parseClaimPath parses a JSONPath-like member expression. The expression may
+// start with the root `$` and consists of member names separated by dots or
+// quoted in brackets, e.g. `resource_access['my-client'].roles`.
+func parseClaimPath(expr string) (claimPath, error) {
+	rest := strings.TrimSpace(expr)
+	if rest == "" {
+		return nil, errors.New("empty claim path")
+	}
+
+	var path claimPath
+	if after, ok := strings.CutPrefix(rest, "$"); ok {
+		rest = after
+	} else {
+		// The first member name may be given without a leading dot.
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		var name string
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			name, rest = rest[1:end+1], rest[end+1:]
+			if name == "" || strings.ContainsAny(name, "*]'\" ") {
+				return nil, fmt.Errorf("invalid member name %q in claim path %q", name, expr)
+			}
+		case '[':
+			if len(rest) < 4 || (rest[1] != '\'' && rest[1] != '"') {
+				return nil, fmt.Errorf("only quoted member names are supported in brackets in claim path %q", expr)
+			}
+
+			end := strings.Index(rest[2:], string(rest[1])+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in claim path %q", expr)
+			}
+
+			name, rest = rest[2:end+2], rest[end+4:]
+			if name == "" {
+				return nil, fmt.Errorf("empty member name in claim path %q", expr)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected character %q in claim path %q", rest[0], expr)
+		}
+
+		path = append(path, name)
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("claim path %q does not select a claim", expr)
+	}
+
+	return path, nil
+}
+
+// lookup returns the claim selected by the path if present.
+func (p claimPath) lookup(claims map[string]any) (any, bool) {
+	if len(p) == 0 {
+		return nil, false
+	}
+
+	var v any = claims
+	for _, name := range p {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		v, ok = obj[name]
+		if !ok || v == nil {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
@@ -0,0 +1,268 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
+)
+
+func TestParseClaimMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		mapping    *smoidcv1.ClaimMapping
+		wantNil    bool
+		wantErrMsg string
+	}{
+		{
+			name:    "No mapping",
+			wantNil: true,
+		},
+		{
+			name:    "Empty mapping",
+			mapping: &smoidcv1.ClaimMapping{},
+			wantNil: true,
+		},
+		{
+			name:    "Member names",
+			mapping: &smoidcv1.ClaimMapping{Subject: new("oid"), Groups: new("realm_access.roles")},
+		},
+		{
+			name:    "Root and brackets",
+			mapping: &smoidcv1.ClaimMapping{Groups: new(`$.resource_access['my-client']["roles"]`)},
+		},
+		{
+			name:       "Empty expression",
+			mapping:    &smoidcv1.ClaimMapping{Email: new(" ")},
+			wantErrMsg: "email: empty claim path",
+		},
+		{
+			name:       "Root only",
+			mapping:    &smoidcv1.ClaimMapping{Email: new("$")},
+			wantErrMsg: "does not select a claim",
+		},
+		{
+			name:       "Wildcard",
+			mapping:    &smoidcv1.ClaimMapping{Groups: new("realm_access.roles[*]")},
+			wantErrMsg: "groups: only quoted member names",
+		},
+		{
+			name:       "Unterminated bracket",
+			mapping:    &smoidcv1.ClaimMapping{Groups: new("resource_access['my-client"), Subject: new("..oid")},
+			wantErrMsg: "unterminated bracket",
+		},
+		{
+			name:       "Empty member name",
+			mapping:    &smoidcv1.ClaimMapping{Subject: new("realm_access..roles")},
+			wantErrMsg: "subject: invalid member name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, err := session.ParseClaimMapping(tt.mapping)
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantNil, cm == nil)
+		})
+	}
+}
+
+func TestClaimMapping_Apply(t *testing.T) {
+	raw := map[string]any{
+		"sub":   "provider-subject",
+		"oid":   "5b4f6c1e-0d1c-4f4a-9a39-3a2a4d7a0f8e",
+		"upn":   "jane.doe@example.com",
+		"emp":   float64(4711),
+		"roles": "admin",
+		"realm_access": map[string]any{
+			"roles": []any{"viewer", "editor"},
+		},
+		"resource_access": map[string]any{
+			"my-client": map[string]any{"roles": []any{"key-admin"}},
+		},
+		"flag": true,
+	}
+
+	tests := []struct {
+		name       string
+		mapping    *smoidcv1.ClaimMapping
+		want       session.Claims
+		wantErrMsg string
+	}{
+		{
+			name: "No mapping keeps the claims",
+			want: session.Claims{Subject: "subject", Groups: []string{"group"}},
+		},
+		{
+			name: "Top-level and nested claims",
+			mapping: &smoidcv1.ClaimMapping{
+				Subject: new("oid"),
+				Email:   new("upn"),
+				Groups:  new("realm_access.roles"),
+			},
+			want: session.Claims{
+				Subject: "5b4f6c1e-0d1c-4f4a-9a39-3a2a4d7a0f8e",
+				Email:   "jane.doe@example.com",
+				Groups:  []string{"viewer", "editor"},
+			},
+		},
+		{
+			name:    "Quoted member names",
+			mapping: &smoidcv1.ClaimMapping{Groups: new("$.resource_access['my-client'].roles")},
+			want:    session.Claims{Subject: "subject", Groups: []string{"key-admin"}},
+		},
+		{
+			name:    "Single string group",
+			mapping: &smoidcv1.ClaimMapping{Groups: new("roles")},
+			want:    session.Claims{Subject: "subject", Groups: []string{"admin"}},
+		},
+		{
+			name:    "Numeric claim",
+			mapping: &smoidcv1.ClaimMapping{UserUuid: new("emp")},
+			want:    session.Claims{Subject: "subject", UserUUID: "4711", Groups: []string{"group"}},
+		},
+		{
+			name:    "Missing claims keep the claims",
+			mapping: &smoidcv1.ClaimMapping{Subject: new("missing"), Groups: new("realm_access.missing.roles")},
+			want:    session.Claims{Subject: "subject", Groups: []string{"group"}},
+		},
+		{
+			name:       "Unexpected claim type",
+			mapping:    &smoidcv1.ClaimMapping{Email: new("flag")},
+			wantErrMsg: "email: unexpected claim type bool",
+		},
+		{
+			name:       "Unexpected groups type",
+			mapping:    &smoidcv1.ClaimMapping{Groups: new("realm_access")},
+			wantErrMsg: "groups: unexpected claim type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, err := session.ParseClaimMapping(tt.mapping)
+			require.NoError(t, err)
+
+			claims := session.Claims{Subject: "subject", Groups: []string{"group"}}
+			err = cm.Apply(raw, &claims)
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, claims)
+		})
+	}
+}
+
+func TestManager_FinaliseOIDCLogin_ClaimMapping(t *testing.T) {
+	const (
+		tenantID = "tenant-id"
+		stateID  = "test-state-id"
+	)
+
+	tests := []struct {
+		name       string
+		mapping    *smoidcv1.ClaimMapping
+		claims     map[string]any
+		wantClaims session.Claims
+		wantErr    bool
+	}{
+		{
+			name:       "Default claims",
+			claims:     map[string]any{"email": "jane.doe@example.com", "groups": []string{"group"}},
+			wantClaims: session.Claims{Subject: "jwt-test", Email: "jane.doe@example.com", Groups: []string{"group"}},
+		},
+		{
+			name: "Mapped claims",
+			mapping: &smoidcv1.ClaimMapping{
+				Subject: new("oid"),
+				Groups:  new("realm_access.roles"),
+			},
+			claims: map[string]any{
+				"oid":          "object-id",
+				"email":        "jane.doe@example.com",
+				"groups":       []string{"group"},
+				"realm_access": map[string]any{"roles": []string{"viewer", "editor"}},
+			},
+			wantClaims: session.Claims{Subject: "object-id", Email: "jane.doe@example.com", Groups: []string{"viewer", "editor"}},
+		},
+		{
+			name:    "Invalid mapped claim",
+			mapping: &smoidcv1.ClaimMapping{Email: new("email_verified")},
+			claims:  map[string]any{"email_verified": true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oidcServer := StartOIDCServerWithIDTokenClaims(t, false, tt.claims)
+			defer oidcServer.Close()
+
+			auditServer := StartAuditServer(t)
+			defer auditServer.Close()
+
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			oidc := oidcv1.OIDC_builder{
+				Issuer:   new(oidcServer.URL),
+				ClientId: new(testClientID),
+			}.Build()
+			if tt.mapping != nil {
+				proto.SetExtension(oidc, smoidcv1.E_ClaimMapping, tt.mapping)
+			}
+			oidcTrust := trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(false),
+				Oidc:     oidc,
+			}.Build()
+
+			sessions := sessionmock.NewInMemRepository(sessionmock.WithState(session.State{
+				ID:       stateID,
+				TenantID: tenantID,
+				Nonce:    testNonce,
+				Expiry:   time.Now().Add(time.Hour),
+			}))
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      "http://sm.example.com/sm/callback",
+					CSRFSecretParsed: []byte(testCSRFSecret),
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessions,
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			result, err := m.FinaliseOIDCLogin(t.Context(), stateID, "auth-code")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			sess, err := sessions.LoadSession(t.Context(), result.SessionID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClaims, sess.Claims)
+		})
+	}
+}
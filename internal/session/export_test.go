@@ -1,3 +1,7 @@
 package session
 
 type TokenResponse = tokenResponse
+
+var MFAType = mfaType
+
+var TokenExpiries = tokenResponse.expiries
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +14,8 @@ import (
 	"time"
 
 	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/session-manager/pkg/serviceerr"
 )
 
 func (m *Manager) TriggerHousekeeping(ctx context.Context, concurrencyLimit int, refreshTriggerInterval time.Duration) error {
@@ -83,9 +86,21 @@ func (m *Manager) housekeepSession(ctx context.Context, s Session, refreshTrigge
 		return
 	}
 
-	// Refresh access tokens that are nearing expiration
-	if time.Until(s.AccessTokenExpiry) < refreshTriggerInterval {
-		err := m.refreshAccessToken(ctx, s)
+	// Delete sessions which cannot be refreshed anymore
+	if !s.RefreshTokenExpiry.IsZero() && time.Now().After(s.RefreshTokenExpiry) {
+		err := m.sessions.DeleteSession(ctx, s)
+		if err != nil {
+			slogctx.Error(ctx, "Error deleting session with expired refresh token", "error", err)
+		} else {
+			slogctx.Info(ctx, "Successfully deleted session with expired refresh token")
+		}
+		return
+	}
+
+	// Refresh access tokens that are nearing expiration. Access tokens of
+	// unknown expiry are not refreshed, see accessTokenExpiresWithin.
+	if accessTokenExpiresWithin(s, refreshTriggerInterval) {
+		_, err := m.RefreshExpiringSession(ctx, s, refreshTriggerInterval)
 		if err != nil {
 			slogctx.Error(ctx, "Error refreshing access token", "error", err)
 		} else {
@@ -94,18 +109,19 @@ func (m *Manager) housekeepSession(ctx context.Context, s Session, refreshTrigge
 	}
 }
 
-// refreshAccessToken refreshes the access token for the given session using its refresh token.
-func (m *Manager) refreshAccessToken(ctx context.Context, s Session) error {
+// refreshAccessToken refreshes the access token for the given session using its
+// refresh token and returns the refreshed session.
+func (m *Manager) refreshAccessToken(ctx context.Context, s Session) (Session, error) {
 	trust, err := m.trust.Get(ctx, s.TenantID)
 	if err != nil {
-		return fmt.Errorf("could not get trust: %w", err)
+		return Session{}, fmt.Errorf("could not get trust: %w", err)
 	}
 
 	oidc := trust.GetOidc()
 
 	openidConf, err := m.getOpenIDConfig(ctx, oidc.GetIssuer())
 	if err != nil {
-		return fmt.Errorf("could not get OpenID configuration: %w", err)
+		return Session{}, fmt.Errorf("could not get OpenID configuration: %w", err)
 	}
 
 	data := url.Values{}
@@ -114,43 +130,66 @@ func (m *Manager) refreshAccessToken(ctx context.Context, s Session) error {
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, openidConf.TokenEndpoint, bytes.NewBufferString(data.Encode()))
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return Session{}, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	client, err := m.httpClient(oidc.GetClientId())
 	if err != nil {
-		return fmt.Errorf("creating http client: %w", err)
+		return Session{}, fmt.Errorf("creating http client: %w", err)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("executing request: %w", err)
+		return Session{}, fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("could not read token endpoint response body: %w", err)
+		return Session{}, fmt.Errorf("could not read token endpoint response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("token endpoint returned non-200 status: %d, body: %s", resp.StatusCode, string(body))
+		var errResp serviceerr.Error
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Err == serviceerr.CodeInvalidGrant {
+			return m.endRejectedSession(ctx, s)
+		}
+
+		return Session{}, fmt.Errorf("token endpoint returned non-200 status: %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	var respData tokenResponse
 	err = json.Unmarshal(body, &respData)
 	if err != nil {
-		return fmt.Errorf("could not unmarshal token endpoint response: %w", err)
+		return Session{}, fmt.Errorf("could not unmarshal token endpoint response: %w", err)
 	}
 
+	previousRefreshToken := s.RefreshToken
+	accessTokenExpiry, refreshTokenExpiry := respData.expiries(time.Now())
 	s.AccessToken = respData.AccessToken
-	s.RefreshToken = respData.RefreshToken
-	s.AccessTokenExpiry = time.Now().Add(time.Duration(respData.ExpiresIn) * time.Second)
+	s.AccessTokenExpiry = accessTokenExpiry
+
+	// The provider may keep the refresh token
+	if respData.RefreshToken != "" {
+		s.RefreshToken = respData.RefreshToken
+		s.RefreshTokenExpiry = refreshTokenExpiry
+	}
 
-	err = m.sessions.StoreSession(ctx, s)
+	err = m.sessions.StoreRefreshedSession(ctx, s, previousRefreshToken)
+	if errors.Is(err, serviceerr.ErrConflict) {
+		// Another refresher has replaced the tokens meanwhile, so its session wins
+		slogctx.Warn(ctx, "Session has been refreshed concurrently")
+
+		s, err = m.sessions.LoadSession(ctx, s.ID)
+		if err != nil {
+			return Session{}, fmt.Errorf("could not load concurrently refreshed session: %w", err)
+		}
+
+		return s, nil
+	}
 	if err != nil {
-		return fmt.Errorf("could not store refreshed session: %w", err)
+		return Session{}, fmt.Errorf("could not store refreshed session: %w", err)
 	}
 
-	return nil
+	return s, nil
 }
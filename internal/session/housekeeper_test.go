@@ -66,6 +66,76 @@ func TestDeleteIdleSessions(t *testing.T) {
 	require.ErrorIs(t, err, serviceerr.ErrNotFound)
 }
 
+func TestDeleteSessionsWithExpiredRefreshToken(t *testing.T) {
+	ctx := t.Context()
+	cfg := &config.SessionManager{
+		CSRFSecretParsed: []byte(testCSRFSecret),
+	}
+	sessions := sessionmock.NewInMemRepository(
+		sessionmock.WithSession(session.Session{
+			ID:                 "expired-refresh-token",
+			TenantID:           "CMKTenantID",
+			AccessTokenExpiry:  time.Now().Add(-time.Minute),
+			RefreshTokenExpiry: time.Now().Add(-time.Second),
+		}),
+		sessionmock.WithSession(session.Session{
+			ID:                 "valid-refresh-token",
+			TenantID:           "CMKTenantID",
+			AccessTokenExpiry:  time.Now().Add(2 * time.Hour),
+			RefreshTokenExpiry: time.Now().Add(4 * time.Hour),
+		}),
+	)
+
+	for _, sessionID := range []string{"expired-refresh-token", "valid-refresh-token"} {
+		err := sessions.BumpActive(ctx, sessionID, time.Hour)
+		require.NoError(t, err)
+	}
+
+	manager, err := session.NewManager(ctx, cfg, nil, sessions, nil)
+	require.NoError(t, err)
+
+	err = manager.TriggerHousekeeping(ctx, 2, time.Hour)
+	require.NoError(t, err)
+
+	// The session can no longer be refreshed, so it must be ended although it is still active
+	_, err = sessions.LoadSession(ctx, "expired-refresh-token")
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+
+	_, err = sessions.LoadSession(ctx, "valid-refresh-token")
+	require.NoError(t, err)
+}
+
+func TestHousekeepSession_UnknownAccessTokenExpiry(t *testing.T) {
+	ctx := t.Context()
+
+	server, refreshes := startRefreshServer(t, 0)
+	defer server.Close()
+
+	// An opaque access token issued without expires_in has no known expiry
+	sess := session.Session{
+		ID:           "opaque-access-token",
+		TenantID:     "test-tenant",
+		AccessToken:  "old-access-token",
+		RefreshToken: "old-refresh-token",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	sessions := sessionmock.NewInMemRepository(sessionmock.WithSession(sess))
+	require.NoError(t, sessions.BumpActive(ctx, sess.ID, time.Hour))
+
+	m := newRefreshManager(t, server.URL, "test-tenant", sessions, nil)
+
+	for range 3 {
+		require.NoError(t, m.TriggerHousekeeping(ctx, 1, time.Minute))
+	}
+
+	assert.Zero(t, refreshes.Load(), "sessions must not be refreshed on every housekeeping pass")
+
+	stored, err := sessions.LoadSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "old-access-token", stored.AccessToken)
+	assert.Equal(t, "old-refresh-token", stored.RefreshToken)
+}
+
 func TestRefreshAccessToken(t *testing.T) {
 	ctx := t.Context()
 	tenantID := "test-tenant"
@@ -202,7 +272,7 @@ func TestRefreshAccessToken(t *testing.T) {
 
 		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusUnauthorized)
-			_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+			_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
 		}))
 		defer tokenServer.Close()
 		tokenServerURL = tokenServer.URL + "/token"
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	slogctx "github.com/veqryn/slog-context"
+)
+
+const (
+	// defaultJWKSCacheExpiration is used if the provider does not return caching directives.
+	defaultJWKSCacheExpiration = time.Hour
+	// defaultJWKSMinRefreshInterval limits how often an unknown key ID triggers a refresh.
+	defaultJWKSMinRefreshInterval = time.Minute
+	// jwksFetchTimeout bounds a key set fetch shared by concurrent callers.
+	jwksFetchTimeout = 30 * time.Second
+)
+
+// cachedKeySet is a provider key set along with the time it has been fetched.
+type cachedKeySet struct {
+	keySet    *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// getProviderKeySet returns the key set of the provider, preferring the JWKS URI
+// configured on the trust over the discovered one. The key set is served from the
+// cache unless the given key ID is unknown and the cached key set has not been
+// refreshed recently, which allows to pick up rotated keys. Concurrent fetches
+// of the same key set are deduplicated.
+func (m *Manager) getProviderKeySet(ctx context.Context, oidc *oidcv1.OIDC, oidcConf *openIDConfiguration, kid string) (*jose.JSONWebKeySet, error) {
+	uri := oidc.GetJwksUri()
+	if uri == "" {
+		uri = oidcConf.JwksURI
+	}
+
+	cacheKey := oidc.GetIssuer() + " " + uri
+	if item := m.jwksCache.Get(cacheKey); item != nil {
+		cached := item.Value()
+		if kid == "" || len(cached.keySet.Key(kid)) > 0 || time.Since(cached.fetchedAt) < m.jwksMinRefreshInterval {
+			return cached.keySet, nil
+		}
+
+		slogctx.Info(ctx, "Refreshing the provider key set due to an unknown key ID", "kid", kid)
+	}
+
+	// The fetch is shared, so it must not be cancelled with the first caller
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+	defer cancel()
+
+	v, err, _ := m.jwksGroup.Do(cacheKey, func() (any, error) {
+		keySet, ttl, err := fetchProviderKeySet(fetchCtx, uri)
+		if err != nil {
+			return nil, err
+		}
+
+		if ttl > 0 {
+			m.jwksCache.Set(cacheKey, &cachedKeySet{keySet: keySet, fetchedAt: time.Now()}, ttl)
+		} else {
+			m.jwksCache.Delete(cacheKey)
+		}
+
+		return keySet, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert
+	return v.(*jose.JSONWebKeySet), nil
+}
+
+// fetchProviderKeySet fetches the key set from the JWKS URI. The key set is
+// public, so like the OpenID configuration it is fetched without the client
+// credentials of the tenant.
+func fetchProviderKeySet(ctx context.Context, uri string) (*jose.JSONWebKeySet, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating a new HTTP request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("executing an http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("fetching keyset failed with status: %d", resp.StatusCode)
+	}
+
+	var keySet jose.JSONWebKeySet
+	err = json.NewDecoder(resp.Body).Decode(&keySet)
+	if err != nil {
+		return nil, 0, fmt.Errorf("decoding keyset response: %w", err)
+	}
+
+	return &keySet, cacheTTL(resp.Header, defaultJWKSCacheExpiration), nil
+}
+
+// cacheTTL returns for how long a response may be cached according to its
+// Cache-Control header. It returns zero if the response must not be cached.
+func cacheTTL(header http.Header, fallback time.Duration) time.Duration {
+	ttl := fallback
+	for directive := range strings.SplitSeq(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+
+			ttl = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return ttl
+}
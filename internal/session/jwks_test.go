@@ -0,0 +1,368 @@
+package session_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/credentials"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+)
+
+// rotatingOIDCServer is a test OIDC provider whose signing key can be rotated.
+// It counts how often its key set has been fetched.
+type rotatingOIDCServer struct {
+	*httptest.Server
+
+	cacheControl string
+	jwksPath     string
+	jwksDelay    time.Duration
+	jwksFetches  atomic.Int32
+
+	// record whether the requests have been sent with client credentials
+	jwksAuthenticated  atomic.Bool
+	tokenAuthenticated atomic.Bool
+
+	mu  sync.Mutex
+	kid string
+	key *rsa.PrivateKey
+}
+
+func startRotatingOIDCServer(t *testing.T, cacheControl, jwksPath string) *rotatingOIDCServer {
+	t.Helper()
+
+	s := &rotatingOIDCServer{cacheControl: cacheControl, jwksPath: jwksPath}
+	s.rotate(t, "key-1")
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+func (s *rotatingOIDCServer) rotate(t *testing.T, kid string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kid, s.key = kid, key
+}
+
+func (s *rotatingOIDCServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	kid, key := s.kid, s.key
+	s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                s.URL,
+			"authorization_endpoint":                s.URL + "/oauth2/authorize",
+			"token_endpoint":                        s.URL + "/oauth2/token",
+			"jwks_uri":                              s.URL + "/.well-known/jwks.json",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	case s.jwksPath:
+		s.jwksFetches.Add(1)
+		if r.Header.Get(clientIDHeader) != "" {
+			s.jwksAuthenticated.Store(true)
+		}
+		time.Sleep(s.jwksDelay)
+		if s.cacheControl != "" {
+			w.Header().Set("Cache-Control", s.cacheControl)
+		}
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+			Key:       &key.PublicKey,
+			KeyID:     kid,
+			Algorithm: string(jose.RS256),
+			Use:       "sig",
+		}}})
+	case "/oauth2/token":
+		if r.Header.Get(clientIDHeader) != "" {
+			s.tokenAuthenticated.Store(true)
+		}
+		signer, err := jose.NewSigner(jose.SigningKey{
+			Algorithm: jose.RS256,
+			Key:       jose.JSONWebKey{Key: key, KeyID: kid},
+		}, (&jose.SignerOptions{}).WithType("JWT"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		atHash := sha256.Sum256([]byte(testAccessToken))
+		now := time.Now()
+		idToken, err := jwt.Signed(signer).Claims(map[string]any{
+			"sub":     "jwt-test",
+			"at_hash": base64.RawURLEncoding.EncodeToString(atHash[:len(atHash)/2]),
+			"exp":     jwt.NewNumericDate(now.Add(time.Hour)),
+			"iat":     jwt.NewNumericDate(now),
+			"iss":     s.URL,
+			"aud":     testClientID,
+			"nonce":   testNonce,
+		}).Serialize()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(session.TokenResponse{
+			AccessToken:  testAccessToken,
+			RefreshToken: "refresh-token",
+			IDToken:      idToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    3600,
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// clientIDHeader marks the requests sent with headerCredentials.
+const clientIDHeader = "X-Test-Client-Id"
+
+// headerCredentials are client credentials which set the client ID header.
+type headerCredentials string
+
+func (c headerCredentials) Transport() http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set(clientIDHeader, string(c))
+		return http.DefaultTransport.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// newKeySetTestManager returns a manager for the tenant of the OIDC server with
+// the given number of login states, named state-0, state-1 and so on.
+func newKeySetTestManager(t *testing.T, oidcServer *rotatingOIDCServer, states int, opts ...session.ManagerOption) *session.Manager {
+	t.Helper()
+
+	const tenantID = "tenant-id"
+
+	auditServer := StartAuditServer(t)
+	t.Cleanup(auditServer.Close)
+
+	auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+	require.NoError(t, err)
+
+	oidcTrust := trustv1.Trust_builder{
+		TenantId: new(tenantID),
+		Blocked:  new(false),
+		Oidc: oidcv1.OIDC_builder{
+			Issuer:   new(oidcServer.URL),
+			ClientId: new(testClientID),
+		}.Build(),
+	}.Build()
+
+	stateOpts := make([]sessionmock.RepositoryOption, 0, states)
+	for i := range states {
+		stateOpts = append(stateOpts, sessionmock.WithState(session.State{
+			ID:       fmt.Sprintf("state-%d", i),
+			TenantID: tenantID,
+			Nonce:    testNonce,
+			Expiry:   time.Now().Add(time.Hour),
+		}))
+	}
+
+	m, err := session.NewManager(t.Context(),
+		&config.SessionManager{
+			SessionDuration:  time.Hour,
+			CallbackURL:      "http://sm.example.com/sm/callback",
+			CSRFSecretParsed: []byte(testCSRFSecret),
+		},
+		newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+		sessionmock.NewInMemRepository(stateOpts...),
+		auditLogger,
+		append([]session.ManagerOption{session.WithAllowHttpScheme(true)}, opts...)...,
+	)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestManager_ProviderKeySetConcurrentRefresh(t *testing.T) {
+	const logins = 5
+
+	oidcServer := startRotatingOIDCServer(t, "", "/.well-known/jwks.json")
+	defer oidcServer.Close()
+	oidcServer.jwksDelay = 100 * time.Millisecond
+
+	m := newKeySetTestManager(t, oidcServer, logins+1, session.WithJWKSMinRefreshInterval(0))
+
+	_, err := m.FinaliseOIDCLogin(t.Context(), "state-0", "auth-code")
+	require.NoError(t, err)
+
+	// All logins miss the rotated key at once, but share one refresh
+	oidcServer.rotate(t, "key-2")
+
+	var wg sync.WaitGroup
+	errs := make([]error, logins)
+	for i := range logins {
+		wg.Go(func() {
+			_, errs[i] = m.FinaliseOIDCLogin(t.Context(), fmt.Sprintf("state-%d", i+1), "auth-code")
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), oidcServer.jwksFetches.Load())
+}
+
+func TestManager_ProviderKeySetWithoutClientCredentials(t *testing.T) {
+	oidcServer := startRotatingOIDCServer(t, "", "/.well-known/jwks.json")
+	defer oidcServer.Close()
+
+	m := newKeySetTestManager(t, oidcServer, 1, session.WithTransportCredentials(func(clientID string) credentials.TransportCredentials {
+		return headerCredentials(clientID)
+	}))
+
+	_, err := m.FinaliseOIDCLogin(t.Context(), "state-0", "auth-code")
+	require.NoError(t, err)
+
+	assert.True(t, oidcServer.tokenAuthenticated.Load(), "the token request must use the client credentials")
+	assert.False(t, oidcServer.jwksAuthenticated.Load(), "the key set must be fetched without client credentials")
+}
+
+func TestManager_ProviderKeySetCache(t *testing.T) {
+	const tenantID = "tenant-id"
+
+	tests := []struct {
+		name               string
+		cacheControl       string
+		jwksPath           string
+		trustJWKSPath      string
+		minRefreshInterval time.Duration
+		rotate             bool
+		wantFetches        int32
+		wantSecondErr      bool
+	}{
+		{
+			name:        "Key set is cached",
+			wantFetches: 1,
+		},
+		{
+			name:         "Key set is cached for max-age",
+			cacheControl: "public, max-age=3600",
+			wantFetches:  1,
+		},
+		{
+			name:         "Key set is not cached with no-store",
+			cacheControl: "no-store",
+			wantFetches:  2,
+		},
+		{
+			name:          "JWKS URI of the trust takes precedence",
+			jwksPath:      "/tenant/jwks.json",
+			trustJWKSPath: "/tenant/jwks.json",
+			wantFetches:   1,
+		},
+		{
+			name:        "Unknown key ID refreshes the key set",
+			rotate:      true,
+			wantFetches: 2,
+		},
+		{
+			name:               "Unknown key ID refresh is rate limited",
+			rotate:             true,
+			minRefreshInterval: time.Hour,
+			wantFetches:        1,
+			wantSecondErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwksPath := tt.jwksPath
+			if jwksPath == "" {
+				jwksPath = "/.well-known/jwks.json"
+			}
+			oidcServer := startRotatingOIDCServer(t, tt.cacheControl, jwksPath)
+			defer oidcServer.Close()
+
+			auditServer := StartAuditServer(t)
+			defer auditServer.Close()
+
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			oidc := oidcv1.OIDC_builder{
+				Issuer:   new(oidcServer.URL),
+				ClientId: new(testClientID),
+			}.Build()
+			if tt.trustJWKSPath != "" {
+				oidc.SetJwksUri(oidcServer.URL + tt.trustJWKSPath)
+			}
+			oidcTrust := trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(false),
+				Oidc:     oidc,
+			}.Build()
+
+			newState := func(id string) session.State {
+				return session.State{ID: id, TenantID: tenantID, Nonce: testNonce, Expiry: time.Now().Add(time.Hour)}
+			}
+			sessions := sessionmock.NewInMemRepository(
+				sessionmock.WithState(newState("state-1")),
+				sessionmock.WithState(newState("state-2")),
+			)
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      "http://sm.example.com/sm/callback",
+					CSRFSecretParsed: []byte(testCSRFSecret),
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessions,
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+				session.WithJWKSMinRefreshInterval(tt.minRefreshInterval),
+			)
+			require.NoError(t, err)
+
+			_, err = m.FinaliseOIDCLogin(t.Context(), "state-1", "auth-code")
+			require.NoError(t, err)
+
+			if tt.rotate {
+				oidcServer.rotate(t, "key-2")
+			}
+
+			_, err = m.FinaliseOIDCLogin(t.Context(), "state-2", "auth-code")
+			if tt.wantSecondErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantFetches, oidcServer.jwksFetches.Load())
+		})
+	}
+}
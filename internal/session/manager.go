@@ -11,8 +11,11 @@ import (
 	"fmt"
 	"hash"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,11 +24,12 @@ import (
 	"github.com/gofrs/uuid/v5"
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/openkcm/common-sdk/pkg/csrf"
-	"github.com/openkcm/common-sdk/pkg/oidc"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/protobuf/proto"
 
 	flowv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/flow/v1"
 	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
 	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
 	slogctx "github.com/veqryn/slog-context"
 
@@ -35,9 +39,34 @@ import (
 	"github.com/openkcm/session-manager/internal/debugtools"
 	"github.com/openkcm/session-manager/internal/pkce"
 	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
 )
 
-const defaultWKOCCacheExpiration = 30 * time.Minute
+const (
+	defaultWKOCCacheExpiration = 30 * time.Minute
+	defaultLogoutTokenMaxAge   = 5 * time.Minute
+)
+
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// logoutTokenAlgorithms are the asymmetric algorithms logout tokens may be
+// signed with. Symmetric algorithms are rejected as the keys of the provider
+// are public.
+var logoutTokenAlgorithms = []jose.SignatureAlgorithm{
+	jose.EdDSA,
+	jose.RS256,
+	jose.RS384,
+	jose.RS512,
+	jose.ES256,
+	jose.ES384,
+	jose.ES512,
+	jose.PS256,
+	jose.PS384,
+	jose.PS512,
+}
+
+// defaultScopes are requested if neither the trust nor the configuration define scopes.
+var defaultScopes = []string{"openid", "profile", "email", "groups"}
 
 var debugSettingSMDumpTransport = debugtools.NewSetting("smdumptransport")
 
@@ -54,7 +83,10 @@ type Manager struct {
 
 	sessionDuration    time.Duration
 	idleSessionTimeout time.Duration
+	idTokenLeeway      time.Duration
+	logoutTokenMaxAge  time.Duration
 	callbackURL        *url.URL
+	scopes             []string
 
 	sessionCookieTemplate   config.CookieTemplate
 	csrfCookieTemplate      config.CookieTemplate
@@ -65,8 +97,23 @@ type Manager struct {
 	allowHttpScheme         bool
 	allowedRedirectBaseURLs []*url.URL
 
+	// signs authorisation request objects if enabled
+	requestObject *requestObjectSigner
+
 	// cache well known OpenID configuration results
-	wkocCache *ttlcache.Cache[string, *oidc.Configuration]
+	wkocCache *ttlcache.Cache[string, *openIDConfiguration]
+
+	// cache provider key sets
+	jwksCache              *ttlcache.Cache[string, *cachedKeySet]
+	jwksMinRefreshInterval time.Duration
+	// deduplicates concurrent fetches of a provider key set
+	jwksGroup singleflight.Group
+
+	// cache tokens exchanged for downstream audiences
+	exchangedTokenCache *ttlcache.Cache[string, cachedExchangedToken]
+
+	// deduplicates concurrent token refreshes of a session
+	refreshGroup singleflight.Group
 }
 
 func NewManager(
@@ -77,6 +124,10 @@ func NewManager(
 	auditLogger *otlpaudit.AuditLogger,
 	opts ...ManagerOption,
 ) (*Manager, error) {
+	if len(cfg.CSRFSecretParsed) == 0 {
+		return nil, errors.New("CSRF secret is not loaded")
+	}
+
 	callbackURL, err := url.Parse(cfg.CallbackURL)
 	if err != nil {
 		return nil, fmt.Errorf("parsing callback URL: %w", err)
@@ -88,6 +139,8 @@ func NewManager(
 		audit:                   auditLogger,
 		sessionDuration:         cfg.SessionDuration,
 		idleSessionTimeout:      cfg.IdleSessionTimeout,
+		idTokenLeeway:           cfg.IDTokenLeeway,
+		logoutTokenMaxAge:       cfg.LogoutTokenMaxAge,
 		sessionCookieTemplate:   cfg.SessionCookieTemplate,
 		csrfCookieTemplate:      cfg.CSRFCookieTemplate,
 		loginCSRFCookieTemplate: cfg.LoginCSRFCookieTemplate,
@@ -95,6 +148,14 @@ func NewManager(
 		newCreds:                func(clientID string) credentials.TransportCredentials { return credentials.NewInsecure(clientID) },
 		csrfSecret:              cfg.CSRFSecretParsed,
 		allowedRedirectBaseURLs: parseURLs(cfg.AllowedRedirectBaseURLs),
+		jwksMinRefreshInterval:  defaultJWKSMinRefreshInterval,
+		scopes:                  cfg.Scopes,
+	}
+	if len(m.scopes) == 0 {
+		m.scopes = defaultScopes
+	}
+	if m.logoutTokenMaxAge <= 0 {
+		m.logoutTokenMaxAge = defaultLogoutTokenMaxAge
 	}
 
 	for _, opt := range opts {
@@ -103,15 +164,31 @@ func NewManager(
 		}
 	}
 
-	m.wkocCache = ttlcache.New(ttlcache.WithTTL[string, *oidc.Configuration](defaultWKOCCacheExpiration))
+	if cfg.RequestObject.Enabled {
+		m.requestObject, err = newRequestObjectSigner(cfg.RequestObject)
+		if err != nil {
+			return nil, fmt.Errorf("creating request object signer: %w", err)
+		}
+	}
+
+	m.wkocCache = ttlcache.New(ttlcache.WithTTL[string, *openIDConfiguration](defaultWKOCCacheExpiration))
 	go m.wkocCache.Start()
 	context.AfterFunc(ctx, m.wkocCache.Stop)
 
+	m.jwksCache = ttlcache.New(ttlcache.WithTTL[string, *cachedKeySet](defaultJWKSCacheExpiration))
+	go m.jwksCache.Start()
+	context.AfterFunc(ctx, m.jwksCache.Stop)
+
+	m.exchangedTokenCache = ttlcache.New(ttlcache.WithTTL[string, cachedExchangedToken](defaultExchangedTokenCacheExpiration))
+	go m.exchangedTokenCache.Start()
+	context.AfterFunc(ctx, m.exchangedTokenCache.Stop)
+
 	return m, nil
 }
 
-// MakeAuthURI returns an OIDC authentication URI.
-func (m *Manager) MakeAuthURI(ctx context.Context, tenantID, requestURI, errorURI string) (string, string, error) {
+// MakeAuthURI returns an OIDC authentication URI. The step-up requirements are
+// passed to the provider and verified when the login is finalised.
+func (m *Manager) MakeAuthURI(ctx context.Context, tenantID, requestURI, errorURI string, stepUp StepUp) (string, string, error) {
 	trust, err := m.trust.Get(ctx, tenantID)
 	if err != nil {
 		return "", "", fmt.Errorf("getting trust: %w", err)
@@ -132,14 +209,18 @@ func (m *Manager) MakeAuthURI(ctx context.Context, tenantID, requestURI, errorUR
 		return "", "", serviceerr.ErrInvalidRequest
 	}
 
+	now := time.Now()
 	state := State{
 		ID:             stateID,
 		TenantID:       tenantID,
 		PKCEVerifier:   pkce.Verifier,
+		Nonce:          m.pkce.Nonce(),
 		RequestURI:     requestURI,
 		ErrorURI:       errorURI,
-		Expiry:         time.Now().Add(m.sessionDuration),
+		Expiry:         now.Add(m.sessionDuration),
 		LoginCSRFToken: csrfToken,
+		CreatedAt:      now,
+		StepUp:         stepUp,
 	}
 
 	err = m.sessions.StoreState(ctx, state)
@@ -147,7 +228,7 @@ func (m *Manager) MakeAuthURI(ctx context.Context, tenantID, requestURI, errorUR
 		return "", "", fmt.Errorf("storing session: %w", err)
 	}
 
-	u, err := m.authURI(openidConf, state, pkce, oidc)
+	u, err := m.authURI(ctx, openidConf, state, pkce, oidc)
 	if err != nil {
 		return "", "", fmt.Errorf("generating auth uri: %w", err)
 	}
@@ -159,17 +240,57 @@ func (m *Manager) LoadState(ctx context.Context, stateID string) (State, error)
 	return m.sessions.LoadState(ctx, stateID)
 }
 
-func (m *Manager) authURI(openidConf *oidc.Configuration, state State, pkce pkce.PKCE, oidc *oidcv1.OIDC) (string, error) {
+func (m *Manager) authURI(ctx context.Context, openidConf *openIDConfiguration, state State, pkce pkce.PKCE, oidc *oidcv1.OIDC) (string, error) {
 	u, err := url.Parse(openidConf.AuthorizationEndpoint)
 	if err != nil {
 		return "", fmt.Errorf("parsing authorisation endpoint url: %w", err)
 	}
 
+	params := m.authParams(state, pkce, oidc)
+	if m.requestObject != nil {
+		params, err = m.requestObjectParams(ctx, openidConf, params, oidc)
+		if err != nil {
+			return "", fmt.Errorf("creating request object: %w", err)
+		}
+	}
+
+	//nolint:forcetypeassert
+	requirePAR := proto.GetExtension(oidc, smoidcv1.E_RequirePushedAuthorizationRequests).(bool) ||
+		openidConf.RequirePushedAuthorizationRequests
+
+	switch {
+	case openidConf.PushedAuthorizationRequestEndpoint != "":
+		requestURI, err := m.pushAuthRequest(ctx, openidConf.PushedAuthorizationRequestEndpoint, params, oidc)
+		if err != nil {
+			return "", fmt.Errorf("pushing authorisation request: %w", err)
+		}
+
+		params = url.Values{}
+		params.Set("client_id", oidc.GetClientId())
+		params.Set("request_uri", requestURI)
+	case requirePAR:
+		slogctx.Warn(ctx, "PAR is required but the provider does not advertise a PAR endpoint")
+		return "", serviceerr.ErrInvalidOIDCProvider
+	}
+
 	q := u.Query()
-	q.Set("scope", "openid profile email groups")
+	for key := range params {
+		q.Set(key, params.Get(key))
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// authParams returns the parameters of the authorisation request.
+func (m *Manager) authParams(state State, pkce pkce.PKCE, oidc *oidcv1.OIDC) url.Values {
+	q := url.Values{}
+	q.Set("scope", strings.Join(m.scopesFor(oidc), " "))
 	q.Set("response_type", "code")
 	q.Set("client_id", oidc.GetClientId())
 	q.Set("state", state.ID)
+	q.Set("nonce", state.Nonce)
 	q.Set("code_challenge", pkce.Challenge)
 	q.Set("code_challenge_method", pkce.Method)
 	q.Set("redirect_uri", m.callbackURL.String())
@@ -179,31 +300,66 @@ func (m *Manager) authURI(openidConf *oidc.Configuration, state State, pkce pkce
 		q.Set(param.GetKey(), param.GetValue())
 	}
 
-	u.RawQuery = q.Encode()
+	// The step-up is set after the auth attributes of the trust, so that
+	// they cannot weaken it
+	if len(state.StepUp.ACRValues) > 0 {
+		q.Set("acr_values", strings.Join(state.StepUp.ACRValues, " "))
+	}
+	if state.StepUp.MaxAge != nil {
+		q.Set("max_age", strconv.FormatInt(int64(state.StepUp.MaxAge.Seconds()), 10))
+	}
+	if state.StepUp.ForceLogin {
+		q.Set("prompt", "login")
+	}
 
-	return u.String(), nil
+	return q
 }
 
-func (m *Manager) getProviderKeySet(ctx context.Context, oidcConf *oidc.Configuration) (*jose.JSONWebKeySet, error) {
-	var keySet jose.JSONWebKeySet
-	uri := oidcConf.JwksURI
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+// scopesFor returns the scopes to request for the trust.
+func (m *Manager) scopesFor(oidc *oidcv1.OIDC) []string {
+	//nolint:forcetypeassert
+	if scopes := proto.GetExtension(oidc, smoidcv1.E_Scopes).([]string); len(scopes) > 0 {
+		return scopes
+	}
+
+	return m.scopes
+}
+
+// pushAuthRequest sends the authorisation request parameters to the PAR endpoint
+// as described in https://datatracker.ietf.org/doc/html/rfc9126 and returns the
+// request URI referencing them.
+func (m *Manager) pushAuthRequest(ctx context.Context, endpoint string, params url.Values, oidc *oidcv1.OIDC) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
 	if err != nil {
-		return nil, fmt.Errorf("creating a new HTTP request: %w", err)
+		return "", fmt.Errorf("creating request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := http.DefaultClient.Do(req)
+	client, err := m.httpClient(oidc.GetClientId())
 	if err != nil {
-		return nil, fmt.Errorf("executing an http request: %w", err)
+		return "", fmt.Errorf("creating http client: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	err = json.NewDecoder(resp.Body).Decode(&keySet)
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pushed authorisation request failed with status: %d", resp.StatusCode)
+	}
+
+	var parResp parResponse
+	err = json.NewDecoder(resp.Body).Decode(&parResp)
 	if err != nil {
-		return nil, fmt.Errorf("decoding keyset response: %w", err)
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+
+	if parResp.RequestURI == "" {
+		return "", errors.New("missing request_uri in the response")
 	}
 
-	return &keySet, nil
+	return parResp.RequestURI, nil
 }
 
 func (m *Manager) FinaliseOIDCLogin(ctx context.Context, stateID, code string) (OIDCSessionData, error) {
@@ -260,7 +416,7 @@ func (m *Manager) FinaliseOIDCLogin(ctx context.Context, stateID, code string) (
 		return OIDCSessionData{}, fmt.Errorf("parsing id token: %w, %s", err, algs)
 	}
 
-	keyset, err := m.getProviderKeySet(ctx, openidConf)
+	keyset, err := m.getProviderKeySet(ctx, oidc, openidConf, token.Headers[0].KeyID)
 	if err != nil {
 		m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "failed to get jwks for provider")
 		return OIDCSessionData{}, fmt.Errorf("getting jwks for a provider: %w", err)
@@ -276,18 +432,41 @@ func (m *Manager) FinaliseOIDCLogin(ctx context.Context, stateID, code string) (
 	}
 
 	type ExtraClaims struct {
-		AtHash string `json:"at_hash,omitempty"`
+		AtHash          string           `json:"at_hash,omitempty"`
+		Nonce           string           `json:"nonce,omitempty"`
+		AuthorizedParty string           `json:"azp,omitempty"`
+		ACR             string           `json:"acr,omitempty"`
+		AMR             []string         `json:"amr,omitempty"`
+		AuthTime        *jwt.NumericDate `json:"auth_time,omitempty"`
 	}
 
 	var standardClaims jwt.Claims
 	var customClaims CustomClaims
 	var extraClaims ExtraClaims
-	err = token.Claims(keyset, &standardClaims, &customClaims, &extraClaims)
+	var rawClaims map[string]any
+	err = token.Claims(keyset, &standardClaims, &customClaims, &extraClaims, &rawClaims)
 	if err != nil {
 		m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "failed to get JWT claims")
 		return OIDCSessionData{}, fmt.Errorf("getting JWT claims: %w", err)
 	}
 
+	err = m.validateIDTokenClaims(standardClaims, extraClaims.AuthorizedParty, oidc)
+	if err != nil {
+		m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "invalid id token claims")
+		return OIDCSessionData{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(extraClaims.Nonce), []byte(state.Nonce)) != 1 {
+		m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "nonce mismatch")
+		return OIDCSessionData{}, serviceerr.ErrInvalidNonce
+	}
+
+	err = m.validateStepUp(state, extraClaims.ACR, extraClaims.AuthTime)
+	if err != nil {
+		m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "step-up authentication not satisfied")
+		return OIDCSessionData{}, err
+	}
+
 	if extraClaims.AtHash != "" {
 		err := m.verifyAccessToken(tokens.AccessToken, extraClaims.AtHash, token)
 		if err != nil {
@@ -306,26 +485,68 @@ func (m *Manager) FinaliseOIDCLogin(ctx context.Context, stateID, code string) (
 		authContext[param.GetKey()] = param.GetValue()
 	}
 
-	session := Session{
-		ID:         sessionID,
-		TenantID:   state.TenantID,
-		ProviderID: customClaims.SID,
-		CSRFToken:  csrfToken,
-		Issuer:     oidc.GetIssuer(),
-		Claims: Claims{
-			Subject:    standardClaims.Subject,
-			UserUUID:   customClaims.UserUUID,
-			GivenName:  customClaims.GivenName,
-			FamilyName: customClaims.FamilyName,
-			Email:      customClaims.Email,
-			Groups:     customClaims.Groups,
-		},
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
-		Expiry:       time.Now().Add(m.sessionDuration),
-		AuthContext:  authContext,
+	claims := Claims{
+		Subject:    standardClaims.Subject,
+		UserUUID:   customClaims.UserUUID,
+		GivenName:  customClaims.GivenName,
+		FamilyName: customClaims.FamilyName,
+		Email:      customClaims.Email,
+		Groups:     customClaims.Groups,
 	}
 
+	if fetchUserInfo(oidc) {
+		userInfo, err := m.getUserInfo(ctx, openidConf, tokens.AccessToken, oidc)
+		if err != nil {
+			m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "failed to get userinfo")
+			return OIDCSessionData{}, fmt.Errorf("getting userinfo: %w", err)
+		}
+
+		// The UserInfo response must be about the user of the ID token
+		subject, _ := userInfo["sub"].(string)
+		if subtle.ConstantTimeCompare([]byte(subject), []byte(standardClaims.Subject)) != 1 {
+			m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "userinfo subject mismatch")
+			return OIDCSessionData{}, serviceerr.ErrInvalidUserInfoSubject
+		}
+
+		err = userInfoClaimMapping.Apply(userInfo, &claims)
+		if err != nil {
+			m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "failed to merge userinfo claims")
+			return OIDCSessionData{}, fmt.Errorf("merging userinfo claims: %w", err)
+		}
+
+		maps.Copy(rawClaims, userInfo)
+	}
+
+	claimMapping, err := ClaimMappingOf(oidc)
+	if err == nil {
+		err = claimMapping.Apply(rawClaims, &claims)
+	}
+	if err != nil {
+		m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "failed to map claims")
+		return OIDCSessionData{}, fmt.Errorf("mapping claims: %w", err)
+	}
+
+	now := time.Now()
+	session := Session{
+		ID:              sessionID,
+		TenantID:        state.TenantID,
+		ProviderID:      customClaims.SID,
+		ProviderSubject: standardClaims.Subject,
+		CSRFToken:       csrfToken,
+		Issuer:          oidc.GetIssuer(),
+		Claims:          claims,
+		AccessToken:     tokens.AccessToken,
+		RefreshToken:    tokens.RefreshToken,
+		Expiry:          now.Add(m.sessionDuration),
+		AuthContext:     authContext,
+		ACR:             extraClaims.ACR,
+		AMR:             extraClaims.AMR,
+	}
+	if extraClaims.AuthTime != nil {
+		session.AuthTime = extraClaims.AuthTime.Time()
+	}
+	session.AccessTokenExpiry, session.RefreshTokenExpiry = tokens.expiries(now)
+
 	err = m.sessions.StoreSession(ctx, session)
 	if err != nil {
 		m.sendUserLoginFailureAudit(ctx, metadata, state.TenantID, "failed to store session")
@@ -344,7 +565,7 @@ func (m *Manager) FinaliseOIDCLogin(ctx context.Context, stateID, code string) (
 	}
 
 	// audit userLoginSuccess
-	event, err := otlpaudit.NewUserLoginSuccessEvent(metadata, state.TenantID, otlpaudit.LOGINMETHOD_OPENIDCONNECT, otlpaudit.MFATYPE_NONE, otlpaudit.USERTYPE_BUSINESS, state.TenantID)
+	event, err := otlpaudit.NewUserLoginSuccessEvent(metadata, state.TenantID, otlpaudit.LOGINMETHOD_OPENIDCONNECT, mfaType(session.AMR), otlpaudit.USERTYPE_BUSINESS, state.TenantID)
 	if err != nil {
 		return OIDCSessionData{}, fmt.Errorf("creating audit log: %w", err)
 	}
@@ -418,81 +639,278 @@ func (m *Manager) Logout(ctx context.Context, sessionID, postLogoutRedirectURL s
 	return redirectURL.String(), nil
 }
 
-func (m *Manager) BCLogout(ctx context.Context, logoutJWT string) error {
-	token, err := jwt.ParseSigned(logoutJWT, []jose.SignatureAlgorithm{
-		jose.EdDSA,
-		jose.HS256,
-		jose.HS384,
-		jose.HS512,
-		jose.RS256,
-		jose.RS384,
-		jose.RS512,
-		jose.ES256,
-		jose.ES384,
-		jose.ES512,
-		jose.PS256,
-		jose.PS384,
-		jose.PS512,
-	})
-	if err != nil {
-		return fmt.Errorf("parsing jwt: %w", err)
+// FCLogout terminates the session of the provider session sid as described in
+// https://openid.net/specs/openid-connect-frontchannel-1_0.html. It returns the
+// tenant ID of the terminated session, or an empty string if there was no
+// session to terminate.
+func (m *Manager) FCLogout(ctx context.Context, issuer, sid string) (string, error) {
+	ctx = slogctx.With(ctx, "issuer", issuer)
+
+	if issuer == "" || sid == "" {
+		slogctx.Warn(ctx, "frontchannel-logout: missing iss or sid")
+		return "", serviceerr.ErrInvalidRequest
 	}
 
-	// Logout token must contain either a sub or a sid Claim, and may contain both.
-	type logoutTokenClaims struct {
-		jwt.Claims
+	trusts, err := m.trust.ListByIssuer(ctx, issuer)
+	if err != nil {
+		return "", fmt.Errorf("listing trusts: %w", err)
+	}
+
+	if len(trusts) == 0 {
+		slogctx.Warn(ctx, "frontchannel-logout: issuer is not trusted")
+		return "", serviceerr.ErrInvalidRequest
+	}
+
+	session, err := m.sessions.LoadSessionByProviderID(ctx, sid)
+	if err != nil {
+		if errors.Is(err, serviceerr.ErrNotFound) {
+			slogctx.Warn(ctx, "frontchannel-logout: session is not open")
+			return "", nil
+		}
+
+		return "", fmt.Errorf("loading session: %w", err)
+	}
+
+	// The sid is only unique for the issuer. Sessions of tenants which do not
+	// trust the issuer are not terminated.
+	if session.Issuer != issuer || !slices.ContainsFunc(trusts, func(trust *trustv1.Trust) bool {
+		return trust.GetTenantId() == session.TenantID
+	}) {
+		slogctx.Warn(ctx, "frontchannel-logout: session is not open")
+		return "", nil
+	}
+
+	if err := m.sessions.DeleteSession(ctx, session); err != nil {
+		slogctx.Error(ctx, "failed to delete a session", "error", err)
+		return "", fmt.Errorf("deleting session: %w", err)
+	}
 
-		// Events is always "http://schemas.openid.net/event/backchannel-logout": {}
-		Events    map[string]json.RawMessage `json:"events,omitempty"`
-		SessionID string                     `json:"sid,omitempty"`
+	return session.TenantID, nil
+}
+
+// BCLogout terminates the sessions identified by the back-channel logout token as
+// described in https://openid.net/specs/openid-connect-backchannel-1_0.html.
+// The logout token is verified before any session is looked up.
+func (m *Manager) BCLogout(ctx context.Context, logoutJWT string) error {
+	token, err := jwt.ParseSigned(logoutJWT, logoutTokenAlgorithms)
+	if err != nil {
+		slogctx.FromCtx(ctx).WarnContext(ctx, "backchannel-logout: failed to parse the logout token", "error", err)
+		return serviceerr.ErrInvalidRequest
 	}
 
+	// The unverified issuer is only used to select the trusts to verify the logout token against.
 	var unsafeClaims logoutTokenClaims
 	if err := token.UnsafeClaimsWithoutVerification(&unsafeClaims); err != nil {
 		slogctx.FromCtx(ctx).WarnContext(ctx, "failed to parse claims", "error", err)
-		return fmt.Errorf("parsing claims unsafe: %w", err)
-	}
-
-	if _, ok := unsafeClaims.Events["http://schemas.openid.net/event/backchannel-logout"]; !ok {
-		slogctx.FromCtx(ctx).WarnContext(ctx, "backchannel-logout: JWT token is not a logout token")
 		return serviceerr.ErrInvalidRequest
 	}
 
-	if unsafeClaims.SessionID == "" {
-		slogctx.FromCtx(ctx).WarnContext(ctx, "missing session id in the claims")
+	if unsafeClaims.Issuer == "" {
+		slogctx.FromCtx(ctx).WarnContext(ctx, "backchannel-logout: missing issuer in the claims")
 		return serviceerr.ErrInvalidRequest
 	}
 
-	session, err := m.sessions.LoadSessionByProviderID(ctx, unsafeClaims.SessionID)
+	ctx = slogctx.With(ctx, "issuer", unsafeClaims.Issuer)
+
+	trusts, err := m.trust.ListByIssuer(ctx, unsafeClaims.Issuer)
 	if err != nil {
+		return fmt.Errorf("listing trusts: %w", err)
+	}
+
+	// Several tenants may trust the issuer. The sessions of all tenants whose
+	// trust the logout token is valid for are terminated.
+	var (
+		claims    logoutTokenClaims
+		tenantIDs = make(map[string]struct{}, len(trusts))
+		tokenErr  error
+	)
+	for _, trust := range trusts {
+		tenantClaims, err := m.verifyLogoutToken(ctx, token, trust.GetOidc())
+		if err != nil {
+			slogctx.FromCtx(ctx).WarnContext(ctx, "backchannel-logout: invalid logout token", "tenantId", trust.GetTenantId(), "error", err)
+			tokenErr = err
+			continue
+		}
+
+		claims = tenantClaims
+		tenantIDs[trust.GetTenantId()] = struct{}{}
+	}
+
+	if len(tenantIDs) == 0 {
+		if tokenErr == nil {
+			slogctx.FromCtx(ctx).WarnContext(ctx, "backchannel-logout: issuer is not trusted")
+			return serviceerr.ErrInvalidRequest
+		}
+
+		return tokenErr
+	}
+
+	// Each logout token is accepted once. Its ID is remembered as long as the
+	// token would pass the validation of its issue time.
+	expiry := claims.IssuedAt.Time().Add(m.logoutTokenMaxAge + m.idTokenLeeway)
+	if err := m.sessions.StoreLogoutTokenID(ctx, claims.Issuer, claims.ID, expiry); err != nil {
+		if errors.Is(err, serviceerr.ErrConflict) {
+			slogctx.FromCtx(ctx).WarnContext(ctx, "backchannel-logout: logout token replayed", "jti", claims.ID)
+			return serviceerr.ErrInvalidRequest
+		}
+
+		return fmt.Errorf("storing logout token id: %w", err)
+	}
+
+	if err := m.deleteLogoutSessions(ctx, claims, tenantIDs); err != nil {
+		// Forget the token ID so that the provider can retry the logout
+		if err := m.sessions.DeleteLogoutTokenID(ctx, claims.Issuer, claims.ID); err != nil {
+			slogctx.FromCtx(ctx).ErrorContext(ctx, "backchannel-logout: could not delete the logout token id", "jti", claims.ID, "error", err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// deleteLogoutSessions deletes the sessions of the tenants identified by the
+// logout token.
+func (m *Manager) deleteLogoutSessions(ctx context.Context, claims logoutTokenClaims, tenantIDs map[string]struct{}) error {
+	sessions, err := m.logoutSessions(ctx, claims)
+	if err != nil && !errors.Is(err, serviceerr.ErrNotFound) {
+		return fmt.Errorf("getting sessions: %w", err)
+	}
+	if len(sessions) == 0 {
 		slogctx.FromCtx(ctx).WarnContext(ctx, "backchannel-logout: session is not open")
 		return nil
 	}
 
-	trust, err := m.trust.Get(ctx, session.TenantID)
-	if err != nil {
-		return fmt.Errorf("getting trust: %w", err)
+	for _, session := range sessions {
+		// Sessions of other tenants are not covered by the verified logout token.
+		if _, ok := tenantIDs[session.TenantID]; !ok || session.Issuer != claims.Issuer {
+			continue
+		}
+
+		if err := m.sessions.DeleteSession(ctx, session); err != nil {
+			return fmt.Errorf("deleting session: %w", err)
+		}
 	}
 
-	oidc := trust.GetOidc()
+	return nil
+}
 
+// verifyLogoutToken verifies the signature and the claims of the logout token
+// against the OIDC trust.
+func (m *Manager) verifyLogoutToken(ctx context.Context, token *jwt.JSONWebToken, oidc *oidcv1.OIDC) (logoutTokenClaims, error) {
 	oidcConf, err := m.getOpenIDConfig(ctx, oidc.GetIssuer())
 	if err != nil {
-		return fmt.Errorf("getting oidc config: %w", err)
+		return logoutTokenClaims{}, fmt.Errorf("getting oidc config: %w", err)
 	}
 
-	keyset, err := m.getProviderKeySet(ctx, oidcConf)
+	alg := jose.SignatureAlgorithm(token.Headers[0].Algorithm)
+	if !slices.Contains(providerSigningAlgorithms(oidcConf), alg) {
+		return logoutTokenClaims{}, errors.Join(serviceerr.ErrInvalidRequest, fmt.Errorf("signing algorithm %s not supported by the provider", alg))
+	}
+
+	keyset, err := m.getProviderKeySet(ctx, oidc, oidcConf, token.Headers[0].KeyID)
 	if err != nil {
-		return fmt.Errorf("getting jwks for a provider: %w", err)
+		return logoutTokenClaims{}, fmt.Errorf("getting jwks for a provider: %w", err)
 	}
 
 	var claims logoutTokenClaims
 	if err := token.Claims(keyset, &claims); err != nil {
-		return fmt.Errorf("parsing claims: %w", err)
+		return logoutTokenClaims{}, errors.Join(serviceerr.ErrInvalidRequest, fmt.Errorf("verifying logout token: %w", err))
 	}
 
-	if err := m.sessions.DeleteSession(ctx, session); err != nil {
-		return fmt.Errorf("deleting session: %w", err)
+	if err := m.validateLogoutTokenClaims(claims, oidc); err != nil {
+		return logoutTokenClaims{}, errors.Join(serviceerr.ErrInvalidRequest, err)
+	}
+
+	return claims, nil
+}
+
+// providerSigningAlgorithms returns the asymmetric algorithms the provider
+// advertises for signing ID tokens. Providers which do not advertise any
+// algorithm sign with RS256.
+func providerSigningAlgorithms(oidcConf *openIDConfiguration) []jose.SignatureAlgorithm {
+	if len(oidcConf.IDTokenSigningAlgValuesSupported) == 0 {
+		return []jose.SignatureAlgorithm{jose.RS256}
+	}
+
+	algs := make([]jose.SignatureAlgorithm, 0, len(oidcConf.IDTokenSigningAlgValuesSupported))
+	for _, alg := range oidcConf.IDTokenSigningAlgValuesSupported {
+		if slices.Contains(logoutTokenAlgorithms, jose.SignatureAlgorithm(alg)) {
+			algs = append(algs, jose.SignatureAlgorithm(alg))
+		}
+	}
+
+	return algs
+}
+
+// logoutTokenClaims are the claims of a back-channel logout token as described in
+// https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken.
+type logoutTokenClaims struct {
+	jwt.Claims
+
+	// Events is always "http://schemas.openid.net/event/backchannel-logout": {}
+	Events    map[string]json.RawMessage `json:"events,omitempty"`
+	SessionID string                     `json:"sid,omitempty"`
+	Nonce     string                     `json:"nonce,omitempty"`
+}
+
+// logoutSessions returns the sessions terminated by the logout token. With a sid
+// claim it is the session of that provider session, otherwise all sessions of
+// the subject.
+func (m *Manager) logoutSessions(ctx context.Context, claims logoutTokenClaims) ([]Session, error) {
+	if claims.SessionID == "" {
+		return m.sessions.ListSessionsBySubject(ctx, claims.Issuer, claims.Subject)
+	}
+
+	session, err := m.sessions.LoadSessionByProviderID(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject != "" && session.ProviderSubject != "" && session.ProviderSubject != claims.Subject {
+		return nil, nil
+	}
+
+	return []Session{session}, nil
+}
+
+// validateLogoutTokenClaims validates the claims of a verified logout token
+// against the trust as described in
+// https://openid.net/specs/openid-connect-backchannel-1_0.html#Validation.
+func (m *Manager) validateLogoutTokenClaims(claims logoutTokenClaims, oidc *oidcv1.OIDC) error {
+	now := time.Now()
+	err := claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:      oidc.GetIssuer(),
+		AnyAudience: append(jwt.Audience{oidc.GetClientId()}, oidc.GetAudiences()...),
+		Time:        now,
+	}, m.idTokenLeeway)
+	if err != nil {
+		return err
+	}
+
+	if claims.IssuedAt == nil {
+		return errors.New("missing iat claim")
+	}
+
+	if claims.IssuedAt.Time().Before(now.Add(-m.logoutTokenMaxAge - m.idTokenLeeway)) {
+		return errors.New("logout token too old")
+	}
+
+	if claims.ID == "" {
+		return errors.New("missing jti claim")
+	}
+
+	if claims.Nonce != "" {
+		return errors.New("logout token must not contain a nonce claim")
+	}
+
+	if _, ok := claims.Events[backChannelLogoutEvent]; !ok {
+		return errors.New("missing backchannel-logout event")
+	}
+
+	// Logout token must contain either a sub or a sid Claim, and may contain both.
+	if claims.SessionID == "" && claims.Subject == "" {
+		return errors.New("missing sid and sub claims")
 	}
 
 	return nil
@@ -591,6 +1009,115 @@ func (m *Manager) sendUserLoginFailureAudit(ctx context.Context, metadata otlpau
 	slogctx.Debug(ctx, "sent audit log for user login failure")
 }
 
+// sendSessionRevocationAudit creates the credential-revocation audit event for a
+// session ended because the provider rejected its refresh token and sends it.
+// Like sendUserLoginFailureAudit, it only logs errors.
+func (m *Manager) sendSessionRevocationAudit(ctx context.Context, s Session) {
+	if m.audit == nil {
+		slogctx.Warn(ctx, "audit logger is nil; skipping session revocation event")
+		return
+	}
+
+	metadata, err := otlpaudit.NewEventMetadata("session manager", s.TenantID, uuid.Must(uuid.NewV4()).String())
+	if err != nil {
+		slogctx.Error(ctx, "creating audit metadata", "error", err)
+		return
+	}
+
+	event, err := otlpaudit.NewCredentialRevokationEvent(metadata, s.ID, otlpaudit.CREDTYPE_SECRET)
+	if err != nil {
+		slogctx.Error(ctx, "creating audit log", "error", err)
+		return
+	}
+
+	err = m.audit.SendEvent(ctx, event)
+	if err != nil {
+		slogctx.Error(ctx, "Failed to send audit log for session revocation", "error", err)
+	}
+	slogctx.Debug(ctx, "sent audit log for session revocation")
+}
+
+// validateIDTokenClaims validates the ID token claims as described in
+// https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation
+func (m *Manager) validateIDTokenClaims(claims jwt.Claims, azp string, oidc *oidcv1.OIDC) error {
+	err := claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:      oidc.GetIssuer(),
+		AnyAudience: append(jwt.Audience{oidc.GetClientId()}, oidc.GetAudiences()...),
+		Time:        time.Now(),
+	}, m.idTokenLeeway)
+	switch {
+	case errors.Is(err, jwt.ErrInvalidIssuer):
+		return serviceerr.ErrInvalidIDTokenIssuer
+	case errors.Is(err, jwt.ErrInvalidAudience):
+		return serviceerr.ErrInvalidIDTokenAud
+	case errors.Is(err, jwt.ErrExpired):
+		return serviceerr.ErrIDTokenExpired
+	case errors.Is(err, jwt.ErrIssuedInTheFuture), errors.Is(err, jwt.ErrNotValidYet):
+		return serviceerr.ErrIDTokenNotValidYet
+	case err != nil:
+		return fmt.Errorf("validating id token claims: %w", err)
+	}
+
+	if claims.Expiry == nil {
+		return serviceerr.ErrIDTokenExpired
+	}
+
+	if claims.IssuedAt == nil {
+		return serviceerr.ErrIDTokenNotValidYet
+	}
+
+	// The azp claim must be present if the token has multiple audiences or
+	// is not issued to our client ID, and must be our client ID if present.
+	azpRequired := len(claims.Audience) > 1 || !claims.Audience.Contains(oidc.GetClientId())
+	if (azpRequired || azp != "") && azp != oidc.GetClientId() {
+		return serviceerr.ErrInvalidIDTokenAZP
+	}
+
+	return nil
+}
+
+// validateStepUp verifies that the authentication reported in the ID token
+// satisfies the step-up requirements of the login.
+func (m *Manager) validateStepUp(state State, acr string, authTime *jwt.NumericDate) error {
+	stepUp := state.StepUp
+	if len(stepUp.ACRValues) > 0 && !slices.Contains(stepUp.ACRValues, acr) {
+		return serviceerr.ErrInvalidIDTokenACR
+	}
+
+	// The auth_time claim is required if max_age has been requested and
+	// must not be older than the login process if a login was enforced.
+	var notBefore time.Time
+	if stepUp.MaxAge != nil {
+		notBefore = time.Now().Add(-*stepUp.MaxAge)
+	}
+	if stepUp.ForceLogin && state.CreatedAt.After(notBefore) {
+		notBefore = state.CreatedAt
+	}
+	if notBefore.IsZero() {
+		return nil
+	}
+
+	if authTime == nil || authTime.Time().Before(notBefore.Add(-m.idTokenLeeway)) {
+		return serviceerr.ErrInvalidIDTokenAuthTime
+	}
+
+	return nil
+}
+
+// mfaType maps the authentication methods of the `amr` claim to the MFA type of
+// the audit log. The audit log only distinguishes WebAuthn, which providers report
+// as proof-of-possession of a hardware or software key as defined in RFC 8176.
+func mfaType(amr []string) otlpaudit.MfaType {
+	for _, method := range amr {
+		switch method {
+		case "hwk", "swk", "fido", "webauthn":
+			return otlpaudit.MFATYPE_WEBAUTHN
+		}
+	}
+
+	return otlpaudit.MFATYPE_NONE
+}
+
 func (m *Manager) verifyAccessToken(accessToken, atHash string, idToken *jwt.JSONWebToken) error {
 	var h hash.Hash
 	switch alg := idToken.Headers[0].Algorithm; alg {
@@ -661,7 +1188,7 @@ func (m *Manager) httpClient(clientID string) (*http.Client, error) {
 	}, nil
 }
 
-func (m *Manager) exchangeCode(ctx context.Context, openidConf *oidc.Configuration, code, codeVerifier string, oidc *oidcv1.OIDC) (tokenResponse, error) {
+func (m *Manager) exchangeCode(ctx context.Context, openidConf *openIDConfiguration, code, codeVerifier string, oidc *oidcv1.OIDC) (tokenResponse, error) {
 	data := url.Values{}
 	data.Set("grant_type", "authorization_code")
 	data.Set("code", code)
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -19,7 +20,9 @@ import (
 	"github.com/openkcm/common-sdk/pkg/oidc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
+	flowv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/flow/v1"
 	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
 	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
 	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
@@ -29,6 +32,8 @@ import (
 	"github.com/openkcm/session-manager/internal/session"
 	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
 	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
 )
 
 const (
@@ -36,6 +41,16 @@ const (
 	testClientID   = "my-client-id"
 )
 
+func TestNewManager_MissingCSRFSecret(t *testing.T) {
+	_, err := session.NewManager(t.Context(),
+		&config.SessionManager{CallbackURL: "http://localhost/sm/callback"},
+		nil,
+		sessionmock.NewInMemRepository(),
+		nil,
+	)
+	assert.ErrorContains(t, err, "CSRF secret is not loaded")
+}
+
 func TestManager_Auth(t *testing.T) {
 	ctx := t.Context()
 	const (
@@ -68,6 +83,7 @@ func TestManager_Auth(t *testing.T) {
 		requestURI string
 		cfg        *config.SessionManager
 		tenantID   string
+		stepUp     session.StepUp
 		wantURL    string
 		errAssert  assert.ErrorAssertionFunc
 		trust      *trustv1.Trust
@@ -87,7 +103,27 @@ func TestManager_Auth(t *testing.T) {
 				CSRFSecretParsed: []byte(testCSRFSecret),
 			},
 			tenantID:  tenantID,
-			wantURL:   oidcServer.URL + "/oauth2/authorize?client_id=my-client-id&code_challenge=someChallenge&code_challenge_method=S256&redirect_uri=" + callbackURL + "&response_type=code&scope=openid+profile+email+groups&state=someState",
+			wantURL:   oidcServer.URL + "/oauth2/authorize?client_id=my-client-id&code_challenge=someChallenge&code_challenge_method=S256&nonce=someNonce&redirect_uri=" + callbackURL + "&response_type=code&scope=openid+profile+email+groups&state=someState",
+			errAssert: assert.NoError,
+		},
+		{
+			name:       "Success with step-up",
+			oidc:       mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust)),
+			sessions:   sessionmock.NewInMemRepository(),
+			requestURI: requestURI,
+			cfg: &config.SessionManager{
+				SessionDuration:         time.Hour,
+				CallbackURL:             callbackURL,
+				AllowedRedirectBaseURLs: []string{"http://localhost"},
+				CSRFSecretParsed:        []byte(testCSRFSecret),
+			},
+			tenantID: tenantID,
+			stepUp: session.StepUp{
+				ACRValues:  []string{"urn:mfa", "urn:hwk"},
+				MaxAge:     new(5 * time.Minute),
+				ForceLogin: true,
+			},
+			wantURL:   oidcServer.URL + "/oauth2/authorize?acr_values=urn%3Amfa+urn%3Ahwk&client_id=my-client-id&code_challenge=someChallenge&code_challenge_method=S256&max_age=300&nonce=someNonce&prompt=login&redirect_uri=" + callbackURL + "&response_type=code&scope=openid+profile+email+groups&state=someState",
 			errAssert: assert.NoError,
 		},
 		{
@@ -129,10 +165,14 @@ func TestManager_Auth(t *testing.T) {
 				kResponseType        = "response_type"
 				kClientID            = "client_id"
 				kState               = "state"
+				kNonce               = "nonce"
 				kCodeChallenge       = "code_challenge"
 				kCodeChallengeMethod = "code_challenge_method"
 				kRedirectURI         = "redirect_uri"
 				kParamAuth1          = "paramAuth1"
+				kACRValues           = "acr_values"
+				kMaxAge              = "max_age"
+				kPrompt              = "prompt"
 			)
 
 			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
@@ -146,7 +186,7 @@ func TestManager_Auth(t *testing.T) {
 				session.WithAllowHttpScheme(true),
 			)
 			require.NoError(t, err)
-			got, _, err := m.MakeAuthURI(t.Context(), tt.tenantID, tt.requestURI, "")
+			got, _, err := m.MakeAuthURI(t.Context(), tt.tenantID, tt.requestURI, "", tt.stepUp)
 
 			if !tt.errAssert(t, err, fmt.Sprintf("Manager.Auth() error = %v", err)) || err != nil {
 				return
@@ -175,6 +215,9 @@ func TestManager_Auth(t *testing.T) {
 			assert.Equal(t, wantQ.Get(kCodeChallengeMethod), q.Get(kCodeChallengeMethod), "Unexpected code challenge")
 			assert.Equal(t, wantQ.Get(kRedirectURI), q.Get(kRedirectURI), "Unexpected redirect URI")
 			assert.Equal(t, wantQ.Get(kParamAuth1), q.Get(kParamAuth1), "Unexpected auth url")
+			assert.Equal(t, wantQ.Get(kACRValues), q.Get(kACRValues), "Unexpected acr values")
+			assert.Equal(t, wantQ.Get(kMaxAge), q.Get(kMaxAge), "Unexpected max age")
+			assert.Equal(t, wantQ.Get(kPrompt), q.Get(kPrompt), "Unexpected prompt")
 
 			// Check the scopes on the URL string to ensure we don't have
 			// something like scope=openid&scope=profile...
@@ -185,6 +228,310 @@ func TestManager_Auth(t *testing.T) {
 			// These values are generated randomly. So check if they aren't empty
 			assert.NotEmpty(t, q.Get(kState), "State is zero")
 			assert.NotEmpty(t, q.Get(kCodeChallenge), "Code challenge is zero")
+			assert.NotEmpty(t, q.Get(kNonce), "Nonce is zero")
+
+			state, err := tt.sessions.LoadState(t.Context(), q.Get(kState))
+			require.NoError(t, err, "loading state")
+			assert.Equal(t, q.Get(kNonce), state.Nonce, "Nonce has not been stored in the state")
+			assert.Equal(t, tt.stepUp, state.StepUp, "Step-up has not been stored in the state")
+		})
+	}
+}
+
+func TestManager_Auth_StepUpOverAuthAttributes(t *testing.T) {
+	const tenantID = "tenant-id"
+
+	oidcServer := StartOIDCServer(t, false)
+	defer oidcServer.Close()
+
+	auditServer := StartAuditServer(t)
+	defer auditServer.Close()
+
+	oidc := oidcv1.OIDC_builder{
+		Issuer:   new(oidcServer.URL),
+		ClientId: new(testClientID),
+	}.Build()
+	proto.SetExtension(oidc, flowv1.E_AuthAttributes, []*flowv1.Attribute{
+		flowv1.Attribute_builder{Key: new("acr_values"), Value: new("urn:pwd")}.Build(),
+		flowv1.Attribute_builder{Key: new("max_age"), Value: new("86400")}.Build(),
+		flowv1.Attribute_builder{Key: new("prompt"), Value: new("none")}.Build(),
+		flowv1.Attribute_builder{Key: new("paramAuth1"), Value: new("valueAuth1")}.Build(),
+	})
+	oidcTrust := trustv1.Trust_builder{
+		TenantId: new(tenantID),
+		Blocked:  new(false),
+		Oidc:     oidc,
+	}.Build()
+
+	tests := []struct {
+		name      string
+		stepUp    session.StepUp
+		wantQuery url.Values
+	}{
+		{
+			name: "Auth attributes without step-up",
+			wantQuery: url.Values{
+				"acr_values": {"urn:pwd"},
+				"max_age":    {"86400"},
+				"prompt":     {"none"},
+				"paramAuth1": {"valueAuth1"},
+			},
+		},
+		{
+			name: "Step-up takes precedence",
+			stepUp: session.StepUp{
+				ACRValues:  []string{"urn:mfa"},
+				MaxAge:     new(5 * time.Minute),
+				ForceLogin: true,
+			},
+			wantQuery: url.Values{
+				"acr_values": {"urn:mfa"},
+				"max_age":    {"300"},
+				"prompt":     {"login"},
+				"paramAuth1": {"valueAuth1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      "http://localhost/sm/callback",
+					CSRFSecretParsed: []byte(testCSRFSecret),
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessionmock.NewInMemRepository(),
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			got, _, err := m.MakeAuthURI(t.Context(), tenantID, "/ui", "", tt.stepUp)
+			require.NoError(t, err)
+
+			u, err := url.Parse(got)
+			require.NoError(t, err)
+			for k, v := range tt.wantQuery {
+				assert.Equal(t, v, u.Query()[k], "query parameter %s", k)
+			}
+		})
+	}
+}
+
+func TestManager_Auth_Scopes(t *testing.T) {
+	const tenantID = "tenant-id"
+
+	oidcServer := StartOIDCServer(t, false)
+	defer oidcServer.Close()
+
+	auditServer := StartAuditServer(t)
+	defer auditServer.Close()
+
+	tests := []struct {
+		name        string
+		cfgScopes   []string
+		trustScopes []string
+		wantScope   string
+	}{
+		{
+			name:      "Default scopes",
+			wantScope: "openid profile email groups",
+		},
+		{
+			name:      "Configured scopes",
+			cfgScopes: []string{"openid", "email"},
+			wantScope: "openid email",
+		},
+		{
+			name:        "Trust scopes take precedence",
+			cfgScopes:   []string{"openid", "email"},
+			trustScopes: []string{"openid", "profile", "offline_access"},
+			wantScope:   "openid profile offline_access",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oidc := oidcv1.OIDC_builder{
+				Issuer:   new(oidcServer.URL),
+				ClientId: new(testClientID),
+			}.Build()
+			if tt.trustScopes != nil {
+				proto.SetExtension(oidc, smoidcv1.E_Scopes, tt.trustScopes)
+			}
+			oidcTrust := trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(false),
+				Oidc:     oidc,
+			}.Build()
+
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      "http://localhost/sm/callback",
+					CSRFSecretParsed: []byte(testCSRFSecret),
+					Scopes:           tt.cfgScopes,
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessionmock.NewInMemRepository(),
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			got, _, err := m.MakeAuthURI(t.Context(), tenantID, "/ui", "", session.StepUp{})
+			require.NoError(t, err)
+
+			u, err := url.Parse(got)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantScope, u.Query().Get("scope"))
+		})
+	}
+}
+
+func TestManager_Auth_PAR(t *testing.T) {
+	const (
+		requestURI    = "/ui"
+		callbackURL   = "http://localhost/sm/callback"
+		tenantID      = "tenant-id"
+		parRequestURI = "urn:ietf:params:oauth:request_uri:6esc_11ACC5bwc014ltc14eY22c"
+	)
+
+	auditServer := StartAuditServer(t)
+	defer auditServer.Close()
+
+	tests := []struct {
+		name        string
+		parEndpoint bool
+		parStatus   int
+		requirePAR  bool
+		errAssert   assert.ErrorAssertionFunc
+	}{
+		{
+			name:        "Pushes the request when the provider supports PAR",
+			parEndpoint: true,
+			parStatus:   http.StatusCreated,
+			errAssert:   assert.NoError,
+		},
+		{
+			name:        "Pushes the request when the trust requires PAR",
+			parEndpoint: true,
+			parStatus:   http.StatusCreated,
+			requirePAR:  true,
+			errAssert:   assert.NoError,
+		},
+		{
+			name:        "PAR endpoint error",
+			parEndpoint: true,
+			parStatus:   http.StatusBadRequest,
+			errAssert:   assert.Error,
+		},
+		{
+			name:       "PAR required but not supported by the provider",
+			requirePAR: true,
+			errAssert: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, serviceerr.ErrInvalidOIDCProvider)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pushed url.Values
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/.well-known/openid-configuration" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				conf := map[string]any{
+					"issuer":                 server.URL,
+					"authorization_endpoint": server.URL + "/oauth2/authorize",
+					"token_endpoint":         server.URL + "/oauth2/token",
+				}
+				if tt.parEndpoint {
+					conf["pushed_authorization_request_endpoint"] = server.URL + "/oauth2/par"
+				}
+				_ = json.NewEncoder(w).Encode(conf)
+			}))
+			defer server.Close()
+
+			parHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/oauth2/par" || r.Method != http.MethodPost {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				if err := r.ParseForm(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				pushed = r.PostForm
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.parStatus)
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"request_uri": parRequestURI,
+					"expires_in":  60,
+				})
+			})
+
+			oidc := oidcv1.OIDC_builder{
+				Issuer:   new(server.URL),
+				ClientId: new(testClientID),
+			}.Build()
+			if tt.requirePAR {
+				proto.SetExtension(oidc, smoidcv1.E_RequirePushedAuthorizationRequests, true)
+			}
+			oidcTrust := trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(false),
+				Oidc:     oidc,
+			}.Build()
+
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      callbackURL,
+					CSRFSecretParsed: []byte(testCSRFSecret),
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessionmock.NewInMemRepository(),
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+				session.WithTransportCredentials(newTCBuilder(localRoundTripper{handler: parHandler})),
+			)
+			require.NoError(t, err)
+
+			got, _, err := m.MakeAuthURI(t.Context(), tenantID, requestURI, "", session.StepUp{})
+			if !tt.errAssert(t, err, fmt.Sprintf("Manager.MakeAuthURI() error = %v", err)) || err != nil {
+				return
+			}
+
+			u, err := url.Parse(got)
+			require.NoError(t, err, "parsing location")
+			assert.Equal(t, "/oauth2/authorize", u.Path)
+			assert.Equal(t, url.Values{
+				"client_id":   {testClientID},
+				"request_uri": {parRequestURI},
+			}, u.Query())
+
+			require.NotNil(t, pushed, "authorisation request has not been pushed")
+			assert.Equal(t, testClientID, pushed.Get("client_id"))
+			assert.Equal(t, "code", pushed.Get("response_type"))
+			assert.Equal(t, callbackURL, pushed.Get("redirect_uri"))
+			assert.Equal(t, "openid profile email groups", pushed.Get("scope"))
+			assert.NotEmpty(t, pushed.Get("state"))
+			assert.NotEmpty(t, pushed.Get("code_challenge"))
 		})
 	}
 }
@@ -203,10 +550,14 @@ func TestManager_FinaliseOIDCLogin(t *testing.T) {
 		ID:           stateID,
 		TenantID:     tenantID,
 		PKCEVerifier: pkceVerifier,
+		Nonce:        testNonce,
 		RequestURI:   requestURI,
 		Expiry:       time.Now().Add(time.Hour),
 	}
 
+	nonceMismatchState := validState
+	nonceMismatchState.Nonce = "other-nonce"
+
 	expiredState := session.State{
 		ID:           stateID,
 		TenantID:     tenantID,
@@ -287,6 +638,21 @@ func TestManager_FinaliseOIDCLogin(t *testing.T) {
 			wantRedirectURI: "",
 			errAssert:       assert.Error,
 		},
+		{
+			name:     "Nonce mismatch",
+			oidc:     mocktrust.NewInMemRepository(),
+			sessions: sessionmock.NewInMemRepository(sessionmock.WithState(nonceMismatchState)),
+			stateID:  stateID,
+			code:     code,
+			cfg: &config.SessionManager{
+				SessionDuration:  time.Hour,
+				CallbackURL:      callbackURL,
+				CSRFSecretParsed: []byte(testCSRFSecret),
+			},
+			errAssert: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, serviceerr.ErrInvalidNonce)
+			},
+		},
 		{
 			name:     "Token exchange error",
 			oidc:     mocktrust.NewInMemRepository(),
@@ -378,84 +744,208 @@ func TestManager_FinaliseOIDCLogin(t *testing.T) {
 	}
 }
 
-func TestManager_BCLogout(t *testing.T) {
-	key, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		panic(err)
-	}
+func TestManager_FinaliseOIDCLogin_IDTokenValidation(t *testing.T) {
+	const (
+		tenantID     = "tenant-id"
+		stateID      = "test-state-id"
+		testAudience = "https://api.example.com"
+	)
 
-	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
-		Key:       key,
-		KeyID:     "kid1",
-		Algorithm: string(jose.RS256),
-	}}}
-	signer, err := jose.NewSigner(jose.SigningKey{
-		Algorithm: jose.RS256,
-		Key:       jwks.Keys[0],
-	}, nil)
-	if err != nil {
-		panic(err)
+	now := time.Now()
+	tests := []struct {
+		name    string
+		claims  map[string]any
+		leeway  time.Duration
+		wantErr error
+	}{
+		{
+			name: "Valid token",
+		},
+		{
+			name:    "Wrong issuer",
+			claims:  map[string]any{"iss": "https://evil.example.com"},
+			wantErr: serviceerr.ErrInvalidIDTokenIssuer,
+		},
+		{
+			name:    "Wrong audience",
+			claims:  map[string]any{"aud": "other-client"},
+			wantErr: serviceerr.ErrInvalidIDTokenAud,
+		},
+		{
+			name:    "Expired",
+			claims:  map[string]any{"exp": jwt.NewNumericDate(now.Add(-time.Hour))},
+			wantErr: serviceerr.ErrIDTokenExpired,
+		},
+		{
+			name:   "Expired within leeway",
+			claims: map[string]any{"exp": jwt.NewNumericDate(now.Add(-30 * time.Second))},
+			leeway: time.Minute,
+		},
+		{
+			name:    "Missing expiry",
+			claims:  map[string]any{"exp": nil},
+			wantErr: serviceerr.ErrIDTokenExpired,
+		},
+		{
+			name:    "Issued in the future",
+			claims:  map[string]any{"iat": jwt.NewNumericDate(now.Add(time.Hour))},
+			wantErr: serviceerr.ErrIDTokenNotValidYet,
+		},
+		{
+			name:    "Missing issued at",
+			claims:  map[string]any{"iat": nil},
+			wantErr: serviceerr.ErrIDTokenNotValidYet,
+		},
+		{
+			name:    "Multiple audiences without azp",
+			claims:  map[string]any{"aud": []string{testClientID, "other-client"}},
+			wantErr: serviceerr.ErrInvalidIDTokenAZP,
+		},
+		{
+			name:   "Multiple audiences with azp",
+			claims: map[string]any{"aud": []string{testClientID, "other-client"}, "azp": testClientID},
+		},
+		{
+			name:    "Wrong azp",
+			claims:  map[string]any{"azp": "other-client"},
+			wantErr: serviceerr.ErrInvalidIDTokenAZP,
+		},
+		{
+			name:   "Configured audience with azp",
+			claims: map[string]any{"aud": testAudience, "azp": testClientID},
+		},
+		{
+			name:    "Configured audience without azp",
+			claims:  map[string]any{"aud": testAudience},
+			wantErr: serviceerr.ErrInvalidIDTokenAZP,
+		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oidcServer := StartOIDCServerWithIDTokenClaims(t, false, tt.claims)
+			defer oidcServer.Close()
 
-	newJwt := func(claims any) string {
-		token, err := jwt.Signed(signer).Claims(claims).Serialize()
-		if err != nil {
-			panic(err)
-		}
+			auditServer := StartAuditServer(t)
+			defer auditServer.Close()
 
-		return token
-	}
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
 
-	jwksSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		publicJwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
-			Key:       &key.PublicKey,
-			KeyID:     "kid1",
-			Algorithm: string(jose.RS256),
-		}}}
-		b, err := json.Marshal(publicJwks)
-		if err != nil {
-			panic(err)
-		}
+			oidcTrust := trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(false),
+				Oidc: oidcv1.OIDC_builder{
+					Issuer:    new(oidcServer.URL),
+					Audiences: []string{testAudience},
+					ClientId:  new(testClientID),
+				}.Build(),
+			}.Build()
 
-		if _, err := w.Write(b); err != nil {
-			panic(err)
-		}
-	}))
+			sessions := sessionmock.NewInMemRepository(sessionmock.WithState(session.State{
+				ID:       stateID,
+				TenantID: tenantID,
+				Nonce:    testNonce,
+				Expiry:   time.Now().Add(time.Hour),
+			}))
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      "http://sm.example.com/sm/callback",
+					CSRFSecretParsed: []byte(testCSRFSecret),
+					IDTokenLeeway:    tt.leeway,
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessions,
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			_, err = m.FinaliseOIDCLogin(t.Context(), stateID, "auth-code")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
 
+func TestManager_FinaliseOIDCLogin_StepUp(t *testing.T) {
+	const (
+		tenantID = "tenant-id"
+		stateID  = "test-state-id"
+	)
+
+	now := time.Now()
 	tests := []struct {
-		name      string
-		cfg       *config.SessionManager
-		jwt       string
-		setupMock func(*mocktrust.Repository, *sessionmock.Repository)
-		errAssert assert.ErrorAssertionFunc
+		name    string
+		stepUp  session.StepUp
+		claims  map[string]any
+		wantACR string
+		wantAMR []string
+		wantErr error
+
+		wantAuthTime time.Time
 	}{
 		{
-			name: "Success",
-			cfg:  &config.SessionManager{},
-			jwt: newJwt(struct {
-				Events    map[string]struct{} `json:"events"`
-				SessionID string              `json:"sid"`
-				KeyID     string              `json:"kid"`
-			}{
-				Events:    map[string]struct{}{"http://schemas.openid.net/event/backchannel-logout": {}},
-				SessionID: "sid-1",
-			}),
-			setupMock: func(oidcs *mocktrust.Repository, sessions *sessionmock.Repository) {
-				_ = oidcs.Create(context.Background(), trustv1.Trust_builder{
-					TenantId: new("tid-1"),
-					Oidc: oidcv1.OIDC_builder{
-						Issuer: new(jwksSrv.URL),
-					}.Build(),
-				}.Build())
-				_ = sessions.StoreSession(context.Background(), session.Session{ID: "sid-1", TenantID: "tid-1"})
-			},
-			errAssert: assert.NoError,
+			name:    "No step-up requested",
+			claims:  map[string]any{"acr": "urn:pwd", "amr": []string{"pwd"}},
+			wantACR: "urn:pwd",
+			wantAMR: []string{"pwd"},
+		},
+		{
+			name:    "Requested acr",
+			stepUp:  session.StepUp{ACRValues: []string{"urn:mfa", "urn:hwk"}},
+			claims:  map[string]any{"acr": "urn:hwk", "amr": []string{"pwd", "hwk"}},
+			wantACR: "urn:hwk",
+			wantAMR: []string{"pwd", "hwk"},
+		},
+		{
+			name:    "Insufficient acr",
+			stepUp:  session.StepUp{ACRValues: []string{"urn:mfa"}},
+			claims:  map[string]any{"acr": "urn:pwd", "amr": []string{"pwd"}},
+			wantErr: serviceerr.ErrInvalidIDTokenACR,
+		},
+		{
+			name:    "Missing acr",
+			stepUp:  session.StepUp{ACRValues: []string{"urn:mfa"}},
+			wantErr: serviceerr.ErrInvalidIDTokenACR,
+		},
+		{
+			name:         "Recent authentication",
+			stepUp:       session.StepUp{MaxAge: new(5 * time.Minute)},
+			claims:       map[string]any{"auth_time": jwt.NewNumericDate(now.Add(-time.Minute))},
+			wantAuthTime: now.Add(-time.Minute).Truncate(time.Second),
+		},
+		{
+			name:    "Authentication too old",
+			stepUp:  session.StepUp{MaxAge: new(5 * time.Minute)},
+			claims:  map[string]any{"auth_time": jwt.NewNumericDate(now.Add(-time.Hour))},
+			wantErr: serviceerr.ErrInvalidIDTokenAuthTime,
+		},
+		{
+			name:    "Missing auth_time with max_age",
+			stepUp:  session.StepUp{MaxAge: new(5 * time.Minute)},
+			wantErr: serviceerr.ErrInvalidIDTokenAuthTime,
+		},
+		{
+			name:         "Forced login",
+			stepUp:       session.StepUp{ForceLogin: true},
+			claims:       map[string]any{"auth_time": jwt.NewNumericDate(now)},
+			wantAuthTime: now.Truncate(time.Second),
+		},
+		{
+			name:    "Forced login reusing an earlier authentication",
+			stepUp:  session.StepUp{ForceLogin: true},
+			claims:  map[string]any{"auth_time": jwt.NewNumericDate(now.Add(-10 * time.Minute))},
+			wantErr: serviceerr.ErrInvalidIDTokenAuthTime,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ctx := t.Context()
-			oidcServer := StartOIDCServer(t, false)
+			oidcServer := StartOIDCServerWithIDTokenClaims(t, false, tt.claims)
 			defer oidcServer.Close()
 
 			auditServer := StartAuditServer(t)
@@ -464,41 +954,69 @@ func TestManager_BCLogout(t *testing.T) {
 			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
 			require.NoError(t, err)
 
-			oidcMock := mocktrust.NewInMemRepository()
-			trust := newTrust(oidcMock)
-			sessionMock := sessionmock.NewInMemRepository()
-
-			rt := localRoundTripper{
-				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					b, err := json.Marshal(oidc.Configuration{
-						JwksURI: jwksSrv.URL,
-						Issuer:  jwksSrv.URL,
-					})
-					if err != nil {
-						panic(err)
-					}
-
-					if _, err := w.Write(b); err != nil {
-						panic(err)
-					}
-				}),
-			}
-
-			tt.setupMock(oidcMock, sessionMock)
+			oidcTrust := trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(false),
+				Oidc: oidcv1.OIDC_builder{
+					Issuer:   new(oidcServer.URL),
+					ClientId: new(testClientID),
+				}.Build(),
+			}.Build()
 
-			m, err := session.NewManager(ctx,
-				tt.cfg,
-				trust,
-				sessionMock,
+			sessions := sessionmock.NewInMemRepository(sessionmock.WithState(session.State{
+				ID:        stateID,
+				TenantID:  tenantID,
+				Nonce:     testNonce,
+				Expiry:    now.Add(time.Hour),
+				CreatedAt: now.Add(-time.Minute),
+				StepUp:    tt.stepUp,
+			}))
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      "http://sm.example.com/sm/callback",
+					CSRFSecretParsed: []byte(testCSRFSecret),
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessions,
 				auditLogger,
-				session.WithTransportCredentials(newTCBuilder(rt)),
+				session.WithAllowHttpScheme(true),
 			)
 			require.NoError(t, err)
 
-			err = m.BCLogout(ctx, tt.jwt)
-			if !tt.errAssert(t, err, fmt.Sprintf("Manager.BCLogout() error = %v", err)) {
+			result, err := m.FinaliseOIDCLogin(t.Context(), stateID, "auth-code")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
 				return
 			}
+			require.NoError(t, err)
+
+			sess, err := sessions.LoadSession(t.Context(), result.SessionID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantACR, sess.ACR)
+			assert.Equal(t, tt.wantAMR, sess.AMR)
+			assert.True(t, tt.wantAuthTime.Equal(sess.AuthTime), "Unexpected auth time %v", sess.AuthTime)
+		})
+	}
+}
+
+func TestMFAType(t *testing.T) {
+	tests := []struct {
+		name string
+		amr  []string
+		want otlpaudit.MfaType
+	}{
+		{name: "No amr", want: otlpaudit.MFATYPE_NONE},
+		{name: "Password only", amr: []string{"pwd"}, want: otlpaudit.MFATYPE_NONE},
+		{name: "One-time password", amr: []string{"pwd", "otp", "mfa"}, want: otlpaudit.MFATYPE_NONE},
+		{name: "Hardware key", amr: []string{"pwd", "hwk", "mfa"}, want: otlpaudit.MFATYPE_WEBAUTHN},
+		{name: "Software key", amr: []string{"swk"}, want: otlpaudit.MFATYPE_WEBAUTHN},
+		{name: "FIDO", amr: []string{"fido"}, want: otlpaudit.MFATYPE_WEBAUTHN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, session.MFAType(tt.amr))
 		})
 	}
 }
@@ -570,6 +1088,118 @@ func TestManager_LogoutEdgeCases(t *testing.T) {
 	}
 }
 
+func TestManager_FCLogout(t *testing.T) {
+	const (
+		issuer      = "https://idp.example.com"
+		otherIssuer = "https://other-idp.example.com"
+		tenantID    = "tenant-id"
+	)
+
+	newOIDCTrust := func(tenantID, issuer string) *trustv1.Trust {
+		return trustv1.Trust_builder{
+			TenantId: new(tenantID),
+			Blocked:  new(false),
+			Oidc: oidcv1.OIDC_builder{
+				Issuer:   new(issuer),
+				ClientId: new(testClientID),
+			}.Build(),
+		}.Build()
+	}
+
+	tests := []struct {
+		name         string
+		issuer       string
+		sid          string
+		wantTenantID string
+		wantErr      error
+		wantDeleted  []string
+		wantRetained []string
+	}{
+		{
+			name:         "Session terminated",
+			issuer:       issuer,
+			sid:          "provider-sid-1",
+			wantTenantID: tenantID,
+			wantDeleted:  []string{"session-1"},
+			wantRetained: []string{"session-2", "session-3"},
+		},
+		{
+			name:         "Unknown sid",
+			issuer:       issuer,
+			sid:          "unknown-sid",
+			wantRetained: []string{"session-1", "session-2", "session-3"},
+		},
+		{
+			name:         "sid of another issuer",
+			issuer:       issuer,
+			sid:          "provider-sid-2",
+			wantRetained: []string{"session-1", "session-2", "session-3"},
+		},
+		{
+			name:         "sid of a tenant not trusting the issuer",
+			issuer:       issuer,
+			sid:          "provider-sid-3",
+			wantRetained: []string{"session-1", "session-2", "session-3"},
+		},
+		{
+			name:         "Untrusted issuer",
+			issuer:       "https://attacker.example.com",
+			sid:          "provider-sid-1",
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3"},
+		},
+		{
+			name:         "Missing sid",
+			issuer:       issuer,
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trusts := mocktrust.NewInMemRepository(
+				mocktrust.WithTrust(newOIDCTrust(tenantID, issuer)),
+				mocktrust.WithTrust(newOIDCTrust("other-tenant-id", otherIssuer)),
+			)
+
+			sessions := sessionmock.NewInMemRepository()
+			for _, sess := range []session.Session{
+				{ID: "session-1", TenantID: tenantID, ProviderID: "provider-sid-1", Issuer: issuer},
+				{ID: "session-2", TenantID: tenantID, ProviderID: "provider-sid-2", Issuer: otherIssuer},
+				{ID: "session-3", TenantID: "other-tenant-id", ProviderID: "provider-sid-3", Issuer: issuer},
+			} {
+				sess.Expiry = time.Now().Add(time.Hour)
+				require.NoError(t, sessions.StoreSession(t.Context(), sess))
+			}
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{CSRFSecretParsed: []byte(testCSRFSecret)},
+				newTrust(trusts),
+				sessions,
+				nil,
+			)
+			require.NoError(t, err)
+
+			tenantID, err := m.FCLogout(t.Context(), tt.issuer, tt.sid)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantTenantID, tenantID)
+			}
+
+			for _, id := range tt.wantDeleted {
+				_, err := sessions.LoadSession(t.Context(), id)
+				assert.ErrorIs(t, err, serviceerr.ErrNotFound, "session %s must be deleted", id)
+			}
+			for _, id := range tt.wantRetained {
+				_, err := sessions.LoadSession(t.Context(), id)
+				assert.NoError(t, err, "session %s must be retained", id)
+			}
+		})
+	}
+}
+
 func TestManager_BCLogout_ErrorCases(t *testing.T) {
 	key, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
@@ -644,17 +1274,18 @@ func TestManager_BCLogout_ErrorCases(t *testing.T) {
 			errAssert: assert.Error,
 		},
 		{
-			name: "Session not found - should succeed",
+			name: "Missing issuer",
 			jwt: newJwt(struct {
 				Events    map[string]struct{} `json:"events"`
 				SessionID string              `json:"sid"`
 			}{
 				Events:    map[string]struct{}{"http://schemas.openid.net/event/backchannel-logout": {}},
-				SessionID: "non-existent-session",
+				SessionID: "sid-1",
 			}),
 			setupMock: func(oidcs *mocktrust.Repository, sessions *sessionmock.Repository) {
+				_ = sessions.StoreSession(context.Background(), session.Session{ID: "sid-1", ProviderID: "sid-1", TenantID: "tid-1"})
 			},
-			errAssert: assert.NoError,
+			errAssert: assert.Error,
 		},
 	}
 
@@ -697,6 +1328,357 @@ func TestManager_BCLogout_ErrorCases(t *testing.T) {
 	}
 }
 
+func TestManager_BCLogout_LogoutToken(t *testing.T) {
+	const (
+		tenantID      = "tenant-id"
+		otherTenantID = "other-tenant-id"
+		subject       = "provider-subject"
+	)
+
+	oidcServer := startRotatingOIDCServer(t, "", "/.well-known/jwks.json")
+	defer oidcServer.Close()
+
+	newSigner := func(t *testing.T, alg jose.SignatureAlgorithm, key any) jose.Signer {
+		t.Helper()
+
+		signer, err := jose.NewSigner(jose.SigningKey{
+			Algorithm: alg,
+			Key:       jose.JSONWebKey{Key: key, KeyID: oidcServer.kid},
+		}, (&jose.SignerOptions{}).WithType("logout+jwt"))
+		require.NoError(t, err)
+
+		return signer
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	newLogoutToken := func(t *testing.T, signer jose.Signer, claims map[string]any) string {
+		t.Helper()
+
+		now := time.Now()
+		logoutClaims := map[string]any{
+			"iss":    oidcServer.URL,
+			"aud":    testClientID,
+			"iat":    jwt.NewNumericDate(now),
+			"exp":    jwt.NewNumericDate(now.Add(2 * time.Minute)),
+			"jti":    "logout-token-id",
+			"sub":    subject,
+			"events": map[string]any{"http://schemas.openid.net/event/backchannel-logout": map[string]any{}},
+		}
+		maps.Copy(logoutClaims, claims)
+		maps.DeleteFunc(logoutClaims, func(_ string, v any) bool { return v == nil })
+
+		token, err := jwt.Signed(signer).Claims(logoutClaims).Serialize()
+		require.NoError(t, err)
+
+		return token
+	}
+
+	newOIDCTrust := func(tenantID, clientID string) *trustv1.Trust {
+		return trustv1.Trust_builder{
+			TenantId: new(tenantID),
+			Blocked:  new(false),
+			Oidc: oidcv1.OIDC_builder{
+				Issuer:   new(oidcServer.URL),
+				ClientId: new(clientID),
+			}.Build(),
+		}.Build()
+	}
+
+	newSession := func(id, tenantID, providerID, subject string) session.Session {
+		return session.Session{
+			ID:              id,
+			TenantID:        tenantID,
+			ProviderID:      providerID,
+			ProviderSubject: subject,
+			Issuer:          oidcServer.URL,
+			Expiry:          time.Now().Add(time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		signer       jose.Signer
+		claims       map[string]any
+		wantErr      error
+		wantDeleted  []string
+		wantRetained []string
+	}{
+		{
+			name:         "Logout by sid",
+			claims:       map[string]any{"sid": "provider-sid-1"},
+			wantDeleted:  []string{"session-1"},
+			wantRetained: []string{"session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Logout by sub terminates all sessions of the subject",
+			wantDeleted:  []string{"session-1", "session-2"},
+			wantRetained: []string{"session-3", "session-4"},
+		},
+		{
+			name:         "Logout by sub terminates sessions of all tenants of the audience",
+			claims:       map[string]any{"aud": []string{testClientID, "other-client-id"}, "azp": testClientID},
+			wantDeleted:  []string{"session-1", "session-2", "session-4"},
+			wantRetained: []string{"session-3"},
+		},
+		{
+			name:         "sid of another tenant",
+			claims:       map[string]any{"sid": "provider-sid-4"},
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Unknown sid",
+			claims:       map[string]any{"sid": "unknown-sid"},
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Symmetric algorithm",
+			signer:       newSigner(t, jose.HS256, []byte("a-secret-of-at-least-256-bits-length")),
+			claims:       map[string]any{"sid": "provider-sid-1"},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Algorithm not advertised by the provider",
+			signer:       newSigner(t, jose.PS256, oidcServer.key),
+			claims:       map[string]any{"sid": "provider-sid-1"},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Invalid signature",
+			signer:       newSigner(t, jose.RS256, otherKey),
+			claims:       map[string]any{"sid": "provider-sid-1"},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Untrusted issuer",
+			claims:       map[string]any{"sid": "provider-sid-1", "iss": "https://untrusted.example.com"},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Missing backchannel-logout event",
+			claims:       map[string]any{"sid": "provider-sid-1", "events": map[string]any{"http://invalid-event": map[string]any{}}},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Missing sid and sub",
+			claims:       map[string]any{"sub": nil},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "sid of another subject",
+			claims:       map[string]any{"sid": "provider-sid-3"},
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Invalid issuer",
+			claims:       map[string]any{"sid": "provider-sid-1", "iss": "https://attacker.example.com"},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Invalid audience",
+			claims:       map[string]any{"aud": "another-client-id"},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Missing iat",
+			claims:       map[string]any{"iat": nil},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Issued too long ago",
+			claims:       map[string]any{"iat": jwt.NewNumericDate(time.Now().Add(-time.Hour)), "exp": nil},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Issued in the future",
+			claims:       map[string]any{"iat": jwt.NewNumericDate(time.Now().Add(time.Hour))},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Missing jti",
+			claims:       map[string]any{"jti": nil},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+		{
+			name:         "Nonce present",
+			claims:       map[string]any{"nonce": testNonce},
+			wantErr:      serviceerr.ErrInvalidRequest,
+			wantRetained: []string{"session-1", "session-2", "session-3", "session-4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditServer := StartAuditServer(t)
+			defer auditServer.Close()
+
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			trusts := mocktrust.NewInMemRepository(
+				mocktrust.WithTrust(newOIDCTrust(tenantID, testClientID)),
+				mocktrust.WithTrust(newOIDCTrust(otherTenantID, "other-client-id")),
+			)
+
+			sessions := sessionmock.NewInMemRepository()
+			for _, sess := range []session.Session{
+				newSession("session-1", tenantID, "provider-sid-1", subject),
+				newSession("session-2", tenantID, "provider-sid-2", subject),
+				newSession("session-3", tenantID, "provider-sid-3", "another-subject"),
+				newSession("session-4", otherTenantID, "provider-sid-4", subject),
+			} {
+				require.NoError(t, sessions.StoreSession(t.Context(), sess))
+			}
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{CSRFSecretParsed: []byte(testCSRFSecret)},
+				newTrust(trusts),
+				sessions,
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			signer := tt.signer
+			if signer == nil {
+				signer = newSigner(t, jose.RS256, oidcServer.key)
+			}
+
+			err = m.BCLogout(t.Context(), newLogoutToken(t, signer, tt.claims))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			for _, id := range tt.wantDeleted {
+				_, err := sessions.LoadSession(t.Context(), id)
+				assert.ErrorIs(t, err, serviceerr.ErrNotFound, "session %s must be deleted", id)
+			}
+			for _, id := range tt.wantRetained {
+				_, err := sessions.LoadSession(t.Context(), id)
+				assert.NoError(t, err, "session %s must be retained", id)
+			}
+		})
+	}
+
+	t.Run("Replayed logout token", func(t *testing.T) {
+		auditServer := StartAuditServer(t)
+		defer auditServer.Close()
+
+		auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+		require.NoError(t, err)
+
+		trusts := mocktrust.NewInMemRepository(mocktrust.WithTrust(newOIDCTrust(tenantID, testClientID)))
+
+		sessions := sessionmock.NewInMemRepository()
+		require.NoError(t, sessions.StoreSession(t.Context(), newSession("session-1", tenantID, "provider-sid-1", subject)))
+
+		m, err := session.NewManager(t.Context(),
+			&config.SessionManager{CSRFSecretParsed: []byte(testCSRFSecret)},
+			newTrust(trusts),
+			sessions,
+			auditLogger,
+			session.WithAllowHttpScheme(true),
+		)
+		require.NoError(t, err)
+
+		token := newLogoutToken(t, newSigner(t, jose.RS256, oidcServer.key), nil)
+		require.NoError(t, m.BCLogout(t.Context(), token))
+
+		// A new session of the subject must not be terminated by the same token again
+		require.NoError(t, sessions.StoreSession(t.Context(), newSession("session-2", tenantID, "provider-sid-2", subject)))
+		assert.ErrorIs(t, m.BCLogout(t.Context(), token), serviceerr.ErrInvalidRequest)
+
+		_, err = sessions.LoadSession(t.Context(), "session-2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failed logout is retried", func(t *testing.T) {
+		auditServer := StartAuditServer(t)
+		defer auditServer.Close()
+
+		auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+		require.NoError(t, err)
+
+		trusts := mocktrust.NewInMemRepository(mocktrust.WithTrust(newOIDCTrust(tenantID, testClientID)))
+
+		sessions := &failingDeleteRepository{Repository: sessionmock.NewInMemRepository(), fail: true}
+		require.NoError(t, sessions.StoreSession(t.Context(), newSession("session-1", tenantID, "provider-sid-1", subject)))
+
+		m, err := session.NewManager(t.Context(),
+			&config.SessionManager{CSRFSecretParsed: []byte(testCSRFSecret)},
+			newTrust(trusts),
+			sessions,
+			auditLogger,
+			session.WithAllowHttpScheme(true),
+		)
+		require.NoError(t, err)
+
+		token := newLogoutToken(t, newSigner(t, jose.RS256, oidcServer.key), nil)
+		err = m.BCLogout(t.Context(), token)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, serviceerr.ErrInvalidRequest, "failures must not be reported as invalid logout tokens")
+
+		sessions.fail = false
+		require.NoError(t, m.BCLogout(t.Context(), token), "the provider must be able to retry the logout")
+
+		_, err = sessions.LoadSession(t.Context(), "session-1")
+		assert.ErrorIs(t, err, serviceerr.ErrNotFound)
+	})
+
+	t.Run("Failed session lookup", func(t *testing.T) {
+		auditServer := StartAuditServer(t)
+		defer auditServer.Close()
+
+		auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+		require.NoError(t, err)
+
+		trusts := mocktrust.NewInMemRepository(mocktrust.WithTrust(newOIDCTrust(tenantID, testClientID)))
+		sessions := sessionmock.NewInMemRepository(sessionmock.WithLoadSessionError(errors.New("valkey unavailable")))
+
+		m, err := session.NewManager(t.Context(),
+			&config.SessionManager{CSRFSecretParsed: []byte(testCSRFSecret)},
+			newTrust(trusts),
+			sessions,
+			auditLogger,
+			session.WithAllowHttpScheme(true),
+		)
+		require.NoError(t, err)
+
+		err = m.BCLogout(t.Context(), newLogoutToken(t, newSigner(t, jose.RS256, oidcServer.key), nil))
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, serviceerr.ErrInvalidRequest, "failures must not be reported as invalid logout tokens")
+	})
+}
+
+// failingDeleteRepository fails to delete sessions while fail is set.
+type failingDeleteRepository struct {
+	*sessionmock.Repository
+
+	fail bool
+}
+
+func (r *failingDeleteRepository) DeleteSession(ctx context.Context, s session.Session) error {
+	if r.fail {
+		return errors.New("valkey unavailable")
+	}
+
+	return r.Repository.DeleteSession(ctx, s)
+}
+
 func TestManager_NewManager_Error(t *testing.T) {
 	ctx := t.Context()
 	auditServer := StartAuditServer(t)
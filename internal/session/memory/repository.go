@@ -0,0 +1,416 @@
+// Package sessionmemory provides a session.Repository which keeps the
+// sessions in the memory of the process. Objects expire like their Valkey
+// counterparts and are stored JSON encoded so that callers never share
+// mutable data with the repository.
+package sessionmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+var (
+	ErrStoreState         = errors.New("storing state into memory")
+	ErrStoreSession       = errors.New("storing session into memory")
+	ErrGetState           = errors.New("getting state from memory")
+	ErrGetSession         = errors.New("getting session from memory")
+	ErrGetSessions        = errors.New("getting sessions from memory")
+	ErrGetAccessToken     = errors.New("getting access token from memory")
+	ErrStoreLogoutTokenID = errors.New("storing logout token ID into memory")
+	ErrAcquireRefreshLock = errors.New("acquiring refresh lock")
+)
+
+// expiring is a value which is removed at its expiry.
+type expiring[T any] struct {
+	value  T
+	expiry time.Time
+}
+
+func (e expiring[T]) expired(now time.Time) bool {
+	return !now.Before(e.expiry)
+}
+
+// storedSession holds the encoded session along with the fields which are
+// looked up without decoding it.
+type storedSession struct {
+	data         []byte
+	tenantID     string
+	issuer       string
+	subject      string
+	accessToken  string
+	refreshToken string
+}
+
+type Repository struct {
+	mu sync.Mutex
+
+	states           map[string]expiring[[]byte]
+	sessions         map[string]expiring[storedSession]
+	providerSessions map[string]expiring[string]
+	active           map[string]expiring[struct{}]
+	logoutTokenIDs   map[string]expiring[struct{}]
+	refreshLocks     map[string]expiring[string]
+}
+
+var _ = session.Repository(&Repository{})
+
+func NewRepository() *Repository {
+	return &Repository{
+		states:           make(map[string]expiring[[]byte]),
+		sessions:         make(map[string]expiring[storedSession]),
+		providerSessions: make(map[string]expiring[string]),
+		active:           make(map[string]expiring[struct{}]),
+		logoutTokenIDs:   make(map[string]expiring[struct{}]),
+		refreshLocks:     make(map[string]expiring[string]),
+	}
+}
+
+func (r *Repository) LoadState(_ context.Context, stateID string) (session.State, error) {
+	r.mu.Lock()
+	data, ok := get(r.states, stateID)
+	r.mu.Unlock()
+	if !ok {
+		return session.State{}, errors.Join(ErrGetState, serviceerr.ErrNotFound)
+	}
+
+	var state session.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return session.State{}, errors.Join(ErrGetState, fmt.Errorf("unmarshaling json: %w", err))
+	}
+
+	return state, nil
+}
+
+func (r *Repository) StoreState(_ context.Context, state session.State) error {
+	if err := validateExpiry(state.Expiry); err != nil {
+		return errors.Join(ErrStoreState, err)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Join(ErrStoreState, fmt.Errorf("marshaling json: %w", err))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.states[state.ID] = expiring[[]byte]{value: data, expiry: state.Expiry}
+
+	return nil
+}
+
+func (r *Repository) DeleteState(_ context.Context, stateID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.states, stateID)
+
+	return nil
+}
+
+func (r *Repository) ListSessions(_ context.Context) ([]session.Session, error) {
+	return r.listSessions(func(storedSession) bool { return true })
+}
+
+// ListSessionsByTenant returns the sessions of the tenant.
+func (r *Repository) ListSessionsByTenant(_ context.Context, tenantID string) ([]session.Session, error) {
+	return r.listSessions(func(s storedSession) bool {
+		return s.tenantID == tenantID
+	})
+}
+
+// ListSessionsBySubject returns the sessions of the subject defined by the
+// issuer. Like in the Valkey store, sessions without issuer or subject are
+// not indexed by subject.
+func (r *Repository) ListSessionsBySubject(_ context.Context, issuer, subject string) ([]session.Session, error) {
+	return r.listSessions(func(s storedSession) bool {
+		return s.issuer != "" && s.subject != "" && s.issuer == issuer && s.subject == subject
+	})
+}
+
+func (r *Repository) listSessions(match func(storedSession) bool) ([]session.Session, error) {
+	r.mu.Lock()
+	now := time.Now()
+	var matching [][]byte
+	for _, e := range r.sessions {
+		if !e.expired(now) && match(e.value) {
+			matching = append(matching, e.value.data)
+		}
+	}
+	r.mu.Unlock()
+
+	sessions := make([]session.Session, 0, len(matching))
+	for _, data := range matching {
+		var s session.Session
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, errors.Join(ErrGetSessions, fmt.Errorf("unmarshaling json: %w", err))
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+}
+
+func (r *Repository) LoadSession(_ context.Context, sessionID string) (session.Session, error) {
+	r.mu.Lock()
+	stored, ok := get(r.sessions, sessionID)
+	r.mu.Unlock()
+	if !ok {
+		return session.Session{}, errors.Join(ErrGetSession, serviceerr.ErrNotFound)
+	}
+
+	var s session.Session
+	if err := json.Unmarshal(stored.data, &s); err != nil {
+		return session.Session{}, errors.Join(ErrGetSession, fmt.Errorf("unmarshaling json: %w", err))
+	}
+
+	return s, nil
+}
+
+// LoadSessionByProviderID returns the session last stored with the provider ID.
+func (r *Repository) LoadSessionByProviderID(ctx context.Context, providerID string) (session.Session, error) {
+	r.mu.Lock()
+	sessionID, ok := get(r.providerSessions, providerID)
+	r.mu.Unlock()
+	if !ok {
+		return session.Session{}, fmt.Errorf("getting session id by provider id: %w", serviceerr.ErrNotFound)
+	}
+
+	sess, err := r.LoadSession(ctx, sessionID)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("getting session by id: %w", err)
+	}
+
+	return sess, nil
+}
+
+func (r *Repository) GetAccessTokenForSession(_ context.Context, sessionID string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := get(r.sessions, sessionID)
+	if !ok {
+		return "", errors.Join(ErrGetAccessToken, serviceerr.ErrNotFound)
+	}
+
+	return stored.accessToken, nil
+}
+
+// StoreSession stores the session and indexes it by its provider ID.
+func (r *Repository) StoreSession(_ context.Context, s session.Session) error {
+	if err := validateExpiry(s.Expiry); err != nil {
+		return errors.Join(ErrStoreSession, err)
+	}
+
+	stored, err := newStoredSession(s)
+	if err != nil {
+		return errors.Join(ErrStoreSession, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[s.ID] = expiring[storedSession]{value: stored, expiry: s.Expiry}
+	r.providerSessions[s.ProviderID] = expiring[string]{value: s.ID, expiry: s.Expiry}
+
+	return nil
+}
+
+func (r *Repository) StoreRefreshedSession(_ context.Context, s session.Session, previousRefreshToken string) error {
+	if err := validateExpiry(s.Expiry); err != nil {
+		return errors.Join(ErrStoreSession, err)
+	}
+
+	stored, err := newStoredSession(s)
+	if err != nil {
+		return errors.Join(ErrStoreSession, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := get(r.sessions, s.ID)
+	if !ok || current.refreshToken != previousRefreshToken {
+		return serviceerr.ErrConflict
+	}
+
+	// The index attributes are kept as they are only set by StoreSession
+	stored.tenantID, stored.issuer, stored.subject = current.tenantID, current.issuer, current.subject
+	r.sessions[s.ID] = expiring[storedSession]{value: stored, expiry: s.Expiry}
+
+	return nil
+}
+
+func (r *Repository) DeleteSession(_ context.Context, s session.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, s.ID)
+	delete(r.providerSessions, s.ProviderID)
+
+	return nil
+}
+
+func (r *Repository) IsActive(_ context.Context, sessionID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := get(r.active, sessionID)
+
+	return ok, nil
+}
+
+func (r *Repository) BumpActive(_ context.Context, sessionID string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("storing an active object: invalid timeout %s", timeout)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.active[sessionID] = expiring[struct{}]{expiry: time.Now().Add(timeout)}
+
+	return nil
+}
+
+func (r *Repository) StoreLogoutTokenID(_ context.Context, issuer, tokenID string, expiry time.Time) error {
+	if err := validateExpiry(expiry); err != nil {
+		return errors.Join(ErrStoreLogoutTokenID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The issuer is part of the ID as token IDs are only unique per issuer
+	id := issuer + " " + tokenID
+	if _, ok := get(r.logoutTokenIDs, id); ok {
+		return serviceerr.ErrConflict
+	}
+	r.logoutTokenIDs[id] = expiring[struct{}]{expiry: expiry}
+
+	return nil
+}
+
+func (r *Repository) DeleteLogoutTokenID(_ context.Context, issuer, tokenID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.logoutTokenIDs, issuer+" "+tokenID)
+
+	return nil
+}
+
+func (r *Repository) AcquireRefreshLock(_ context.Context, sessionID, owner string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.Join(ErrAcquireRefreshLock, fmt.Errorf("invalid TTL %s", ttl))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := get(r.refreshLocks, sessionID); ok {
+		return serviceerr.ErrConflict
+	}
+	r.refreshLocks[sessionID] = expiring[string]{value: owner, expiry: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (r *Repository) ReleaseRefreshLock(_ context.Context, sessionID, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The lock may have expired and been acquired by another owner meanwhile
+	if current, ok := get(r.refreshLocks, sessionID); ok && current == owner {
+		delete(r.refreshLocks, sessionID)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes the expired objects and returns their number.
+func (r *Repository) DeleteExpired() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	return deleteExpired(r.states, now) +
+		deleteExpired(r.sessions, now) +
+		deleteExpired(r.providerSessions, now) +
+		deleteExpired(r.active, now) +
+		deleteExpired(r.logoutTokenIDs, now) +
+		deleteExpired(r.refreshLocks, now)
+}
+
+// Sweep removes the expired objects every interval until the context is done.
+// Expired objects are never returned, sweeping only frees their memory.
+func (r *Repository) Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.DeleteExpired()
+		}
+	}
+}
+
+func newStoredSession(s session.Session) (storedSession, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return storedSession{}, fmt.Errorf("marshaling json: %w", err)
+	}
+
+	return storedSession{
+		data:         data,
+		tenantID:     s.TenantID,
+		issuer:       s.Issuer,
+		subject:      s.ProviderSubject,
+		accessToken:  s.AccessToken,
+		refreshToken: s.RefreshToken,
+	}, nil
+}
+
+// validateExpiry rejects expiry times which Valkey rejects as TTL.
+func validateExpiry(expiry time.Time) error {
+	if time.Until(expiry) < time.Millisecond {
+		return fmt.Errorf("expired at %s", expiry)
+	}
+
+	return nil
+}
+
+// get returns the value of the key if it has not expired. The caller must
+// hold the lock.
+func get[T any](m map[string]expiring[T], key string) (T, bool) {
+	e, ok := m[key]
+	if !ok || e.expired(time.Now()) {
+		var zero T
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+func deleteExpired[T any](m map[string]expiring[T], now time.Time) int {
+	deleted := 0
+	for key, e := range m {
+		if e.expired(now) {
+			delete(m, key)
+			deleted++
+		}
+	}
+
+	return deleted
+}
@@ -0,0 +1,71 @@
+package sessionmemory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmemory "github.com/openkcm/session-manager/internal/session/memory"
+	"github.com/openkcm/session-manager/internal/session/sessiontest"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+	sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+		return sessionmemory.NewRepository()
+	})
+}
+
+func TestRepository_StoreSession_DoesNotShareData(t *testing.T) {
+	ctx := t.Context()
+	r := sessionmemory.NewRepository()
+
+	sess := session.Session{
+		ID:          "session-id",
+		AuthContext: map[string]string{"key": "value"},
+		Expiry:      time.Now().Add(time.Hour),
+	}
+	require.NoError(t, r.StoreSession(ctx, sess))
+
+	sess.AuthContext["key"] = "changed"
+
+	loaded, err := r.LoadSession(ctx, "session-id")
+	require.NoError(t, err)
+	assert.Equal(t, "value", loaded.AuthContext["key"])
+}
+
+func TestRepository_RejectsExpired(t *testing.T) {
+	ctx := t.Context()
+	r := sessionmemory.NewRepository()
+
+	expired := time.Now().Add(-time.Second)
+	require.Error(t, r.StoreState(ctx, session.State{ID: "state-id", Expiry: expired}))
+	require.Error(t, r.StoreSession(ctx, session.Session{ID: "session-id", Expiry: expired}))
+	require.Error(t, r.StoreLogoutTokenID(ctx, "issuer", "jti", expired))
+	require.Error(t, r.BumpActive(ctx, "session-id", 0))
+	require.Error(t, r.AcquireRefreshLock(ctx, "session-id", "owner", 0))
+}
+
+func TestRepository_DeleteExpired(t *testing.T) {
+	ctx := t.Context()
+	r := sessionmemory.NewRepository()
+
+	require.NoError(t, r.StoreState(ctx, session.State{ID: "state-id", Expiry: time.Now().Add(50 * time.Millisecond)}))
+	require.NoError(t, r.StoreSession(ctx, session.Session{ID: "session-id", ProviderID: "provider-id", Expiry: time.Now().Add(50 * time.Millisecond)}))
+	require.NoError(t, r.BumpActive(ctx, "session-id", time.Hour))
+
+	assert.Zero(t, r.DeleteExpired())
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err := r.LoadSession(ctx, "session-id")
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+	assert.Equal(t, 3, r.DeleteExpired(), "state, session and provider session must be deleted")
+
+	active, err := r.IsActive(ctx, "session-id")
+	require.NoError(t, err)
+	assert.True(t, active, "unexpired objects must be kept")
+}
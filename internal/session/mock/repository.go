@@ -2,6 +2,7 @@ package sessionmock
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/openkcm/session-manager/internal/session"
@@ -10,11 +11,21 @@ import (
 
 type RepositoryOption func(*Repository)
 
+type refreshLock struct {
+	owner  string
+	expiry time.Time
+}
+
+// Repository is an in-memory session.Repository. It is safe for concurrent use.
 type Repository struct {
+	mu sync.Mutex
+
 	states          map[string]session.State
 	sessions        map[string]session.Session
 	providerSession map[string]session.Session
 	active          map[string]time.Time
+	logoutTokenIDs  map[string]time.Time
+	refreshLocks    map[string]refreshLock
 
 	loadStateErr, storeStateErr, deleteStateErr       error
 	loadSessionErr, storeSessionErr, deleteSessionErr error
@@ -27,6 +38,9 @@ func WithState(state session.State) RepositoryOption {
 func WithSession(sess session.Session) RepositoryOption {
 	return func(r *Repository) { r.sessions[sess.ID] = sess }
 }
+func WithRefreshLock(sessionID, owner string, expiry time.Time) RepositoryOption {
+	return func(r *Repository) { r.refreshLocks[sessionID] = refreshLock{owner: owner, expiry: expiry} }
+}
 func WithLoadStateError(err error) RepositoryOption {
 	return func(r *Repository) { r.loadStateErr = err }
 }
@@ -55,6 +69,9 @@ func WithBumpActiveError(err error) RepositoryOption {
 var _ = session.Repository(&Repository{})
 
 func (r *Repository) ListSessions(ctx context.Context) ([]session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	sessions := make([]session.Session, 0, len(r.sessions))
 	for _, s := range r.sessions {
 		sessions = append(sessions, s)
@@ -68,6 +85,8 @@ func NewInMemRepository(opts ...RepositoryOption) *Repository {
 		sessions:        make(map[string]session.Session),
 		providerSession: make(map[string]session.Session),
 		active:          make(map[string]time.Time),
+		logoutTokenIDs:  make(map[string]time.Time),
+		refreshLocks:    make(map[string]refreshLock),
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -78,6 +97,9 @@ func NewInMemRepository(opts ...RepositoryOption) *Repository {
 }
 
 func (r *Repository) LoadState(_ context.Context, stateID string) (session.State, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.loadStateErr != nil {
 		return session.State{}, r.loadStateErr
 	}
@@ -88,6 +110,9 @@ func (r *Repository) LoadState(_ context.Context, stateID string) (session.State
 }
 
 func (r *Repository) StoreState(_ context.Context, state session.State) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.storeStateErr != nil {
 		return r.storeStateErr
 	}
@@ -99,6 +124,9 @@ func (r *Repository) StoreState(_ context.Context, state session.State) error {
 }
 
 func (r *Repository) DeleteState(_ context.Context, stateID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.deleteStateErr != nil {
 		return r.deleteStateErr
 	}
@@ -110,6 +138,9 @@ func (r *Repository) DeleteState(_ context.Context, stateID string) error {
 }
 
 func (r *Repository) LoadSession(_ context.Context, sessionID string) (session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.loadSessionErr != nil {
 		return session.Session{}, r.loadSessionErr
 	}
@@ -120,6 +151,9 @@ func (r *Repository) LoadSession(_ context.Context, sessionID string) (session.S
 }
 
 func (r *Repository) LoadSessionByProviderID(_ context.Context, providerID string) (session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.loadSessionErr != nil {
 		return session.Session{}, r.loadSessionErr
 	}
@@ -129,16 +163,72 @@ func (r *Repository) LoadSessionByProviderID(_ context.Context, providerID strin
 	return session.Session{}, serviceerr.ErrNotFound
 }
 
+func (r *Repository) ListSessionsByTenant(_ context.Context, tenantID string) ([]session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loadSessionErr != nil {
+		return nil, r.loadSessionErr
+	}
+	var sessions []session.Session
+	for _, s := range r.sessions {
+		if s.TenantID == tenantID {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *Repository) ListSessionsBySubject(_ context.Context, issuer, subject string) ([]session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loadSessionErr != nil {
+		return nil, r.loadSessionErr
+	}
+	var sessions []session.Session
+	for _, s := range r.sessions {
+		if s.Issuer == issuer && s.ProviderSubject == subject {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
 func (r *Repository) StoreSession(_ context.Context, sess session.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.storeSessionErr != nil {
 		return r.storeSessionErr
 	}
+	r.storeSession(sess)
+	return nil
+}
+
+func (r *Repository) storeSession(sess session.Session) {
 	r.sessions[sess.ID] = sess
 	r.providerSession[sess.ProviderID] = sess
+}
+
+func (r *Repository) StoreRefreshedSession(_ context.Context, sess session.Session, previousRefreshToken string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.storeSessionErr != nil {
+		return r.storeSessionErr
+	}
+	if stored, ok := r.sessions[sess.ID]; !ok || stored.RefreshToken != previousRefreshToken {
+		return serviceerr.ErrConflict
+	}
+	r.storeSession(sess)
 	return nil
 }
 
 func (r *Repository) DeleteSession(_ context.Context, sess session.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.deleteSessionErr != nil {
 		return r.deleteSessionErr
 	}
@@ -151,7 +241,23 @@ func (r *Repository) DeleteSession(_ context.Context, sess session.Session) erro
 	return nil
 }
 
+func (r *Repository) GetAccessTokenForSession(_ context.Context, sessionID string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loadSessionErr != nil {
+		return "", r.loadSessionErr
+	}
+	if s, ok := r.sessions[sessionID]; ok {
+		return s.AccessToken, nil
+	}
+	return "", serviceerr.ErrNotFound
+}
+
 func (r *Repository) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.isActiveErr != nil {
 		return false, r.isActiveErr
 	}
@@ -164,9 +270,53 @@ func (r *Repository) IsActive(ctx context.Context, sessionID string) (bool, erro
 }
 
 func (r *Repository) BumpActive(ctx context.Context, sessionID string, timeout time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.bumpActiveErr != nil {
 		return r.bumpActiveErr
 	}
 	r.active[sessionID] = time.Now().Add(timeout)
 	return nil
 }
+
+func (r *Repository) StoreLogoutTokenID(_ context.Context, issuer, tokenID string, expiry time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := issuer + " " + tokenID
+	if exp, ok := r.logoutTokenIDs[key]; ok && exp.After(time.Now()) {
+		return serviceerr.ErrConflict
+	}
+	r.logoutTokenIDs[key] = expiry
+	return nil
+}
+
+func (r *Repository) DeleteLogoutTokenID(_ context.Context, issuer, tokenID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.logoutTokenIDs, issuer+" "+tokenID)
+	return nil
+}
+
+func (r *Repository) AcquireRefreshLock(_ context.Context, sessionID, owner string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lock, ok := r.refreshLocks[sessionID]; ok && lock.expiry.After(time.Now()) {
+		return serviceerr.ErrConflict
+	}
+	r.refreshLocks[sessionID] = refreshLock{owner: owner, expiry: time.Now().Add(ttl)}
+	return nil
+}
+
+func (r *Repository) ReleaseRefreshLock(_ context.Context, sessionID, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lock, ok := r.refreshLocks[sessionID]; ok && lock.owner == owner {
+		delete(r.refreshLocks, sessionID)
+	}
+	return nil
+}
@@ -9,25 +9,41 @@ type State struct {
 	ID             string    // State ID to align the auth request with the callback
 	TenantID       string    // Tenant ID for which the login is done
 	PKCEVerifier   string    // PKCE verifier to validate the PKCE challenge
+	Nonce          string    // Nonce to bind the ID token to the auth request
 	RequestURI     string    // Request URI for the eventual redirect
 	ErrorURI       string    // Error URI for redirecting to UI error page on failure (optional)
 	Expiry         time.Time // Expiry time of the login process
 	LoginCSRFToken string    // CSRF token to prevent CSRF attacks
+	CreatedAt      time.Time // Creation time of the login process
+	StepUp         StepUp    // Additional authentication requirements (optional)
+}
+
+// StepUp describes authentication requirements of a login which go beyond an
+// existing authentication of the user at the OIDC provider.
+type StepUp struct {
+	ACRValues  []string       // Requested authentication context class references in order of preference
+	MaxAge     *time.Duration // Maximum time since the user last actively authenticated (optional)
+	ForceLogin bool           // Whether the user must actively re-authenticate (`prompt=login`)
 }
 
 // Session represents a user session in our system.
 type Session struct {
-	ID                string            // Session ID in our system
-	TenantID          string            // Tenant ID for which the session is created
-	ProviderID        string            // Provider session ID defined by the OIDC provider (`sid` claim)
-	CSRFToken         string            // CSRF token to prevent CSRF attacks
-	Issuer            string            // Issuer of the OIDC tokens
-	Claims            Claims            // Claims from the ID token
-	AccessToken       string            // Access token from the identity provider
-	RefreshToken      string            // Refresh token from the identity provider
-	Expiry            time.Time         // Expiry time of the session
-	AccessTokenExpiry time.Time         // Expiry time of the Access Token
-	AuthContext       map[string]string // Additional authentication context
+	ID                 string            // Session ID in our system
+	TenantID           string            // Tenant ID for which the session is created
+	ProviderID         string            // Provider session ID defined by the OIDC provider (`sid` claim)
+	ProviderSubject    string            // Subject defined by the OIDC provider (`sub` claim)
+	CSRFToken          string            // CSRF token to prevent CSRF attacks
+	Issuer             string            // Issuer of the OIDC tokens
+	Claims             Claims            // Claims from the ID token
+	AccessToken        string            // Access token from the identity provider
+	RefreshToken       string            // Refresh token from the identity provider
+	Expiry             time.Time         // Expiry time of the session
+	AccessTokenExpiry  time.Time         // Expiry time of the Access Token
+	RefreshTokenExpiry time.Time         // Expiry time of the Refresh Token (optional)
+	AuthContext        map[string]string // Additional authentication context
+	ACR                string            // Authentication context class reference achieved (`acr` claim)
+	AMR                []string          // Authentication methods used (`amr` claim)
+	AuthTime           time.Time         // Time of the user authentication (`auth_time` claim, optional)
 }
 
 type Claims struct {
@@ -56,4 +72,18 @@ type tokenResponse struct {
 	IDToken      string `json:"id_token"`
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int    `json:"expires_in"`
+
+	// RefreshExpiresIn is not standardised but returned by some providers, e.g. Keycloak
+	RefreshExpiresIn int `json:"refresh_expires_in"`
+
+	// IssuedTokenType is returned by token exchanges as described in
+	// https://www.rfc-editor.org/rfc/rfc8693.html#section-2.2.1
+	IssuedTokenType string `json:"issued_token_type"`
+}
+
+// parResponse represents the response from the pushed authorization request endpoint
+// described in https://datatracker.ietf.org/doc/html/rfc9126#section-2.2
+type parResponse struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int    `json:"expires_in"`
 }
@@ -4,6 +4,10 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
 
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/openkcm/common-sdk/pkg/oidc"
@@ -11,7 +15,20 @@ import (
 	slogctx "github.com/veqryn/slog-context"
 )
 
-func (m *Manager) getOpenIDConfig(ctx context.Context, issuerURL string) (*oidc.Configuration, error) {
+const wellKnownOpenIDConfigPath = "/.well-known/openid-configuration"
+
+// openIDConfiguration is the provider metadata used by the session manager.
+// It extends the common OpenID configuration with the metadata which is not
+// decoded by the common SDK.
+type openIDConfiguration struct {
+	oidc.Configuration
+
+	// From https://datatracker.ietf.org/doc/html/rfc9126#section-5
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint,omitempty"`
+	RequirePushedAuthorizationRequests bool   `json:"require_pushed_authorization_requests,omitempty"`
+}
+
+func (m *Manager) getOpenIDConfig(ctx context.Context, issuerURL string) (*openIDConfiguration, error) {
 	// first check the cache for a recent WKOC configuration for this issuer
 	hashedSuffix := sha256.Sum256([]byte(issuerURL))
 	cacheKey := base64.RawURLEncoding.EncodeToString(hashedSuffix[:])
@@ -19,8 +36,8 @@ func (m *Manager) getOpenIDConfig(ctx context.Context, issuerURL string) (*oidc.
 		return item.Value(), nil
 	}
 
-	// otherwise, fetch the configuration
-	provider, err := oidc.NewProvider(issuerURL, []string{},
+	// otherwise, fetch the configuration. The provider is only used to validate the issuer URL.
+	_, err := oidc.NewProvider(issuerURL, []string{},
 		oidc.WithAllowHttpScheme(m.allowHttpScheme),
 	)
 	if err != nil {
@@ -28,7 +45,7 @@ func (m *Manager) getOpenIDConfig(ctx context.Context, issuerURL string) (*oidc.
 			"issuerURL", issuerURL, "error", err)
 		return nil, err
 	}
-	cfg, err := provider.GetConfiguration(ctx)
+	cfg, err := fetchOpenIDConfig(ctx, issuerURL)
 	if err != nil {
 		slogctx.Error(ctx, "Could not get OIDC provider configuration",
 			"issuerURL", issuerURL, "error", err)
@@ -40,3 +57,33 @@ func (m *Manager) getOpenIDConfig(ctx context.Context, issuerURL string) (*oidc.
 
 	return cfg, nil
 }
+
+func fetchOpenIDConfig(ctx context.Context, issuerURL string) (*openIDConfiguration, error) {
+	u, err := url.JoinPath(issuerURL, wellKnownOpenIDConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("building well-known configuration url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating a new HTTP request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing an http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider responded with status: %d", resp.StatusCode)
+	}
+
+	var cfg openIDConfiguration
+	err = json.NewDecoder(resp.Body).Decode(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("decoding openid configuration: %w", err)
+	}
+
+	return &cfg, nil
+}
@@ -1,6 +1,10 @@
 package session
 
-import "github.com/openkcm/session-manager/internal/credentials"
+import (
+	"time"
+
+	"github.com/openkcm/session-manager/internal/credentials"
+)
 
 type ManagerOption func(*Manager)
 
@@ -15,3 +19,11 @@ func WithTransportCredentials(b credentials.Builder) ManagerOption {
 		m.newCreds = b
 	}
 }
+
+// WithJWKSMinRefreshInterval sets how often a provider key set may be refreshed
+// at most when an ID token is signed with an unknown key.
+func WithJWKSMinRefreshInterval(d time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.jwksMinRefreshInterval = d
+	}
+}
@@ -0,0 +1,34 @@
+package sessionpostgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+
+	"github.com/openkcm/session-manager/internal/session/postgres/migrations"
+)
+
+// VersionTable is the goose version table of the session store migrations. It
+// differs from the default table used by the trust migrations as both share
+// the database.
+const VersionTable = "session_goose_db_version"
+
+// Migrate applies the migrations of the session store tables. Go migrations
+// registered globally belong to the trust migrations and are ignored.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS,
+		goose.WithTableName(VersionTable),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		return fmt.Errorf("creating migration provider: %w", err)
+	}
+
+	if _, err := provider.Up(ctx); err != nil {
+		return fmt.Errorf("applying migrations: %w", err)
+	}
+
+	return nil
+}
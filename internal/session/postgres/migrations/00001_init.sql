@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE session_state (
+    id TEXT PRIMARY KEY,
+    state JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX session_state_expires_at_idx ON session_state (expires_at);
+
+CREATE TABLE session (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    provider_id TEXT NOT NULL,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    session JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX session_provider_id_idx ON session (provider_id);
+CREATE INDEX session_tenant_id_idx ON session (tenant_id);
+CREATE INDEX session_issuer_subject_idx ON session (issuer, subject);
+CREATE INDEX session_expires_at_idx ON session (expires_at);
+
+CREATE TABLE session_active (
+    session_id TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX session_active_expires_at_idx ON session_active (expires_at);
+
+-- Token IDs are only unique per issuer
+CREATE TABLE session_logout_token (
+    issuer TEXT NOT NULL,
+    token_id TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (issuer, token_id)
+);
+
+CREATE INDEX session_logout_token_expires_at_idx ON session_logout_token (expires_at);
+
+CREATE TABLE session_refresh_lock (
+    session_id TEXT PRIMARY KEY,
+    owner TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX session_refresh_lock_expires_at_idx ON session_refresh_lock (expires_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE session_refresh_lock;
+DROP TABLE session_logout_token;
+DROP TABLE session_active;
+DROP TABLE session;
+DROP TABLE session_state;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+// Package migrations holds the migrations of the tables of the PostgreSQL
+// session store.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
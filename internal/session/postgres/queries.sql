@@ -0,0 +1,161 @@
+-- name: GetState :one
+SELECT state
+FROM session_state
+WHERE id = sqlc.arg(id)
+  AND expires_at > now();
+
+-- name: UpsertState :exec
+INSERT INTO session_state (id, state, expires_at)
+VALUES (sqlc.arg(id), sqlc.arg(state), sqlc.arg(expires_at))
+ON CONFLICT (id) DO UPDATE
+SET state = EXCLUDED.state,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: DeleteState :exec
+DELETE FROM session_state
+WHERE id = sqlc.arg(id);
+
+-- name: ListSessions :many
+SELECT session
+FROM session
+WHERE expires_at > now()
+ORDER BY id;
+
+-- name: ListSessionsByTenant :many
+SELECT session
+FROM session
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND expires_at > now()
+ORDER BY id;
+
+-- name: ListSessionsBySubject :many
+SELECT session
+FROM session
+WHERE issuer = sqlc.arg(issuer)
+  AND subject = sqlc.arg(subject)
+  AND expires_at > now()
+ORDER BY id;
+
+-- name: GetSession :one
+SELECT session
+FROM session
+WHERE id = sqlc.arg(id)
+  AND expires_at > now();
+
+-- name: GetSessionByProviderID :one
+SELECT session
+FROM session
+WHERE provider_id = sqlc.arg(provider_id)
+  AND expires_at > now()
+ORDER BY expires_at DESC
+LIMIT 1;
+
+-- name: GetAccessToken :one
+SELECT access_token
+FROM session
+WHERE id = sqlc.arg(id)
+  AND expires_at > now();
+
+-- name: UpsertSession :exec
+INSERT INTO session (
+    id,
+    tenant_id,
+    provider_id,
+    issuer,
+    subject,
+    access_token,
+    refresh_token,
+    session,
+    expires_at)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(tenant_id),
+    sqlc.arg(provider_id),
+    sqlc.arg(issuer),
+    sqlc.arg(subject),
+    sqlc.arg(access_token),
+    sqlc.arg(refresh_token),
+    sqlc.arg(session),
+    sqlc.arg(expires_at))
+ON CONFLICT (id) DO UPDATE
+SET tenant_id = EXCLUDED.tenant_id,
+    provider_id = EXCLUDED.provider_id,
+    issuer = EXCLUDED.issuer,
+    subject = EXCLUDED.subject,
+    access_token = EXCLUDED.access_token,
+    refresh_token = EXCLUDED.refresh_token,
+    session = EXCLUDED.session,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: UpdateRefreshedSession :execrows
+UPDATE session
+SET access_token = sqlc.arg(access_token),
+    refresh_token = sqlc.arg(refresh_token),
+    session = sqlc.arg(session),
+    expires_at = sqlc.arg(expires_at)
+WHERE id = sqlc.arg(id)
+  AND refresh_token = sqlc.arg(previous_refresh_token)
+  AND expires_at > now();
+
+-- name: DeleteSession :exec
+DELETE FROM session
+WHERE id = sqlc.arg(id);
+
+-- name: IsActive :one
+SELECT EXISTS (
+    SELECT 1
+    FROM session_active
+    WHERE session_id = sqlc.arg(session_id)
+      AND expires_at > now()
+);
+
+-- name: BumpActive :exec
+INSERT INTO session_active (session_id, expires_at)
+VALUES (sqlc.arg(session_id), now() + sqlc.arg(timeout)::interval)
+ON CONFLICT (session_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at;
+
+-- name: InsertLogoutTokenID :execrows
+INSERT INTO session_logout_token (issuer, token_id, expires_at)
+VALUES (sqlc.arg(issuer), sqlc.arg(token_id), sqlc.arg(expires_at))
+ON CONFLICT (issuer, token_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at
+WHERE session_logout_token.expires_at <= now();
+
+-- name: DeleteLogoutTokenID :exec
+DELETE FROM session_logout_token
+WHERE issuer = sqlc.arg(issuer)
+  AND token_id = sqlc.arg(token_id);
+
+-- name: AcquireRefreshLock :execrows
+INSERT INTO session_refresh_lock (session_id, owner, expires_at)
+VALUES (sqlc.arg(session_id), sqlc.arg(owner), now() + sqlc.arg(ttl)::interval)
+ON CONFLICT (session_id) DO UPDATE
+SET owner = EXCLUDED.owner,
+    expires_at = EXCLUDED.expires_at
+WHERE session_refresh_lock.expires_at <= now();
+
+-- name: ReleaseRefreshLock :exec
+DELETE FROM session_refresh_lock
+WHERE session_id = sqlc.arg(session_id)
+  AND owner = sqlc.arg(owner);
+
+-- name: DeleteExpiredStates :execrows
+DELETE FROM session_state
+WHERE expires_at <= now();
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM session
+WHERE expires_at <= now();
+
+-- name: DeleteExpiredActive :execrows
+DELETE FROM session_active
+WHERE expires_at <= now();
+
+-- name: DeleteExpiredLogoutTokens :execrows
+DELETE FROM session_logout_token
+WHERE expires_at <= now();
+
+-- name: DeleteExpiredRefreshLocks :execrows
+DELETE FROM session_refresh_lock
+WHERE expires_at <= now();
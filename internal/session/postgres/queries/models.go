@@ -0,0 +1,44 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package queries
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Session struct {
+	ID           string             `db:"id"`
+	TenantID     string             `db:"tenant_id"`
+	ProviderID   string             `db:"provider_id"`
+	Issuer       string             `db:"issuer"`
+	Subject      string             `db:"subject"`
+	AccessToken  string             `db:"access_token"`
+	RefreshToken string             `db:"refresh_token"`
+	Session      []byte             `db:"session"`
+	ExpiresAt    pgtype.Timestamptz `db:"expires_at"`
+}
+
+type SessionActive struct {
+	SessionID string             `db:"session_id"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+}
+
+type SessionLogoutToken struct {
+	Issuer    string             `db:"issuer"`
+	TokenID   string             `db:"token_id"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+}
+
+type SessionRefreshLock struct {
+	SessionID string             `db:"session_id"`
+	Owner     string             `db:"owner"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+}
+
+type SessionState struct {
+	ID        string             `db:"id"`
+	State     []byte             `db:"state"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+}
@@ -0,0 +1,467 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: queries.sql
+
+package queries
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const acquireRefreshLock = `-- name: AcquireRefreshLock :execrows
+INSERT INTO session_refresh_lock (session_id, owner, expires_at)
+VALUES ($1, $2, now() + $3::interval)
+ON CONFLICT (session_id) DO UPDATE
+SET owner = EXCLUDED.owner,
+    expires_at = EXCLUDED.expires_at
+WHERE session_refresh_lock.expires_at <= now()
+`
+
+type AcquireRefreshLockParams struct {
+	SessionID string          `db:"session_id"`
+	Owner     string          `db:"owner"`
+	Ttl       pgtype.Interval `db:"ttl"`
+}
+
+func (q *Queries) AcquireRefreshLock(ctx context.Context, arg AcquireRefreshLockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, acquireRefreshLock, arg.SessionID, arg.Owner, arg.Ttl)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const bumpActive = `-- name: BumpActive :exec
+INSERT INTO session_active (session_id, expires_at)
+VALUES ($1, now() + $2::interval)
+ON CONFLICT (session_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at
+`
+
+type BumpActiveParams struct {
+	SessionID string          `db:"session_id"`
+	Timeout   pgtype.Interval `db:"timeout"`
+}
+
+func (q *Queries) BumpActive(ctx context.Context, arg BumpActiveParams) error {
+	_, err := q.db.Exec(ctx, bumpActive, arg.SessionID, arg.Timeout)
+	return err
+}
+
+const deleteExpiredActive = `-- name: DeleteExpiredActive :execrows
+DELETE FROM session_active
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredActive(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredActive)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredLogoutTokens = `-- name: DeleteExpiredLogoutTokens :execrows
+DELETE FROM session_logout_token
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredLogoutTokens(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredLogoutTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredRefreshLocks = `-- name: DeleteExpiredRefreshLocks :execrows
+DELETE FROM session_refresh_lock
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredRefreshLocks(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredRefreshLocks)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :execrows
+DELETE FROM session
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredSessions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredStates = `-- name: DeleteExpiredStates :execrows
+DELETE FROM session_state
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredStates(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredStates)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteLogoutTokenID = `-- name: DeleteLogoutTokenID :exec
+DELETE FROM session_logout_token
+WHERE issuer = $1
+  AND token_id = $2
+`
+
+type DeleteLogoutTokenIDParams struct {
+	Issuer  string `db:"issuer"`
+	TokenID string `db:"token_id"`
+}
+
+func (q *Queries) DeleteLogoutTokenID(ctx context.Context, arg DeleteLogoutTokenIDParams) error {
+	_, err := q.db.Exec(ctx, deleteLogoutTokenID, arg.Issuer, arg.TokenID)
+	return err
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM session
+WHERE id = $1
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, deleteSession, id)
+	return err
+}
+
+const deleteState = `-- name: DeleteState :exec
+DELETE FROM session_state
+WHERE id = $1
+`
+
+func (q *Queries) DeleteState(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, deleteState, id)
+	return err
+}
+
+const getAccessToken = `-- name: GetAccessToken :one
+SELECT access_token
+FROM session
+WHERE id = $1
+  AND expires_at > now()
+`
+
+func (q *Queries) GetAccessToken(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRow(ctx, getAccessToken, id)
+	var access_token string
+	err := row.Scan(&access_token)
+	return access_token, err
+}
+
+const getSession = `-- name: GetSession :one
+SELECT session
+FROM session
+WHERE id = $1
+  AND expires_at > now()
+`
+
+func (q *Queries) GetSession(ctx context.Context, id string) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getSession, id)
+	var session []byte
+	err := row.Scan(&session)
+	return session, err
+}
+
+const getSessionByProviderID = `-- name: GetSessionByProviderID :one
+SELECT session
+FROM session
+WHERE provider_id = $1
+  AND expires_at > now()
+ORDER BY expires_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetSessionByProviderID(ctx context.Context, providerID string) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getSessionByProviderID, providerID)
+	var session []byte
+	err := row.Scan(&session)
+	return session, err
+}
+
+const getState = `-- name: GetState :one
+SELECT state
+FROM session_state
+WHERE id = $1
+  AND expires_at > now()
+`
+
+func (q *Queries) GetState(ctx context.Context, id string) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getState, id)
+	var state []byte
+	err := row.Scan(&state)
+	return state, err
+}
+
+const insertLogoutTokenID = `-- name: InsertLogoutTokenID :execrows
+INSERT INTO session_logout_token (issuer, token_id, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (issuer, token_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at
+WHERE session_logout_token.expires_at <= now()
+`
+
+type InsertLogoutTokenIDParams struct {
+	Issuer    string             `db:"issuer"`
+	TokenID   string             `db:"token_id"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+}
+
+func (q *Queries) InsertLogoutTokenID(ctx context.Context, arg InsertLogoutTokenIDParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertLogoutTokenID, arg.Issuer, arg.TokenID, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const isActive = `-- name: IsActive :one
+SELECT EXISTS (
+    SELECT 1
+    FROM session_active
+    WHERE session_id = $1
+      AND expires_at > now()
+)
+`
+
+func (q *Queries) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	row := q.db.QueryRow(ctx, isActive, sessionID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listSessions = `-- name: ListSessions :many
+SELECT session
+FROM session
+WHERE expires_at > now()
+ORDER BY id
+`
+
+func (q *Queries) ListSessions(ctx context.Context) ([][]byte, error) {
+	rows, err := q.db.Query(ctx, listSessions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items [][]byte
+	for rows.Next() {
+		var session []byte
+		if err := rows.Scan(&session); err != nil {
+			return nil, err
+		}
+		items = append(items, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsBySubject = `-- name: ListSessionsBySubject :many
+SELECT session
+FROM session
+WHERE issuer = $1
+  AND subject = $2
+  AND expires_at > now()
+ORDER BY id
+`
+
+type ListSessionsBySubjectParams struct {
+	Issuer  string `db:"issuer"`
+	Subject string `db:"subject"`
+}
+
+func (q *Queries) ListSessionsBySubject(ctx context.Context, arg ListSessionsBySubjectParams) ([][]byte, error) {
+	rows, err := q.db.Query(ctx, listSessionsBySubject, arg.Issuer, arg.Subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items [][]byte
+	for rows.Next() {
+		var session []byte
+		if err := rows.Scan(&session); err != nil {
+			return nil, err
+		}
+		items = append(items, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsByTenant = `-- name: ListSessionsByTenant :many
+SELECT session
+FROM session
+WHERE tenant_id = $1
+  AND expires_at > now()
+ORDER BY id
+`
+
+func (q *Queries) ListSessionsByTenant(ctx context.Context, tenantID string) ([][]byte, error) {
+	rows, err := q.db.Query(ctx, listSessionsByTenant, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items [][]byte
+	for rows.Next() {
+		var session []byte
+		if err := rows.Scan(&session); err != nil {
+			return nil, err
+		}
+		items = append(items, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseRefreshLock = `-- name: ReleaseRefreshLock :exec
+DELETE FROM session_refresh_lock
+WHERE session_id = $1
+  AND owner = $2
+`
+
+type ReleaseRefreshLockParams struct {
+	SessionID string `db:"session_id"`
+	Owner     string `db:"owner"`
+}
+
+func (q *Queries) ReleaseRefreshLock(ctx context.Context, arg ReleaseRefreshLockParams) error {
+	_, err := q.db.Exec(ctx, releaseRefreshLock, arg.SessionID, arg.Owner)
+	return err
+}
+
+const updateRefreshedSession = `-- name: UpdateRefreshedSession :execrows
+UPDATE session
+SET access_token = $1,
+    refresh_token = $2,
+    session = $3,
+    expires_at = $4
+WHERE id = $5
+  AND refresh_token = $6
+  AND expires_at > now()
+`
+
+type UpdateRefreshedSessionParams struct {
+	AccessToken          string             `db:"access_token"`
+	RefreshToken         string             `db:"refresh_token"`
+	Session              []byte             `db:"session"`
+	ExpiresAt            pgtype.Timestamptz `db:"expires_at"`
+	ID                   string             `db:"id"`
+	PreviousRefreshToken string             `db:"previous_refresh_token"`
+}
+
+func (q *Queries) UpdateRefreshedSession(ctx context.Context, arg UpdateRefreshedSessionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateRefreshedSession,
+		arg.AccessToken,
+		arg.RefreshToken,
+		arg.Session,
+		arg.ExpiresAt,
+		arg.ID,
+		arg.PreviousRefreshToken,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertSession = `-- name: UpsertSession :exec
+INSERT INTO session (
+    id,
+    tenant_id,
+    provider_id,
+    issuer,
+    subject,
+    access_token,
+    refresh_token,
+    session,
+    expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9)
+ON CONFLICT (id) DO UPDATE
+SET tenant_id = EXCLUDED.tenant_id,
+    provider_id = EXCLUDED.provider_id,
+    issuer = EXCLUDED.issuer,
+    subject = EXCLUDED.subject,
+    access_token = EXCLUDED.access_token,
+    refresh_token = EXCLUDED.refresh_token,
+    session = EXCLUDED.session,
+    expires_at = EXCLUDED.expires_at
+`
+
+type UpsertSessionParams struct {
+	ID           string             `db:"id"`
+	TenantID     string             `db:"tenant_id"`
+	ProviderID   string             `db:"provider_id"`
+	Issuer       string             `db:"issuer"`
+	Subject      string             `db:"subject"`
+	AccessToken  string             `db:"access_token"`
+	RefreshToken string             `db:"refresh_token"`
+	Session      []byte             `db:"session"`
+	ExpiresAt    pgtype.Timestamptz `db:"expires_at"`
+}
+
+func (q *Queries) UpsertSession(ctx context.Context, arg UpsertSessionParams) error {
+	_, err := q.db.Exec(ctx, upsertSession,
+		arg.ID,
+		arg.TenantID,
+		arg.ProviderID,
+		arg.Issuer,
+		arg.Subject,
+		arg.AccessToken,
+		arg.RefreshToken,
+		arg.Session,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const upsertState = `-- name: UpsertState :exec
+INSERT INTO session_state (id, state, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (id) DO UPDATE
+SET state = EXCLUDED.state,
+    expires_at = EXCLUDED.expires_at
+`
+
+type UpsertStateParams struct {
+	ID        string             `db:"id"`
+	State     []byte             `db:"state"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+}
+
+func (q *Queries) UpsertState(ctx context.Context, arg UpsertStateParams) error {
+	_, err := q.db.Exec(ctx, upsertState, arg.ID, arg.State, arg.ExpiresAt)
+	return err
+}
@@ -0,0 +1,317 @@
+// Package sessionpostgres provides a session.Repository backed by PostgreSQL.
+// Expired rows are ignored by all queries and removed by the Sweep loop.
+package sessionpostgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/internal/session/postgres/queries"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+var (
+	ErrGetSessions        = errors.New("getting sessions from database")
+	ErrGetState           = errors.New("getting state from database")
+	ErrStoreState         = errors.New("storing state into database")
+	ErrStoreSession       = errors.New("storing session into database")
+	ErrGetSession         = errors.New("getting session from database")
+	ErrGetAccessToken     = errors.New("getting access token from database")
+	ErrStoreLogoutTokenID = errors.New("storing logout token ID into database")
+	ErrAcquireRefreshLock = errors.New("acquiring refresh lock")
+	ErrReleaseRefreshLock = errors.New("releasing refresh lock")
+)
+
+type Repository struct {
+	queries *queries.Queries
+}
+
+var _ = session.Repository(&Repository{})
+
+func NewRepository(db sessionmanager.Database) *Repository {
+	return &Repository{
+		queries: queries.New(db),
+	}
+}
+
+func (r *Repository) LoadState(ctx context.Context, stateID string) (session.State, error) {
+	data, err := r.queries.GetState(ctx, stateID)
+	if err != nil {
+		return session.State{}, errors.Join(ErrGetState, notFound(err))
+	}
+
+	var state session.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return session.State{}, errors.Join(ErrGetState, fmt.Errorf("unmarshaling json: %w", err))
+	}
+
+	return state, nil
+}
+
+func (r *Repository) StoreState(ctx context.Context, state session.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Join(ErrStoreState, fmt.Errorf("marshaling json: %w", err))
+	}
+
+	err = r.queries.UpsertState(ctx, queries.UpsertStateParams{
+		ID:        state.ID,
+		State:     data,
+		ExpiresAt: timestamptz(state.Expiry),
+	})
+	if err != nil {
+		return errors.Join(ErrStoreState, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteState(ctx context.Context, stateID string) error {
+	if err := r.queries.DeleteState(ctx, stateID); err != nil {
+		return fmt.Errorf("deleting state from database: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) ListSessions(ctx context.Context) ([]session.Session, error) {
+	rows, err := r.queries.ListSessions(ctx)
+	if err != nil {
+		return nil, errors.Join(ErrGetSessions, err)
+	}
+
+	return decodeSessions(rows)
+}
+
+// ListSessionsByTenant returns the sessions of the tenant.
+func (r *Repository) ListSessionsByTenant(ctx context.Context, tenantID string) ([]session.Session, error) {
+	rows, err := r.queries.ListSessionsByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, errors.Join(ErrGetSessions, err)
+	}
+
+	return decodeSessions(rows)
+}
+
+// ListSessionsBySubject returns the sessions of the subject defined by the issuer.
+func (r *Repository) ListSessionsBySubject(ctx context.Context, issuer, subject string) ([]session.Session, error) {
+	rows, err := r.queries.ListSessionsBySubject(ctx, queries.ListSessionsBySubjectParams{
+		Issuer:  issuer,
+		Subject: subject,
+	})
+	if err != nil {
+		return nil, errors.Join(ErrGetSessions, err)
+	}
+
+	return decodeSessions(rows)
+}
+
+func (r *Repository) LoadSession(ctx context.Context, sessionID string) (session.Session, error) {
+	data, err := r.queries.GetSession(ctx, sessionID)
+	if err != nil {
+		return session.Session{}, errors.Join(ErrGetSession, notFound(err))
+	}
+
+	return decodeSession(data)
+}
+
+// LoadSessionByProviderID returns the session of the provider ID which
+// expires last if several sessions share it.
+func (r *Repository) LoadSessionByProviderID(ctx context.Context, providerID string) (session.Session, error) {
+	data, err := r.queries.GetSessionByProviderID(ctx, providerID)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("getting session by provider id: %w", errors.Join(ErrGetSession, notFound(err)))
+	}
+
+	return decodeSession(data)
+}
+
+func (r *Repository) GetAccessTokenForSession(ctx context.Context, sessionID string) (string, error) {
+	accessToken, err := r.queries.GetAccessToken(ctx, sessionID)
+	if err != nil {
+		return "", errors.Join(ErrGetAccessToken, notFound(err))
+	}
+
+	return accessToken, nil
+}
+
+func (r *Repository) StoreSession(ctx context.Context, s session.Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.Join(ErrStoreSession, fmt.Errorf("marshaling json: %w", err))
+	}
+
+	err = r.queries.UpsertSession(ctx, queries.UpsertSessionParams{
+		ID:           s.ID,
+		TenantID:     s.TenantID,
+		ProviderID:   s.ProviderID,
+		Issuer:       s.Issuer,
+		Subject:      s.ProviderSubject,
+		AccessToken:  s.AccessToken,
+		RefreshToken: s.RefreshToken,
+		Session:      data,
+		ExpiresAt:    timestamptz(s.Expiry),
+	})
+	if err != nil {
+		return errors.Join(ErrStoreSession, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) StoreRefreshedSession(ctx context.Context, s session.Session, previousRefreshToken string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.Join(ErrStoreSession, fmt.Errorf("marshaling json: %w", err))
+	}
+
+	updated, err := r.queries.UpdateRefreshedSession(ctx, queries.UpdateRefreshedSessionParams{
+		ID:                   s.ID,
+		AccessToken:          s.AccessToken,
+		RefreshToken:         s.RefreshToken,
+		Session:              data,
+		ExpiresAt:            timestamptz(s.Expiry),
+		PreviousRefreshToken: previousRefreshToken,
+	})
+	if err != nil {
+		return errors.Join(ErrStoreSession, err)
+	}
+	if updated == 0 {
+		return serviceerr.ErrConflict
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteSession(ctx context.Context, s session.Session) error {
+	if err := r.queries.DeleteSession(ctx, s.ID); err != nil {
+		return fmt.Errorf("deleting session from database: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	active, err := r.queries.IsActive(ctx, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("getting active row: %w", err)
+	}
+
+	return active, nil
+}
+
+func (r *Repository) BumpActive(ctx context.Context, sessionID string, timeout time.Duration) error {
+	err := r.queries.BumpActive(ctx, queries.BumpActiveParams{
+		SessionID: sessionID,
+		Timeout:   interval(timeout),
+	})
+	if err != nil {
+		return fmt.Errorf("storing an active row: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) StoreLogoutTokenID(ctx context.Context, issuer, tokenID string, expiry time.Time) error {
+	inserted, err := r.queries.InsertLogoutTokenID(ctx, queries.InsertLogoutTokenIDParams{
+		Issuer:    issuer,
+		TokenID:   tokenID,
+		ExpiresAt: timestamptz(expiry),
+	})
+	if err != nil {
+		return errors.Join(ErrStoreLogoutTokenID, err)
+	}
+	if inserted == 0 {
+		return serviceerr.ErrConflict
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteLogoutTokenID(ctx context.Context, issuer, tokenID string) error {
+	err := r.queries.DeleteLogoutTokenID(ctx, queries.DeleteLogoutTokenIDParams{
+		Issuer:  issuer,
+		TokenID: tokenID,
+	})
+	if err != nil {
+		return fmt.Errorf("deleting logout token ID from database: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) AcquireRefreshLock(ctx context.Context, sessionID, owner string, ttl time.Duration) error {
+	acquired, err := r.queries.AcquireRefreshLock(ctx, queries.AcquireRefreshLockParams{
+		SessionID: sessionID,
+		Owner:     owner,
+		Ttl:       interval(ttl),
+	})
+	if err != nil {
+		return errors.Join(ErrAcquireRefreshLock, err)
+	}
+	if acquired == 0 {
+		return serviceerr.ErrConflict
+	}
+
+	return nil
+}
+
+func (r *Repository) ReleaseRefreshLock(ctx context.Context, sessionID, owner string) error {
+	// The lock may have expired and been acquired by another owner meanwhile
+	err := r.queries.ReleaseRefreshLock(ctx, queries.ReleaseRefreshLockParams{
+		SessionID: sessionID,
+		Owner:     owner,
+	})
+	if err != nil {
+		return errors.Join(ErrReleaseRefreshLock, err)
+	}
+
+	return nil
+}
+
+func decodeSession(data []byte) (session.Session, error) {
+	var s session.Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return session.Session{}, errors.Join(ErrGetSession, fmt.Errorf("unmarshaling json: %w", err))
+	}
+
+	return s, nil
+}
+
+func decodeSessions(rows [][]byte) ([]session.Session, error) {
+	sessions := make([]session.Session, 0, len(rows))
+	for _, data := range rows {
+		var s session.Session
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, errors.Join(ErrGetSessions, fmt.Errorf("unmarshaling json: %w", err))
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+}
+
+// notFound replaces pgx.ErrNoRows with serviceerr.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return serviceerr.ErrNotFound
+	}
+
+	return err
+}
+
+func timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func interval(d time.Duration) pgtype.Interval {
+	return pgtype.Interval{Microseconds: d.Microseconds(), Valid: true}
+}
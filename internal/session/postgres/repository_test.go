@@ -0,0 +1,111 @@
+package sessionpostgres_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/dbtest/postgrestest"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionpostgres "github.com/openkcm/session-manager/internal/session/postgres"
+	"github.com/openkcm/session-manager/internal/session/sessiontest"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+var dbPool sessionmanager.Database
+
+type pooldb struct {
+	*pgxpool.Pool
+}
+
+func (p *pooldb) STDAdapter() *sql.DB {
+	return stdlib.OpenDBFromPool(p.Pool)
+}
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	pool, _, terminate := postgrestest.Start(ctx)
+	defer terminate(ctx)
+
+	dbPool = &pooldb{pool}
+
+	// Migrating twice must be a no-op
+	for range 2 {
+		if err := sessionpostgres.Migrate(ctx, dbPool.STDAdapter()); err != nil {
+			panic(err)
+		}
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestRepository_Conformance(t *testing.T) {
+	sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+		return sessionpostgres.NewRepository(dbPool)
+	})
+}
+
+func TestRepository_DeleteExpired(t *testing.T) {
+	ctx := t.Context()
+	r := sessionpostgres.NewRepository(dbPool)
+
+	expired := time.Now().Add(-time.Minute)
+	require.NoError(t, r.StoreState(ctx, session.State{ID: "expired-state", Expiry: expired}))
+	require.NoError(t, r.StoreSession(ctx, session.Session{ID: "expired-session", ProviderID: "expired-provider", Expiry: expired}))
+	require.NoError(t, r.StoreState(ctx, session.State{ID: "valid-state", Expiry: time.Now().Add(time.Hour)}))
+
+	_, err := r.LoadSession(ctx, "expired-session")
+	require.ErrorIs(t, err, serviceerr.ErrNotFound, "expired sessions must not be loaded before they are deleted")
+
+	deleted, err := r.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(2))
+
+	var count int
+	err = dbPool.QueryRow(ctx, `SELECT count(*) FROM session_state WHERE id = 'expired-state'`).Scan(&count)
+	require.NoError(t, err)
+	assert.Zero(t, count, "expired state must be deleted")
+
+	err = dbPool.QueryRow(ctx, `SELECT count(*) FROM session WHERE id = 'expired-session'`).Scan(&count)
+	require.NoError(t, err)
+	assert.Zero(t, count, "expired session must be deleted")
+
+	_, err = r.LoadState(ctx, "valid-state")
+	require.NoError(t, err, "valid state must be kept")
+}
+
+func TestRepository_Sweep(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	r := sessionpostgres.NewRepository(dbPool)
+
+	require.NoError(t, r.StoreState(ctx, session.State{ID: "swept-state", Expiry: time.Now().Add(-time.Minute)}))
+
+	done := make(chan struct{})
+	go func() {
+		r.Sweep(ctx, 50*time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		var count int
+		err := dbPool.QueryRow(ctx, `SELECT count(*) FROM session_state WHERE id = 'swept-state'`).Scan(&count)
+		return err == nil && count == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sweep did not return after the context was cancelled")
+	}
+}
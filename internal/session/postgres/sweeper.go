@@ -0,0 +1,56 @@
+package sessionpostgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+// DeleteExpired deletes the expired states, sessions, active rows, logout
+// token IDs and refresh locks and returns the number of deleted rows.
+func (r *Repository) DeleteExpired(ctx context.Context) (int64, error) {
+	deletes := []struct {
+		name          string
+		deleteExpired func(context.Context) (int64, error)
+	}{
+		{"states", r.queries.DeleteExpiredStates},
+		{"sessions", r.queries.DeleteExpiredSessions},
+		{"active rows", r.queries.DeleteExpiredActive},
+		{"logout token IDs", r.queries.DeleteExpiredLogoutTokens},
+		{"refresh locks", r.queries.DeleteExpiredRefreshLocks},
+	}
+
+	var total int64
+	for _, d := range deletes {
+		deleted, err := d.deleteExpired(ctx)
+		if err != nil {
+			return total, fmt.Errorf("deleting expired %s: %w", d.name, err)
+		}
+		total += deleted
+	}
+
+	return total, nil
+}
+
+// Sweep deletes the expired rows every interval until the context is done.
+// Failures are logged and retried at the next interval.
+func (r *Repository) Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := r.DeleteExpired(ctx)
+			if err != nil {
+				slogctx.Error(ctx, "Could not delete expired session rows", "error", err)
+				continue
+			}
+			slogctx.Debug(ctx, "Deleted expired session rows", "count", deleted)
+		}
+	}
+}
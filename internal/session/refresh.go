@@ -0,0 +1,170 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/gofrs/uuid/v5"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+const (
+	// refreshLockTTL bounds the time a replica holds the refresh lock of a session.
+	refreshLockTTL = 30 * time.Second
+	// refreshLockPollInterval is the interval to poll for a session refreshed by another replica.
+	refreshLockPollInterval = 100 * time.Millisecond
+)
+
+// RefreshExpiringSession refreshes the access token of the session if it
+// expires within the given window and returns the session with the current
+// tokens. Concurrent refreshes of a session are deduplicated within the process
+// and, with a lock in the session repository, across replicas.
+func (m *Manager) RefreshExpiringSession(ctx context.Context, s Session, window time.Duration) (Session, error) {
+	if !accessTokenExpiresWithin(s, window) {
+		return s, nil
+	}
+
+	// The refresh is shared with concurrent callers, so it must not be
+	// cancelled together with the request of the first caller.
+	refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshLockTTL)
+	defer cancel()
+
+	v, err, _ := m.refreshGroup.Do(s.ID, func() (any, error) {
+		return m.refreshSessionLocked(refreshCtx, s)
+	})
+	if err != nil {
+		return Session{}, err
+	}
+
+	//nolint:forcetypeassert
+	return v.(Session), nil
+}
+
+// accessTokenExpiresWithin reports whether the access token of the session
+// expires within the window. An access token of unknown expiry, e.g. an opaque
+// token issued without expires_in, is not refreshed: every refresh would
+// return a token of unknown expiry again and rotate the refresh token.
+func accessTokenExpiresWithin(s Session, window time.Duration) bool {
+	if s.AccessTokenExpiry.IsZero() {
+		return false
+	}
+
+	return time.Until(s.AccessTokenExpiry) < window
+}
+
+// refreshSessionLocked refreshes the access token of the session while holding
+// its refresh lock. If another replica holds the lock, it waits for the session
+// refreshed by that replica.
+func (m *Manager) refreshSessionLocked(ctx context.Context, s Session) (Session, error) {
+	owner, err := uuid.NewV4()
+	if err != nil {
+		return Session{}, fmt.Errorf("generating lock owner: %w", err)
+	}
+
+	for {
+		err := m.sessions.AcquireRefreshLock(ctx, s.ID, owner.String(), refreshLockTTL)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, serviceerr.ErrConflict) {
+			return Session{}, fmt.Errorf("acquiring refresh lock: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return Session{}, fmt.Errorf("waiting for refresh lock: %w", ctx.Err())
+		case <-time.After(refreshLockPollInterval):
+		}
+
+		refreshed, ok, err := m.loadRefreshedSession(ctx, s)
+		if err != nil {
+			return Session{}, err
+		}
+		if ok {
+			return refreshed, nil
+		}
+	}
+
+	defer func() {
+		err := m.sessions.ReleaseRefreshLock(ctx, s.ID, owner.String())
+		if err != nil {
+			slogctx.Error(ctx, "Failed to release the refresh lock", "error", err)
+		}
+	}()
+
+	// Another replica may have refreshed the session before the lock was acquired
+	refreshed, ok, err := m.loadRefreshedSession(ctx, s)
+	if err != nil {
+		return Session{}, err
+	}
+	if ok {
+		return refreshed, nil
+	}
+
+	return m.refreshAccessToken(ctx, refreshed)
+}
+
+// loadRefreshedSession loads the session and reports whether its access token
+// has been refreshed since the given session was loaded.
+func (m *Manager) loadRefreshedSession(ctx context.Context, s Session) (Session, bool, error) {
+	loaded, err := m.sessions.LoadSession(ctx, s.ID)
+	if err != nil {
+		return Session{}, false, fmt.Errorf("loading session: %w", err)
+	}
+
+	return loaded, loaded.AccessTokenExpiry.After(s.AccessTokenExpiry), nil
+}
+
+// endRejectedSession deletes the session after its refresh token has been
+// rejected by the provider, e.g. because it has been revoked or reused. A
+// session whose tokens have been replaced meanwhile is returned instead.
+func (m *Manager) endRejectedSession(ctx context.Context, s Session) (Session, error) {
+	stored, err := m.sessions.LoadSession(ctx, s.ID)
+	if err != nil {
+		return Session{}, fmt.Errorf("loading session: %w", err)
+	}
+	if stored.RefreshToken != s.RefreshToken {
+		return stored, nil
+	}
+
+	err = m.sessions.DeleteSession(ctx, stored)
+	if err != nil {
+		return Session{}, fmt.Errorf("deleting session with rejected refresh token: %w", err)
+	}
+	slogctx.Warn(ctx, "Deleted session as its refresh token has been rejected", "tenant_id", stored.TenantID)
+	m.sendSessionRevocationAudit(ctx, stored)
+
+	return Session{}, fmt.Errorf("refresh token rejected: %w", serviceerr.ErrInvalidGrant)
+}
+
+// accessTokenAlgorithms are the algorithms of access tokens issued as JWT.
+var accessTokenAlgorithms = append([]jose.SignatureAlgorithm{jose.HS256, jose.HS384, jose.HS512}, logoutTokenAlgorithms...)
+
+// expiries returns the expiry times of the access and the refresh token of the
+// token response. The expiry of the access token falls back to the exp claim
+// if the access token is a JWT. The zero time is returned for unknown expiries.
+func (t tokenResponse) expiries(now time.Time) (accessTokenExpiry, refreshTokenExpiry time.Time) {
+	if t.ExpiresIn > 0 {
+		accessTokenExpiry = now.Add(time.Duration(t.ExpiresIn) * time.Second)
+	} else if token, err := jwt.ParseSigned(t.AccessToken, accessTokenAlgorithms); err == nil {
+		// The access token is opaque to the client, so its signature is not
+		// verified. It has been received from the token endpoint directly.
+		var claims jwt.Claims
+		if err := token.UnsafeClaimsWithoutVerification(&claims); err == nil && claims.Expiry != nil {
+			accessTokenExpiry = claims.Expiry.Time()
+		}
+	}
+
+	if t.RefreshExpiresIn > 0 {
+		refreshTokenExpiry = now.Add(time.Duration(t.RefreshExpiresIn) * time.Second)
+	}
+
+	return accessTokenExpiry, refreshTokenExpiry
+}
@@ -0,0 +1,488 @@
+package session_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+// startRefreshServer starts an OIDC provider which issues new tokens for a
+// refresh token and counts the refresh requests.
+func startRefreshServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	return startTokenServer(t, delay, http.StatusOK, map[string]any{
+		"access_token":  "new-access-token",
+		"refresh_token": "new-refresh-token",
+		"expires_in":    3600,
+	})
+}
+
+// startTokenServer starts an OIDC provider whose token endpoint responds with
+// the given status and body and counts the token requests.
+func startTokenServer(t *testing.T, delay time.Duration, status int, body map[string]any) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":         server.URL,
+				"token_endpoint": server.URL + "/token",
+			})
+		case "/token":
+			requests.Add(1)
+			time.Sleep(delay)
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server, &requests
+}
+
+func TestManager_RefreshExpiringSession(t *testing.T) {
+	const (
+		tenantID  = "test-tenant"
+		sessionID = "test-session-id"
+	)
+
+	tests := []struct {
+		name            string
+		expiry          time.Duration
+		storedExpiry    time.Duration
+		lockExpiry      time.Duration
+		wantRefreshes   int32
+		wantAccessToken string
+	}{
+		{
+			name:            "Access token is not expiring",
+			expiry:          time.Hour,
+			storedExpiry:    time.Hour,
+			wantAccessToken: "old-access-token",
+		},
+		{
+			name:            "Access token is expiring",
+			expiry:          30 * time.Second,
+			storedExpiry:    30 * time.Second,
+			wantRefreshes:   1,
+			wantAccessToken: "new-access-token",
+		},
+		{
+			name:            "Access token has expired",
+			expiry:          -time.Minute,
+			storedExpiry:    -time.Minute,
+			wantRefreshes:   1,
+			wantAccessToken: "new-access-token",
+		},
+		{
+			name:            "Session has been refreshed meanwhile",
+			expiry:          30 * time.Second,
+			storedExpiry:    time.Hour,
+			wantAccessToken: "old-access-token",
+		},
+		{
+			name:            "Lock is held by another replica",
+			expiry:          30 * time.Second,
+			storedExpiry:    30 * time.Second,
+			lockExpiry:      300 * time.Millisecond,
+			wantRefreshes:   1,
+			wantAccessToken: "new-access-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, refreshes := startRefreshServer(t, 0)
+			defer server.Close()
+
+			now := time.Now()
+			sess := session.Session{
+				ID:                sessionID,
+				TenantID:          tenantID,
+				AccessToken:       "old-access-token",
+				RefreshToken:      "old-refresh-token",
+				AccessTokenExpiry: now.Add(tt.expiry),
+				Expiry:            now.Add(time.Hour),
+			}
+			stored := sess
+			stored.AccessTokenExpiry = now.Add(tt.storedExpiry)
+
+			opts := []sessionmock.RepositoryOption{sessionmock.WithSession(stored)}
+			if tt.lockExpiry > 0 {
+				opts = append(opts, sessionmock.WithRefreshLock(sessionID, "other-replica", time.Now().Add(tt.lockExpiry)))
+			}
+			sessions := sessionmock.NewInMemRepository(opts...)
+
+			m := newRefreshManager(t, server.URL, tenantID, sessions, nil)
+
+			refreshed, err := m.RefreshExpiringSession(t.Context(), sess, time.Minute)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantRefreshes, refreshes.Load())
+			assert.Equal(t, tt.wantAccessToken, refreshed.AccessToken)
+
+			stored, err = sessions.LoadSession(t.Context(), sessionID)
+			require.NoError(t, err)
+			assert.Equal(t, refreshed.AccessToken, stored.AccessToken)
+		})
+	}
+
+	t.Run("Opaque access token without expires_in is not refreshed", func(t *testing.T) {
+		server, refreshes := startTokenServer(t, 0, http.StatusOK, map[string]any{
+			"access_token":  "new-opaque-access-token",
+			"refresh_token": "new-refresh-token",
+		})
+		defer server.Close()
+
+		// The expiry of the opaque access token is unknown
+		sess := session.Session{
+			ID:           sessionID,
+			TenantID:     tenantID,
+			AccessToken:  "old-opaque-access-token",
+			RefreshToken: "old-refresh-token",
+			Expiry:       time.Now().Add(time.Hour),
+		}
+		sessions := sessionmock.NewInMemRepository(sessionmock.WithSession(sess))
+
+		m := newRefreshManager(t, server.URL, tenantID, sessions, nil)
+
+		for range 3 {
+			refreshed, err := m.RefreshExpiringSession(t.Context(), sess, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "old-opaque-access-token", refreshed.AccessToken)
+		}
+
+		assert.Zero(t, refreshes.Load(), "the refresh token must not be rotated on every call")
+	})
+
+	t.Run("Concurrent refreshes are deduplicated", func(t *testing.T) {
+		server, refreshes := startRefreshServer(t, 100*time.Millisecond)
+		defer server.Close()
+
+		sess := session.Session{
+			ID:                sessionID,
+			TenantID:          tenantID,
+			AccessToken:       "old-access-token",
+			RefreshToken:      "old-refresh-token",
+			AccessTokenExpiry: time.Now().Add(30 * time.Second),
+			Expiry:            time.Now().Add(time.Hour),
+		}
+		sessions := sessionmock.NewInMemRepository(sessionmock.WithSession(sess))
+
+		m := newRefreshManager(t, server.URL, tenantID, sessions, nil)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				refreshed, err := m.RefreshExpiringSession(t.Context(), sess, time.Minute)
+				assert.NoError(t, err)
+				assert.Equal(t, "new-access-token", refreshed.AccessToken)
+			})
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), refreshes.Load())
+	})
+}
+
+func newRefreshManager(t *testing.T, issuer, tenantID string, sessions session.Repository, auditLogger *otlpaudit.AuditLogger) *session.Manager {
+	t.Helper()
+
+	trust := newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustv1.Trust_builder{
+		TenantId: new(tenantID),
+		Oidc: oidcv1.OIDC_builder{
+			Issuer:   new(issuer),
+			ClientId: new(testClientID),
+		}.Build(),
+	}.Build())))
+
+	m, err := session.NewManager(t.Context(),
+		&config.SessionManager{CSRFSecretParsed: []byte(testCSRFSecret)},
+		trust,
+		sessions,
+		auditLogger,
+		session.WithAllowHttpScheme(true),
+	)
+	require.NoError(t, err)
+
+	return m
+}
+
+// rotatingRepository replaces the tokens of the session once the token
+// endpoint has been requested, as a concurrent refresher would.
+type rotatingRepository struct {
+	*sessionmock.Repository
+
+	tokenRequests *atomic.Int32
+	rotated       bool
+}
+
+func (r *rotatingRepository) LoadSession(ctx context.Context, sessionID string) (session.Session, error) {
+	r.rotate(ctx, sessionID)
+	return r.Repository.LoadSession(ctx, sessionID)
+}
+
+func (r *rotatingRepository) StoreRefreshedSession(ctx context.Context, s session.Session, previousRefreshToken string) error {
+	r.rotate(ctx, s.ID)
+	return r.Repository.StoreRefreshedSession(ctx, s, previousRefreshToken)
+}
+
+func (r *rotatingRepository) rotate(ctx context.Context, sessionID string) {
+	if r.rotated || r.tokenRequests.Load() == 0 {
+		return
+	}
+	r.rotated = true
+
+	s, err := r.Repository.LoadSession(ctx, sessionID)
+	if err != nil {
+		return
+	}
+	s.AccessToken = "rotated-access-token"
+	s.RefreshToken = "rotated-refresh-token"
+	_ = r.Repository.StoreSession(ctx, s)
+}
+
+func TestManager_RefreshExpiringSession_Rotation(t *testing.T) {
+	const (
+		tenantID  = "test-tenant"
+		sessionID = "test-session-id"
+	)
+
+	invalidGrant := map[string]any{"error": "invalid_grant", "error_description": "refresh token has been revoked"}
+
+	tests := []struct {
+		name             string
+		status           int
+		body             map[string]any
+		concurrent       bool
+		wantErr          error
+		wantAccessToken  string
+		wantRefreshToken string
+		wantAuditEvents  int32
+	}{
+		{
+			name:             "Refresh token is rotated",
+			status:           http.StatusOK,
+			body:             map[string]any{"access_token": "new-access-token", "refresh_token": "new-refresh-token", "expires_in": 3600},
+			wantAccessToken:  "new-access-token",
+			wantRefreshToken: "new-refresh-token",
+		},
+		{
+			name:             "Refresh token is not rotated",
+			status:           http.StatusOK,
+			body:             map[string]any{"access_token": "new-access-token", "expires_in": 3600},
+			wantAccessToken:  "new-access-token",
+			wantRefreshToken: "old-refresh-token",
+		},
+		{
+			name:             "Tokens are replaced by a concurrent refresh",
+			status:           http.StatusOK,
+			body:             map[string]any{"access_token": "new-access-token", "refresh_token": "new-refresh-token", "expires_in": 3600},
+			concurrent:       true,
+			wantAccessToken:  "rotated-access-token",
+			wantRefreshToken: "rotated-refresh-token",
+		},
+		{
+			name:            "Refresh token is rejected",
+			status:          http.StatusBadRequest,
+			body:            invalidGrant,
+			wantErr:         serviceerr.ErrInvalidGrant,
+			wantAuditEvents: 1,
+		},
+		{
+			name:             "Refresh token is rejected after a concurrent refresh",
+			status:           http.StatusBadRequest,
+			body:             invalidGrant,
+			concurrent:       true,
+			wantAccessToken:  "rotated-access-token",
+			wantRefreshToken: "rotated-refresh-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, tokenRequests := startTokenServer(t, 0, tt.status, tt.body)
+			defer server.Close()
+
+			var auditEvents atomic.Int32
+			auditServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auditEvents.Add(1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer auditServer.Close()
+
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			sess := session.Session{
+				ID:                sessionID,
+				TenantID:          tenantID,
+				AccessToken:       "old-access-token",
+				RefreshToken:      "old-refresh-token",
+				AccessTokenExpiry: time.Now().Add(30 * time.Second),
+				Expiry:            time.Now().Add(time.Hour),
+			}
+			var sessions session.Repository = sessionmock.NewInMemRepository(sessionmock.WithSession(sess))
+			if tt.concurrent {
+				sessions = &rotatingRepository{
+					Repository:    sessionmock.NewInMemRepository(sessionmock.WithSession(sess)),
+					tokenRequests: tokenRequests,
+				}
+			}
+
+			m := newRefreshManager(t, server.URL, tenantID, sessions, auditLogger)
+
+			refreshed, err := m.RefreshExpiringSession(t.Context(), sess, time.Minute)
+			assert.Equal(t, int32(1), tokenRequests.Load())
+			assert.Equal(t, tt.wantAuditEvents, auditEvents.Load())
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				_, err = sessions.LoadSession(t.Context(), sessionID)
+				require.ErrorIs(t, err, serviceerr.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAccessToken, refreshed.AccessToken)
+			assert.Equal(t, tt.wantRefreshToken, refreshed.RefreshToken)
+
+			stored, err := sessions.LoadSession(t.Context(), sessionID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAccessToken, stored.AccessToken)
+			assert.Equal(t, tt.wantRefreshToken, stored.RefreshToken)
+		})
+	}
+}
+
+func TestTokenResponse_Expiries(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	require.NoError(t, err)
+	jwtAccessToken, err := jwt.Signed(signer).Claims(jwt.Claims{Expiry: jwt.NewNumericDate(now.Add(5 * time.Minute))}).Serialize()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                   string
+		tokens                 session.TokenResponse
+		wantAccessTokenExpiry  time.Time
+		wantRefreshTokenExpiry time.Time
+	}{
+		{
+			name:                  "expires_in",
+			tokens:                session.TokenResponse{AccessToken: jwtAccessToken, ExpiresIn: 300},
+			wantAccessTokenExpiry: now.Add(300 * time.Second),
+		},
+		{
+			name:                  "exp claim of a JWT access token",
+			tokens:                session.TokenResponse{AccessToken: jwtAccessToken},
+			wantAccessTokenExpiry: now.Add(5 * time.Minute),
+		},
+		{
+			name:   "Opaque access token",
+			tokens: session.TokenResponse{AccessToken: "opaque-access-token"},
+		},
+		{
+			name:                   "refresh_expires_in",
+			tokens:                 session.TokenResponse{AccessToken: "opaque-access-token", ExpiresIn: 300, RefreshExpiresIn: 1800},
+			wantAccessTokenExpiry:  now.Add(300 * time.Second),
+			wantRefreshTokenExpiry: now.Add(1800 * time.Second),
+		},
+		{
+			name:                  "Refresh token without expiry",
+			tokens:                session.TokenResponse{AccessToken: "opaque-access-token", ExpiresIn: 300, RefreshExpiresIn: 0},
+			wantAccessTokenExpiry: now.Add(300 * time.Second),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessTokenExpiry, refreshTokenExpiry := session.TokenExpiries(tt.tokens, now)
+			assert.True(t, tt.wantAccessTokenExpiry.Equal(accessTokenExpiry), "access token expiry %v", accessTokenExpiry)
+			assert.True(t, tt.wantRefreshTokenExpiry.Equal(refreshTokenExpiry), "refresh token expiry %v", refreshTokenExpiry)
+		})
+	}
+}
+
+func TestManager_FinaliseOIDCLogin_TokenExpiry(t *testing.T) {
+	const (
+		tenantID = "tenant-id"
+		stateID  = "test-state-id"
+	)
+
+	oidcServer := StartOIDCServer(t, false)
+	defer oidcServer.Close()
+
+	auditServer := StartAuditServer(t)
+	defer auditServer.Close()
+
+	auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+	require.NoError(t, err)
+
+	sessions := sessionmock.NewInMemRepository(sessionmock.WithState(session.State{
+		ID:       stateID,
+		TenantID: tenantID,
+		Nonce:    testNonce,
+		Expiry:   time.Now().Add(time.Hour),
+	}))
+
+	m, err := session.NewManager(t.Context(),
+		&config.SessionManager{
+			SessionDuration:  time.Hour,
+			CallbackURL:      "http://sm.example.com/sm/callback",
+			CSRFSecretParsed: []byte(testCSRFSecret),
+		},
+		newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustv1.Trust_builder{
+			TenantId: new(tenantID),
+			Blocked:  new(false),
+			Oidc: oidcv1.OIDC_builder{
+				Issuer:   new(oidcServer.URL),
+				ClientId: new(testClientID),
+			}.Build(),
+		}.Build()))),
+		sessions,
+		auditLogger,
+		session.WithAllowHttpScheme(true),
+	)
+	require.NoError(t, err)
+
+	before := time.Now()
+	result, err := m.FinaliseOIDCLogin(t.Context(), stateID, "auth-code")
+	require.NoError(t, err)
+
+	sess, err := sessions.LoadSession(t.Context(), result.SessionID)
+	require.NoError(t, err)
+
+	// The test provider issues access tokens which expire in an hour
+	assert.WithinRange(t, sess.AccessTokenExpiry, before.Add(time.Hour), time.Now().Add(time.Hour))
+	assert.True(t, sess.RefreshTokenExpiry.IsZero())
+}
@@ -15,8 +15,37 @@ type Repository interface {
 	ListSessions(ctx context.Context) ([]Session, error)
 	LoadSession(ctx context.Context, sessionID string) (Session, error)
 	LoadSessionByProviderID(ctx context.Context, providerID string) (Session, error)
+	ListSessionsByTenant(ctx context.Context, tenantID string) ([]Session, error)
+	ListSessionsBySubject(ctx context.Context, issuer, subject string) ([]Session, error)
 	StoreSession(ctx context.Context, session Session) error
+	// StoreRefreshedSession stores the session with refreshed tokens only if
+	// the stored refresh token of the session is still the previous one. It
+	// returns serviceerr.ErrConflict if the tokens have been replaced meanwhile.
+	StoreRefreshedSession(ctx context.Context, session Session, previousRefreshToken string) error
 	DeleteSession(ctx context.Context, session Session) error
+	// GetAccessTokenForSession returns the current access token of the session
+	// without loading the whole session.
+	GetAccessTokenForSession(ctx context.Context, sessionID string) (string, error)
 	IsActive(ctx context.Context, sessionID string) (bool, error)
 	BumpActive(ctx context.Context, sessionID string, timeout time.Duration) error
+
+	// Logout token operations
+
+	// StoreLogoutTokenID records the ID of a processed logout token of the issuer
+	// until the given expiry. It returns serviceerr.ErrConflict if the ID has
+	// already been recorded.
+	StoreLogoutTokenID(ctx context.Context, issuer, tokenID string, expiry time.Time) error
+	// DeleteLogoutTokenID forgets the ID of a logout token of the issuer, e.g.
+	// if the logout failed and the issuer should be able to retry it.
+	DeleteLogoutTokenID(ctx context.Context, issuer, tokenID string) error
+
+	// Refresh lock operations
+
+	// AcquireRefreshLock acquires the lock for refreshing the tokens of the
+	// session on behalf of the owner. The lock is released automatically after
+	// the TTL. It returns serviceerr.ErrConflict if the lock is held already.
+	AcquireRefreshLock(ctx context.Context, sessionID, owner string, ttl time.Duration) error
+	// ReleaseRefreshLock releases the lock for refreshing the tokens of the
+	// session if it is held by the owner.
+	ReleaseRefreshLock(ctx context.Context, sessionID, owner string) error
 }
@@ -0,0 +1,160 @@
+package session
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/gofrs/uuid/v5"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+
+	"github.com/openkcm/session-manager/internal/config"
+)
+
+const (
+	requestObjectType     = "oauth-authz-req+jwt"
+	requestObjectLifetime = 5 * time.Minute
+)
+
+// requestObjectSigner wraps the authorisation request parameters into request objects
+// as described in https://datatracker.ietf.org/doc/html/rfc9101.
+type requestObjectSigner struct {
+	signer jose.Signer
+
+	encrypt           bool
+	keyEncryption     jose.KeyAlgorithm
+	contentEncryption jose.ContentEncryption
+}
+
+func newRequestObjectSigner(cfg config.RequestObject) (*requestObjectSigner, error) {
+	key, err := parsePrivateKey(cfg.SigningKeyParsed)
+	if err != nil {
+		return nil, fmt.Errorf("parsing signing key: %w", err)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.SignatureAlgorithm(cfg.SigningAlgorithm),
+		Key:       jose.JSONWebKey{Key: key, KeyID: cfg.SigningKeyID},
+	}, (&jose.SignerOptions{}).WithType(requestObjectType))
+	if err != nil {
+		return nil, fmt.Errorf("creating signer: %w", err)
+	}
+
+	return &requestObjectSigner{
+		signer:            signer,
+		encrypt:           cfg.Encrypt,
+		keyEncryption:     jose.KeyAlgorithm(cfg.KeyEncryptionAlgorithm),
+		contentEncryption: jose.ContentEncryption(cfg.ContentEncryptionAlgorithm),
+	}, nil
+}
+
+// requestObjectParams returns the parameters of an authorisation request passing
+// the given parameters by value in a signed request object. The parameters
+// required by OpenID Connect are kept outside of the request object as well.
+func (m *Manager) requestObjectParams(ctx context.Context, openidConf *openIDConfiguration, params url.Values, oidc *oidcv1.OIDC) (url.Values, error) {
+	now := time.Now()
+	claims := make(map[string]any, len(params)+6)
+	for key := range params {
+		claims[key] = params.Get(key)
+	}
+	// max_age is a number in the request object, see
+	// https://openid.net/specs/openid-connect-core-1_0.html#RequestObject
+	if maxAge, err := strconv.ParseInt(params.Get("max_age"), 10, 64); err == nil {
+		claims["max_age"] = maxAge
+	}
+	claims["iss"] = params.Get("client_id")
+	claims["aud"] = openidConf.Issuer
+	claims["iat"] = jwt.NewNumericDate(now)
+	claims["nbf"] = jwt.NewNumericDate(now)
+	claims["exp"] = jwt.NewNumericDate(now.Add(requestObjectLifetime))
+	claims["jti"] = uuid.Must(uuid.NewV4()).String()
+
+	var (
+		requestObject string
+		err           error
+	)
+	if m.requestObject.encrypt {
+		encrypter, encErr := m.requestObjectEncrypter(ctx, openidConf, oidc)
+		if encErr != nil {
+			return nil, encErr
+		}
+
+		requestObject, err = jwt.SignedAndEncrypted(m.requestObject.signer, encrypter).Claims(claims).Serialize()
+	} else {
+		requestObject, err = jwt.Signed(m.requestObject.signer).Claims(claims).Serialize()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("serializing request object: %w", err)
+	}
+
+	q := url.Values{}
+	q.Set("client_id", params.Get("client_id"))
+	q.Set("response_type", params.Get("response_type"))
+	q.Set("scope", params.Get("scope"))
+	q.Set("request", requestObject)
+
+	return q, nil
+}
+
+// requestObjectEncrypter returns an encrypter for the first encryption key published
+// by the provider which can be used with the configured key management algorithm.
+func (m *Manager) requestObjectEncrypter(ctx context.Context, openidConf *openIDConfiguration, oidc *oidcv1.OIDC) (jose.Encrypter, error) {
+	keySet, err := m.getProviderKeySet(ctx, oidc, openidConf, "")
+	if err != nil {
+		return nil, fmt.Errorf("getting jwks for a provider: %w", err)
+	}
+
+	for _, key := range keySet.Keys {
+		if key.Use != "enc" || (key.Algorithm != "" && key.Algorithm != string(m.requestObject.keyEncryption)) {
+			continue
+		}
+
+		encrypter, err := jose.NewEncrypter(m.requestObject.contentEncryption, jose.Recipient{
+			Algorithm: m.requestObject.keyEncryption,
+			Key:       key.Key,
+			KeyID:     key.KeyID,
+		}, (&jose.EncrypterOptions{}).WithContentType("JWT").WithType("JWT"))
+		if err != nil {
+			return nil, fmt.Errorf("creating encrypter: %w", err)
+		}
+
+		return encrypter, nil
+	}
+
+	return nil, errors.New("no suitable encryption key found in the provider jwks")
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("unsupported private key format")
+}
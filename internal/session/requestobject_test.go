@@ -0,0 +1,204 @@
+package session_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	flowv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/flow/v1"
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+)
+
+func TestManager_Auth_RequestObject(t *testing.T) {
+	const (
+		callbackURL = "http://localhost/sm/callback"
+		tenantID    = "tenant-id"
+	)
+
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signingKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: must(x509.MarshalPKCS8PrivateKey(signingKey))})
+
+	encryptionKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":                 server.URL,
+				"authorization_endpoint": server.URL + "/oauth2/authorize",
+				"jwks_uri":               server.URL + "/.well-known/jwks.json",
+			})
+		case "/.well-known/jwks.json":
+			_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &encryptionKey.PublicKey, KeyID: "enc-key", Use: "enc", Algorithm: string(jose.RSA_OAEP_256)},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	auditServer := StartAuditServer(t)
+	defer auditServer.Close()
+
+	oidc := oidcv1.OIDC_builder{
+		Issuer:   new(server.URL),
+		ClientId: new(testClientID),
+	}.Build()
+	proto.SetExtension(oidc, flowv1.E_AuthAttributes, []*flowv1.Attribute{
+		flowv1.Attribute_builder{Key: new("paramAuth1"), Value: new("valueAuth1")}.Build(),
+	})
+	oidcTrust := trustv1.Trust_builder{
+		TenantId: new(tenantID),
+		Blocked:  new(false),
+		Oidc:     oidc,
+	}.Build()
+
+	tests := []struct {
+		name          string
+		requestObject config.RequestObject
+		wantErr       bool
+	}{
+		{
+			name: "Signed request object",
+			requestObject: config.RequestObject{
+				Enabled:          true,
+				SigningKeyParsed: signingKeyPEM,
+				SigningKeyID:     "sign-key",
+				SigningAlgorithm: string(jose.PS256),
+			},
+		},
+		{
+			name: "Signed and encrypted request object",
+			requestObject: config.RequestObject{
+				Enabled:                    true,
+				SigningKeyParsed:           signingKeyPEM,
+				SigningKeyID:               "sign-key",
+				SigningAlgorithm:           string(jose.PS256),
+				Encrypt:                    true,
+				KeyEncryptionAlgorithm:     string(jose.RSA_OAEP_256),
+				ContentEncryptionAlgorithm: string(jose.A256GCM),
+			},
+		},
+		{
+			name: "Invalid signing key",
+			requestObject: config.RequestObject{
+				Enabled:          true,
+				SigningKeyParsed: []byte("not a key"),
+				SigningAlgorithm: string(jose.PS256),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      callbackURL,
+					CSRFSecretParsed: []byte(testCSRFSecret),
+					RequestObject:    tt.requestObject,
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessionmock.NewInMemRepository(),
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+			)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			got, _, err := m.MakeAuthURI(t.Context(), tenantID, "/ui", "", session.StepUp{MaxAge: new(5 * time.Minute)})
+			require.NoError(t, err)
+
+			u, err := url.Parse(got)
+			require.NoError(t, err)
+
+			q := u.Query()
+			assert.Equal(t, testClientID, q.Get("client_id"))
+			assert.Equal(t, "code", q.Get("response_type"))
+			assert.Equal(t, "openid profile email groups", q.Get("scope"))
+			assert.Empty(t, q.Get("state"), "state must only be passed in the request object")
+			assert.Empty(t, q.Get("paramAuth1"), "auth attributes must only be passed in the request object")
+
+			var token *jwt.JSONWebToken
+			if tt.requestObject.Encrypt {
+				jwe, err := jose.ParseEncrypted(q.Get("request"),
+					[]jose.KeyAlgorithm{jose.RSA_OAEP_256},
+					[]jose.ContentEncryption{jose.A256GCM})
+				require.NoError(t, err)
+				assert.Equal(t, "enc-key", jwe.Header.KeyID)
+
+				nested, err := jwe.Decrypt(encryptionKey)
+				require.NoError(t, err)
+
+				token, err = jwt.ParseSigned(string(nested), []jose.SignatureAlgorithm{jose.PS256})
+				require.NoError(t, err)
+			} else {
+				token, err = jwt.ParseSigned(q.Get("request"), []jose.SignatureAlgorithm{jose.PS256})
+				require.NoError(t, err)
+			}
+			assert.Equal(t, "sign-key", token.Headers[0].KeyID)
+
+			var claims struct {
+				jwt.Claims
+
+				State         string `json:"state"`
+				CodeChallenge string `json:"code_challenge"`
+				RedirectURI   string `json:"redirect_uri"`
+				ParamAuth1    string `json:"paramAuth1"`
+				MaxAge        int64  `json:"max_age"`
+			}
+			require.NoError(t, token.Claims(&signingKey.PublicKey, &claims))
+
+			require.NoError(t, claims.Validate(jwt.Expected{
+				Issuer:      testClientID,
+				AnyAudience: jwt.Audience{server.URL},
+				Time:        time.Now(),
+			}))
+			assert.NotEmpty(t, claims.ID)
+			assert.NotEmpty(t, claims.State)
+			assert.NotEmpty(t, claims.CodeChallenge)
+			assert.Equal(t, callbackURL, claims.RedirectURI)
+			assert.Equal(t, "valueAuth1", claims.ParamAuth1)
+			assert.Equal(t, int64(300), claims.MaxAge)
+		})
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
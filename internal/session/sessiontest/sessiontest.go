@@ -0,0 +1,284 @@
+// Package sessiontest provides a test suite which verifies that an
+// implementation of session.Repository behaves like the Valkey store.
+package sessiontest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+// TestRepository runs the test suite against the repositories returned by
+// newRepository. Repositories may share their storage as all tests use unique
+// IDs.
+func TestRepository(t *testing.T, newRepository func(t *testing.T) session.Repository) {
+	t.Helper()
+
+	t.Run("State", func(t *testing.T) { testState(t, newRepository(t)) })
+	t.Run("Session", func(t *testing.T) { testSession(t, newRepository(t)) })
+	t.Run("ListSessions", func(t *testing.T) { testListSessions(t, newRepository(t)) })
+	t.Run("StoreRefreshedSession", func(t *testing.T) { testStoreRefreshedSession(t, newRepository(t)) })
+	t.Run("Active", func(t *testing.T) { testActive(t, newRepository(t)) })
+	t.Run("LogoutTokenID", func(t *testing.T) { testLogoutTokenID(t, newRepository(t)) })
+	t.Run("RefreshLock", func(t *testing.T) { testRefreshLock(t, newRepository(t)) })
+}
+
+// uniqueID returns an ID which is unique across test runs sharing a storage.
+func uniqueID(name string) string {
+	return name + "-" + uuid.Must(uuid.NewV4()).String()
+}
+
+// newSession returns a session with the given tenant and subject which
+// expires in an hour.
+func newSession(tenantID, issuer, subject string) session.Session {
+	id := uniqueID("session")
+	return session.Session{
+		ID:              id,
+		TenantID:        tenantID,
+		ProviderID:      uniqueID("provider"),
+		ProviderSubject: subject,
+		CSRFToken:       "csrf-token-" + id,
+		Issuer:          issuer,
+		Claims: session.Claims{
+			Subject: subject,
+			Email:   subject + "@example.com",
+			Groups:  []string{"users"},
+		},
+		AccessToken:       "access-token-" + id,
+		RefreshToken:      "refresh-token-" + id,
+		Expiry:            time.Now().Add(time.Hour).Truncate(time.Millisecond),
+		AccessTokenExpiry: time.Now().Add(5 * time.Minute).Truncate(time.Millisecond),
+		AuthContext:       map[string]string{"key": "value"},
+	}
+}
+
+func sessionIDs(sessions []session.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func assertSessionEqual(t *testing.T, want, got session.Session) {
+	t.Helper()
+
+	assert.True(t, want.Expiry.Equal(got.Expiry), "expiry: want %s, got %s", want.Expiry, got.Expiry)
+	assert.True(t, want.AccessTokenExpiry.Equal(got.AccessTokenExpiry), "access token expiry: want %s, got %s", want.AccessTokenExpiry, got.AccessTokenExpiry)
+	want.Expiry, got.Expiry = time.Time{}, time.Time{}
+	want.AccessTokenExpiry, got.AccessTokenExpiry = time.Time{}, time.Time{}
+	assert.Equal(t, want, got)
+}
+
+func testState(t *testing.T, r session.Repository) {
+	ctx := t.Context()
+
+	state := session.State{
+		ID:           uniqueID("state"),
+		TenantID:     "tenant-id",
+		PKCEVerifier: "verifier",
+		RequestURI:   "https://example.com",
+		Expiry:       time.Now().Add(time.Hour).Truncate(time.Millisecond),
+	}
+
+	_, err := r.LoadState(ctx, state.ID)
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+
+	require.NoError(t, r.StoreState(ctx, state))
+	loaded, err := r.LoadState(ctx, state.ID)
+	require.NoError(t, err)
+	assert.Equal(t, state.PKCEVerifier, loaded.PKCEVerifier)
+	assert.Equal(t, state.RequestURI, loaded.RequestURI)
+	assert.True(t, state.Expiry.Equal(loaded.Expiry))
+
+	state.RequestURI = "https://example.com/updated"
+	require.NoError(t, r.StoreState(ctx, state))
+	loaded, err = r.LoadState(ctx, state.ID)
+	require.NoError(t, err)
+	assert.Equal(t, state.RequestURI, loaded.RequestURI, "stored states must be replaced")
+
+	require.NoError(t, r.DeleteState(ctx, state.ID))
+	_, err = r.LoadState(ctx, state.ID)
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+}
+
+func testSession(t *testing.T, r session.Repository) {
+	ctx := t.Context()
+
+	sess := newSession(uniqueID("tenant"), "https://issuer.example.com", uniqueID("subject"))
+
+	_, err := r.LoadSession(ctx, sess.ID)
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+
+	require.NoError(t, r.StoreSession(ctx, sess))
+
+	loaded, err := r.LoadSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assertSessionEqual(t, sess, loaded)
+
+	loaded, err = r.LoadSessionByProviderID(ctx, sess.ProviderID)
+	require.NoError(t, err)
+	assert.Equal(t, sess.ID, loaded.ID)
+
+	accessToken, err := r.GetAccessTokenForSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sess.AccessToken, accessToken)
+
+	require.NoError(t, r.DeleteSession(ctx, sess))
+
+	_, err = r.LoadSession(ctx, sess.ID)
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+	_, err = r.LoadSessionByProviderID(ctx, sess.ProviderID)
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+	_, err = r.GetAccessTokenForSession(ctx, sess.ID)
+	require.ErrorIs(t, err, serviceerr.ErrNotFound)
+}
+
+func testListSessions(t *testing.T, r session.Repository) {
+	ctx := t.Context()
+
+	tenantA, tenantB := uniqueID("tenant-a"), uniqueID("tenant-b")
+	issuer := "https://issuer.example.com"
+	alice, bob := uniqueID("alice"), uniqueID("bob")
+
+	sessions := []session.Session{
+		newSession(tenantA, issuer, alice),
+		newSession(tenantA, issuer, bob),
+		newSession(tenantB, issuer, alice),
+		newSession(tenantB, "https://other-issuer.example.com", alice),
+	}
+	for _, s := range sessions {
+		require.NoError(t, r.StoreSession(ctx, s))
+	}
+
+	all, err := r.ListSessions(ctx)
+	require.NoError(t, err)
+	assert.Subset(t, sessionIDs(all), sessionIDs(sessions))
+
+	byTenant, err := r.ListSessionsByTenant(ctx, tenantA)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{sessions[0].ID, sessions[1].ID}, sessionIDs(byTenant))
+
+	bySubject, err := r.ListSessionsBySubject(ctx, issuer, alice)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{sessions[0].ID, sessions[2].ID}, sessionIDs(bySubject))
+
+	require.NoError(t, r.DeleteSession(ctx, sessions[0]))
+
+	byTenant, err = r.ListSessionsByTenant(ctx, tenantA)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{sessions[1].ID}, sessionIDs(byTenant))
+
+	bySubject, err = r.ListSessionsBySubject(ctx, issuer, alice)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{sessions[2].ID}, sessionIDs(bySubject))
+
+	byTenant, err = r.ListSessionsByTenant(ctx, uniqueID("unknown-tenant"))
+	require.NoError(t, err)
+	assert.Empty(t, byTenant)
+}
+
+func testStoreRefreshedSession(t *testing.T, r session.Repository) {
+	ctx := t.Context()
+
+	sess := newSession(uniqueID("tenant"), "https://issuer.example.com", uniqueID("subject"))
+	require.NoError(t, r.StoreSession(ctx, sess))
+
+	refreshed := sess
+	refreshed.AccessToken = "new-access-token"
+	refreshed.RefreshToken = "new-refresh-token"
+	require.NoError(t, r.StoreRefreshedSession(ctx, refreshed, sess.RefreshToken))
+
+	concurrent := sess
+	concurrent.AccessToken = "concurrent-access-token"
+	concurrent.RefreshToken = "concurrent-refresh-token"
+	err := r.StoreRefreshedSession(ctx, concurrent, sess.RefreshToken)
+	require.ErrorIs(t, err, serviceerr.ErrConflict, "replaced tokens must not be overwritten")
+
+	loaded, err := r.LoadSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new-access-token", loaded.AccessToken)
+	assert.Equal(t, "new-refresh-token", loaded.RefreshToken)
+
+	accessToken, err := r.GetAccessTokenForSession(ctx, sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new-access-token", accessToken)
+
+	deleted := newSession(uniqueID("tenant"), "https://issuer.example.com", uniqueID("subject"))
+	err = r.StoreRefreshedSession(ctx, deleted, deleted.RefreshToken)
+	require.ErrorIs(t, err, serviceerr.ErrConflict, "deleted sessions must not be recreated")
+}
+
+func testActive(t *testing.T, r session.Repository) {
+	ctx := t.Context()
+
+	sessionID := uniqueID("session")
+
+	active, err := r.IsActive(ctx, sessionID)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	require.NoError(t, r.BumpActive(ctx, sessionID, 2*time.Second))
+	active, err = r.IsActive(ctx, sessionID)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	require.Eventually(t, func() bool {
+		active, err := r.IsActive(ctx, sessionID)
+		return err == nil && !active
+	}, 5*time.Second, 100*time.Millisecond, "session must become idle after the timeout")
+}
+
+func testLogoutTokenID(t *testing.T, r session.Repository) {
+	ctx := t.Context()
+
+	tokenID := uniqueID("jti")
+	expiry := time.Now().Add(time.Minute)
+
+	require.NoError(t, r.StoreLogoutTokenID(ctx, "https://issuer-one.example.com", tokenID, expiry))
+
+	err := r.StoreLogoutTokenID(ctx, "https://issuer-one.example.com", tokenID, expiry)
+	require.ErrorIs(t, err, serviceerr.ErrConflict, "replayed token ID must be rejected")
+
+	err = r.StoreLogoutTokenID(ctx, "https://issuer-two.example.com", tokenID, expiry)
+	require.NoError(t, err, "token IDs are unique per issuer")
+
+	require.NoError(t, r.DeleteLogoutTokenID(ctx, "https://issuer-one.example.com", tokenID))
+	err = r.StoreLogoutTokenID(ctx, "https://issuer-one.example.com", tokenID, expiry)
+	require.NoError(t, err, "deleted token ID must be stored again")
+
+	err = r.StoreLogoutTokenID(ctx, "https://issuer-two.example.com", tokenID, expiry)
+	require.ErrorIs(t, err, serviceerr.ErrConflict, "token IDs of other issuers must not be deleted")
+}
+
+func testRefreshLock(t *testing.T, r session.Repository) {
+	ctx := t.Context()
+
+	sessionOne, sessionTwo := uniqueID("session-one"), uniqueID("session-two")
+
+	require.NoError(t, r.AcquireRefreshLock(ctx, sessionOne, "owner-one", time.Minute))
+
+	err := r.AcquireRefreshLock(ctx, sessionOne, "owner-two", time.Minute)
+	require.ErrorIs(t, err, serviceerr.ErrConflict, "held lock must not be acquired")
+
+	require.NoError(t, r.AcquireRefreshLock(ctx, sessionTwo, "owner-two", time.Minute), "locks are held per session")
+
+	require.NoError(t, r.ReleaseRefreshLock(ctx, sessionOne, "owner-two"))
+	err = r.AcquireRefreshLock(ctx, sessionOne, "owner-two", time.Minute)
+	require.ErrorIs(t, err, serviceerr.ErrConflict, "lock must only be released by its owner")
+
+	require.NoError(t, r.ReleaseRefreshLock(ctx, sessionOne, "owner-one"))
+	require.NoError(t, r.AcquireRefreshLock(ctx, sessionOne, "owner-two", time.Minute), "released lock must be acquired")
+
+	expiring := uniqueID("session-expiring")
+	require.NoError(t, r.AcquireRefreshLock(ctx, expiring, "owner-one", time.Second))
+	require.Eventually(t, func() bool {
+		return r.AcquireRefreshLock(ctx, expiring, "owner-two", time.Minute) == nil
+	}, 5*time.Second, 100*time.Millisecond, "expired lock must be acquired")
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+// Identifiers defined by RFC 8693
+// https://www.rfc-editor.org/rfc/rfc8693.html#section-3
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+const (
+	// defaultExchangedTokenCacheExpiration is the upper bound for caching exchanged tokens.
+	defaultExchangedTokenCacheExpiration = time.Hour
+	// exchangedTokenExpiryLeeway stops serving cached tokens shortly before they expire.
+	exchangedTokenExpiryLeeway = 30 * time.Second
+)
+
+// ExchangedToken is a token issued by the provider for a downstream audience
+// on behalf of the user of a session.
+type ExchangedToken struct {
+	AccessToken     string
+	IssuedTokenType string
+	TokenType       string
+	Expiry          time.Time // Expiry time of the token (zero if unknown)
+}
+
+// cachedExchangedToken is an exchanged token along with the access token of
+// the session it has been exchanged for.
+type cachedExchangedToken struct {
+	subjectToken string
+	token        ExchangedToken
+}
+
+// ExchangeToken exchanges the access token of the session for a token of the
+// given audience as described in https://www.rfc-editor.org/rfc/rfc8693.html.
+// The tokens are cached per session and audience until shortly before they
+// expire or the access token of the session changes.
+func (m *Manager) ExchangeToken(ctx context.Context, s Session, audience string) (ExchangedToken, error) {
+	if audience == "" || s.AccessToken == "" {
+		return ExchangedToken{}, serviceerr.ErrInvalidRequest
+	}
+
+	cacheKey := s.ID + " " + audience
+	if item := m.exchangedTokenCache.Get(cacheKey); item != nil && item.Value().subjectToken == s.AccessToken {
+		return item.Value().token, nil
+	}
+
+	trust, err := m.trust.Get(ctx, s.TenantID)
+	if err != nil {
+		return ExchangedToken{}, fmt.Errorf("getting trust: %w", err)
+	}
+
+	oidc := trust.GetOidc()
+
+	openidConf, err := m.getOpenIDConfig(ctx, oidc.GetIssuer())
+	if err != nil {
+		return ExchangedToken{}, fmt.Errorf("getting an openid config: %w", err)
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", grantTypeTokenExchange)
+	data.Set("subject_token", s.AccessToken)
+	data.Set("subject_token_type", tokenTypeAccessToken)
+	data.Set("requested_token_type", tokenTypeAccessToken)
+	data.Set("audience", audience)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, openidConf.TokenEndpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return ExchangedToken{}, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client, err := m.httpClient(oidc.GetClientId())
+	if err != nil {
+		return ExchangedToken{}, fmt.Errorf("creating http client: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ExchangedToken{}, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ExchangedToken{}, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp serviceerr.Error
+		if err := json.Unmarshal(body, &errResp); err == nil {
+			switch errResp.Err {
+			case serviceerr.CodeInvalidGrant:
+				return ExchangedToken{}, fmt.Errorf("subject token rejected: %w", serviceerr.ErrInvalidGrant)
+			case serviceerr.CodeInvalidTarget:
+				return ExchangedToken{}, fmt.Errorf("audience %q rejected: %w", audience, serviceerr.ErrInvalidTarget)
+			}
+		}
+
+		return ExchangedToken{}, fmt.Errorf("token exchange failed with status: %d", resp.StatusCode)
+	}
+
+	var tokens tokenResponse
+	err = json.Unmarshal(body, &tokens)
+	if err != nil {
+		return ExchangedToken{}, fmt.Errorf("decoding response: %w", err)
+	}
+	if tokens.AccessToken == "" {
+		return ExchangedToken{}, errors.New("token exchange response without access token")
+	}
+
+	token := ExchangedToken{
+		AccessToken:     tokens.AccessToken,
+		IssuedTokenType: tokens.IssuedTokenType,
+		TokenType:       tokens.TokenType,
+	}
+	token.Expiry, _ = tokens.expiries(time.Now())
+
+	// Tokens without a known expiry are not cached
+	ttl := min(time.Until(token.Expiry)-exchangedTokenExpiryLeeway, defaultExchangedTokenCacheExpiration)
+	if ttl > 0 {
+		m.exchangedTokenCache.Set(cacheKey, cachedExchangedToken{subjectToken: s.AccessToken, token: token}, ttl)
+	} else {
+		slogctx.Debug(ctx, "Not caching the exchanged token", "audience", audience)
+	}
+
+	return token, nil
+}
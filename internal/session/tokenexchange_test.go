@@ -0,0 +1,192 @@
+package session_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+)
+
+// startTokenExchangeServer starts an OIDC provider which exchanges access
+// tokens for tokens of the requested audience and records the exchange requests.
+func startTokenExchangeServer(t *testing.T, expiresIn int) (*httptest.Server, func() []url.Values) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []url.Values
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":         server.URL,
+				"token_endpoint": server.URL + "/token",
+			})
+		case "/token":
+			_ = r.ParseForm()
+			mu.Lock()
+			requests = append(requests, r.PostForm)
+			mu.Unlock()
+
+			if r.PostForm.Get("audience") == "unknown-service" {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": "invalid_target"})
+				return
+			}
+			if r.PostForm.Get("subject_token") == "revoked-access-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": "invalid_grant"})
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token":      r.PostForm.Get("audience") + ":" + r.PostForm.Get("subject_token"),
+				"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+				"token_type":        "Bearer",
+				"expires_in":        expiresIn,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server, func() []url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestManager_ExchangeToken(t *testing.T) {
+	const tenantID = "test-tenant"
+
+	sess := session.Session{
+		ID:          "test-session-id",
+		TenantID:    tenantID,
+		AccessToken: "access-token",
+		Expiry:      time.Now().Add(time.Hour),
+	}
+
+	t.Run("Exchanges the access token", func(t *testing.T) {
+		server, requests := startTokenExchangeServer(t, 300)
+		defer server.Close()
+
+		m := newRefreshManager(t, server.URL, tenantID, sessionmock.NewInMemRepository(), nil)
+
+		before := time.Now()
+		token, err := m.ExchangeToken(t.Context(), sess, "orders-service")
+		require.NoError(t, err)
+
+		assert.Equal(t, "orders-service:access-token", token.AccessToken)
+		assert.Equal(t, "urn:ietf:params:oauth:token-type:access_token", token.IssuedTokenType)
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.WithinRange(t, token.Expiry, before.Add(300*time.Second), time.Now().Add(300*time.Second))
+
+		require.Len(t, requests(), 1)
+		form := requests()[0]
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", form.Get("grant_type"))
+		assert.Equal(t, "access-token", form.Get("subject_token"))
+		assert.Equal(t, "urn:ietf:params:oauth:token-type:access_token", form.Get("subject_token_type"))
+		assert.Equal(t, "urn:ietf:params:oauth:token-type:access_token", form.Get("requested_token_type"))
+		assert.Equal(t, "orders-service", form.Get("audience"))
+	})
+
+	t.Run("Caches tokens per session and audience", func(t *testing.T) {
+		server, requests := startTokenExchangeServer(t, 300)
+		defer server.Close()
+
+		m := newRefreshManager(t, server.URL, tenantID, sessionmock.NewInMemRepository(), nil)
+
+		for range 2 {
+			token, err := m.ExchangeToken(t.Context(), sess, "orders-service")
+			require.NoError(t, err)
+			assert.Equal(t, "orders-service:access-token", token.AccessToken)
+		}
+		assert.Len(t, requests(), 1, "token must be served from the cache")
+
+		token, err := m.ExchangeToken(t.Context(), sess, "billing-service")
+		require.NoError(t, err)
+		assert.Equal(t, "billing-service:access-token", token.AccessToken)
+		assert.Len(t, requests(), 2, "tokens must be cached per audience")
+
+		otherSession := sess
+		otherSession.ID = "other-session-id"
+		_, err = m.ExchangeToken(t.Context(), otherSession, "orders-service")
+		require.NoError(t, err)
+		assert.Len(t, requests(), 3, "tokens must be cached per session")
+
+		refreshed := sess
+		refreshed.AccessToken = "refreshed-access-token"
+		token, err = m.ExchangeToken(t.Context(), refreshed, "orders-service")
+		require.NoError(t, err)
+		assert.Equal(t, "orders-service:refreshed-access-token", token.AccessToken)
+		assert.Len(t, requests(), 4, "refreshed access tokens must be exchanged again")
+	})
+
+	t.Run("Does not cache short-lived tokens", func(t *testing.T) {
+		server, requests := startTokenExchangeServer(t, 10)
+		defer server.Close()
+
+		m := newRefreshManager(t, server.URL, tenantID, sessionmock.NewInMemRepository(), nil)
+
+		for range 2 {
+			_, err := m.ExchangeToken(t.Context(), sess, "orders-service")
+			require.NoError(t, err)
+		}
+		assert.Len(t, requests(), 2)
+	})
+
+	errorTests := []struct {
+		name        string
+		accessToken string
+		audience    string
+		wantErr     error
+	}{
+		{
+			name:        "Missing audience",
+			accessToken: "access-token",
+			wantErr:     serviceerr.ErrInvalidRequest,
+		},
+		{
+			name:     "Missing access token",
+			audience: "orders-service",
+			wantErr:  serviceerr.ErrInvalidRequest,
+		},
+		{
+			name:        "Audience rejected",
+			accessToken: "access-token",
+			audience:    "unknown-service",
+			wantErr:     serviceerr.ErrInvalidTarget,
+		},
+		{
+			name:        "Access token rejected",
+			accessToken: "revoked-access-token",
+			audience:    "orders-service",
+			wantErr:     serviceerr.ErrInvalidGrant,
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := startTokenExchangeServer(t, 300)
+			defer server.Close()
+
+			m := newRefreshManager(t, server.URL, tenantID, sessionmock.NewInMemRepository(), nil)
+
+			s := sess
+			s.AccessToken = tt.accessToken
+			_, err := m.ExchangeToken(t.Context(), s, tt.audience)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"google.golang.org/protobuf/proto"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
+)
+
+// userInfoClaimMapping merges the standard claims of a UserInfo response into
+// the claims of a session. The subject is verified rather than mapped.
+var userInfoClaimMapping = &ClaimMapping{
+	userUUID:   claimPath{"user_uuid"},
+	givenName:  claimPath{"given_name"},
+	familyName: claimPath{"family_name"},
+	email:      claimPath{"email"},
+	groups:     claimPath{"groups"},
+}
+
+func fetchUserInfo(oidc *oidcv1.OIDC) bool {
+	//nolint:forcetypeassert
+	return proto.GetExtension(oidc, smoidcv1.E_FetchUserinfo).(bool)
+}
+
+// getUserInfo requests the claims about the authenticated user from the UserInfo
+// endpoint as described in https://openid.net/specs/openid-connect-core-1_0.html#UserInfo.
+func (m *Manager) getUserInfo(ctx context.Context, openidConf *openIDConfiguration, accessToken string, oidc *oidcv1.OIDC) (map[string]any, error) {
+	if openidConf.UserinfoEndpoint == "" {
+		return nil, errors.New("provider does not advertise a userinfo endpoint")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, openidConf.UserinfoEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	client, err := m.httpClient(oidc.GetClientId())
+	if err != nil {
+		return nil, fmt.Errorf("creating http client: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo request failed with status: %d", resp.StatusCode)
+	}
+
+	// Signed or encrypted UserInfo responses are not supported
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return nil, fmt.Errorf("unsupported userinfo content type: %q", resp.Header.Get("Content-Type"))
+	}
+
+	var userInfo map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&userInfo)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return userInfo, nil
+}
@@ -0,0 +1,166 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
+)
+
+func TestManager_FinaliseOIDCLogin_UserInfo(t *testing.T) {
+	const (
+		tenantID = "tenant-id"
+		stateID  = "test-state-id"
+	)
+
+	tests := []struct {
+		name          string
+		fetchUserInfo bool
+		claimMapping  *smoidcv1.ClaimMapping
+		claims        map[string]any
+		userInfo      map[string]any
+		wantClaims    session.Claims
+		wantErr       error
+		wantErrMsg    string
+	}{
+		{
+			name:       "Disabled",
+			claims:     map[string]any{"email": "id-token@example.com"},
+			userInfo:   map[string]any{"sub": "jwt-test", "email": "userinfo@example.com"},
+			wantClaims: session.Claims{Subject: "jwt-test", Email: "id-token@example.com"},
+		},
+		{
+			name:          "Thin ID token is enriched",
+			fetchUserInfo: true,
+			userInfo: map[string]any{
+				"sub":         "jwt-test",
+				"given_name":  "Jane",
+				"family_name": "Doe",
+				"email":       "jane.doe@example.com",
+				"groups":      []string{"viewer", "editor"},
+			},
+			wantClaims: session.Claims{
+				Subject:    "jwt-test",
+				GivenName:  "Jane",
+				FamilyName: "Doe",
+				Email:      "jane.doe@example.com",
+				Groups:     []string{"viewer", "editor"},
+			},
+		},
+		{
+			name:          "UserInfo claims take precedence",
+			fetchUserInfo: true,
+			claims:        map[string]any{"given_name": "Jane", "email": "old@example.com"},
+			userInfo:      map[string]any{"sub": "jwt-test", "email": "new@example.com"},
+			wantClaims:    session.Claims{Subject: "jwt-test", GivenName: "Jane", Email: "new@example.com"},
+		},
+		{
+			name:          "Claim mapping applies to UserInfo claims",
+			fetchUserInfo: true,
+			claimMapping:  &smoidcv1.ClaimMapping{Groups: new("realm_access.roles")},
+			userInfo: map[string]any{
+				"sub":          "jwt-test",
+				"realm_access": map[string]any{"roles": []string{"key-admin"}},
+			},
+			wantClaims: session.Claims{Subject: "jwt-test", Groups: []string{"key-admin"}},
+		},
+		{
+			name:          "Subject mismatch",
+			fetchUserInfo: true,
+			userInfo:      map[string]any{"sub": "someone-else", "email": "jane.doe@example.com"},
+			wantErr:       serviceerr.ErrInvalidUserInfoSubject,
+		},
+		{
+			name:          "Missing subject",
+			fetchUserInfo: true,
+			userInfo:      map[string]any{"email": "jane.doe@example.com"},
+			wantErr:       serviceerr.ErrInvalidUserInfoSubject,
+		},
+		{
+			name:          "Endpoint not advertised",
+			fetchUserInfo: true,
+			wantErrMsg:    "does not advertise a userinfo endpoint",
+		},
+		{
+			name:          "Invalid claim type",
+			fetchUserInfo: true,
+			userInfo:      map[string]any{"sub": "jwt-test", "email": true},
+			wantErrMsg:    "merging userinfo claims",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oidcServer := StartOIDCServerWithUserInfo(t, false, tt.claims, tt.userInfo)
+			defer oidcServer.Close()
+
+			auditServer := StartAuditServer(t)
+			defer auditServer.Close()
+
+			auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+			require.NoError(t, err)
+
+			oidc := oidcv1.OIDC_builder{
+				Issuer:   new(oidcServer.URL),
+				ClientId: new(testClientID),
+			}.Build()
+			proto.SetExtension(oidc, smoidcv1.E_FetchUserinfo, tt.fetchUserInfo)
+			if tt.claimMapping != nil {
+				proto.SetExtension(oidc, smoidcv1.E_ClaimMapping, tt.claimMapping)
+			}
+			oidcTrust := trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(false),
+				Oidc:     oidc,
+			}.Build()
+
+			sessions := sessionmock.NewInMemRepository(sessionmock.WithState(session.State{
+				ID:       stateID,
+				TenantID: tenantID,
+				Nonce:    testNonce,
+				Expiry:   time.Now().Add(time.Hour),
+			}))
+
+			m, err := session.NewManager(t.Context(),
+				&config.SessionManager{
+					SessionDuration:  time.Hour,
+					CallbackURL:      "http://sm.example.com/sm/callback",
+					CSRFSecretParsed: []byte(testCSRFSecret),
+				},
+				newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(oidcTrust))),
+				sessions,
+				auditLogger,
+				session.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			result, err := m.FinaliseOIDCLogin(t.Context(), stateID, "auth-code")
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			case tt.wantErrMsg != "":
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			sess, err := sessions.LoadSession(t.Context(), result.SessionID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClaims, sess.Claims)
+		})
+	}
+}
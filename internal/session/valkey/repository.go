@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/valkey-io/valkey-go"
@@ -24,6 +25,12 @@ const (
 	objectTypeRefreshToken    ObjectType = "refreshToken"
 	objectTypeProviderToken   ObjectType = "providerToken"
 	objectTypeActive          ObjectType = "active"
+	objectTypeLogoutToken     ObjectType = "logoutToken"
+	objectTypeRefreshLock     ObjectType = "refreshLock"
+
+	// Sorted sets of session IDs scored by the session expiry
+	objectTypeTenantSessions  ObjectType = "tenantSessions"
+	objectTypeSubjectSessions ObjectType = "subjectSessions"
 )
 
 var (
@@ -35,6 +42,9 @@ var (
 	ErrGetSessIDByProviderID = errors.New("getting session ID by provider ID from store")
 	ErrGetAccessToken        = errors.New("getting access token from store")
 	ErrGetRefreshToken       = errors.New("getting refresh token from store")
+	ErrStoreLogoutTokenID    = errors.New("setting logout token ID into storage")
+	ErrAcquireRefreshLock    = errors.New("acquiring refresh lock")
+	ErrReleaseRefreshLock    = errors.New("releasing refresh lock")
 )
 
 type Repository struct {
@@ -103,6 +113,73 @@ func (r *Repository) LoadSessionByProviderID(ctx context.Context, providerID str
 	return sess, nil
 }
 
+// ListSessionsByTenant returns the sessions of the tenant.
+func (r *Repository) ListSessionsByTenant(ctx context.Context, tenantID string) ([]session.Session, error) {
+	return r.listIndexedSessions(ctx, r.tenantIndexKey(tenantID))
+}
+
+// ListSessionsBySubject returns the sessions of the subject defined by the issuer.
+func (r *Repository) ListSessionsBySubject(ctx context.Context, issuer, subject string) ([]session.Session, error) {
+	return r.listIndexedSessions(ctx, r.subjectIndexKey(issuer, subject))
+}
+
+// listIndexedSessions returns the unexpired sessions of the index. Members of
+// sessions which have been removed otherwise, e.g. expired with a shorter TTL,
+// are removed from the index.
+func (r *Repository) listIndexedSessions(ctx context.Context, indexKey string) ([]session.Session, error) {
+	client := r.store.valkey
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	sessionIDs, err := client.Do(ctx, client.B().Zrange().Key(indexKey).Min(now).Max("+inf").Byscore().Build()).AsStrSlice()
+	if err != nil {
+		return nil, errors.Join(ErrGetSessions, err)
+	}
+	if len(sessionIDs) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		keys = append(keys, r.store.key(objectTypeSession, id))
+	}
+	values, err := valkey.MGet(client, ctx, keys)
+	if err != nil {
+		return nil, errors.Join(ErrGetSessions, err)
+	}
+
+	sessions := make([]session.Session, 0, len(sessionIDs))
+	var stale []string
+	for i, key := range keys {
+		msg, ok := values[key]
+		if !ok {
+			stale = append(stale, sessionIDs[i])
+			continue
+		}
+		bytes, err := msg.AsBytes()
+		if valkey.IsValkeyNil(err) {
+			stale = append(stale, sessionIDs[i])
+			continue
+		}
+		if err != nil {
+			return nil, errors.Join(ErrGetSessions, err)
+		}
+
+		var s session.Session
+		if err := r.store.decode(bytes, &s); err != nil {
+			return nil, errors.Join(ErrGetSessions, err)
+		}
+		sessions = append(sessions, s)
+	}
+
+	if len(stale) > 0 {
+		err := client.Do(ctx, client.B().Zrem().Key(indexKey).Member(stale...).Build()).Error()
+		if err != nil {
+			slogctx.Warn(ctx, "Could not remove stale members from the session index", "error", err)
+		}
+	}
+
+	return sessions, nil
+}
+
 func (r *Repository) GetSessIDByProviderID(ctx context.Context, providerID string) (string, error) {
 	var s string
 	err := r.store.Get(ctx, objectTypeProviderSession, getObjectID(objectTypeProviderSession, providerID), &s)
@@ -133,41 +210,122 @@ func (r *Repository) GetRefreshTokenForSession(ctx context.Context, sessionID st
 	return refreshToken, nil
 }
 
+// StoreSession stores the session along with its provider session, access
+// token and refresh token objects and indexes it in a single transaction.
 func (r *Repository) StoreSession(ctx context.Context, s session.Session) error {
-	duration := time.Until(s.Expiry)
-	var errs []error
-	err := r.store.Set(ctx, objectTypeProviderSession, getObjectID(objectTypeProviderSession, s.ProviderID), s.ID, duration)
-	if err != nil {
-		errs = append(errs, err)
+	ttl := time.Until(s.Expiry)
+	if ttl < time.Millisecond {
+		// Invalid expiry times only fail at execution and would not abort the transaction
+		return errors.Join(ErrStoreSession, fmt.Errorf("session has expired at %s", s.Expiry))
 	}
 
-	err = r.store.Set(ctx, objectTypeAccessToken, getObjectID(objectTypeAccessToken, s.ID), s.AccessToken, duration)
-	if err != nil {
-		errs = append(errs, err)
+	cmds := make(valkey.Commands, 0, 5)
+	values := []struct {
+		key string
+		val any
+	}{
+		{r.store.key(objectTypeProviderSession, getObjectID(objectTypeProviderSession, s.ProviderID)), s.ID},
+		{r.store.key(objectTypeAccessToken, getObjectID(objectTypeAccessToken, s.ID)), s.AccessToken},
+		{r.store.key(objectTypeRefreshToken, getObjectID(objectTypeRefreshToken, s.ID)), s.RefreshToken},
+		{r.store.key(objectTypeSession, s.ID), s},
+	}
+	for _, v := range values {
+		bytes, err := r.store.encode(v.val)
+		if err != nil {
+			return errors.Join(ErrStoreSession, err)
+		}
+		cmds = append(cmds, r.store.valkey.B().Set().Key(v.key).Value(valkey.BinaryString(bytes)).Px(ttl).Build())
 	}
+	cmds = append(cmds, r.indexSessionCommand(s))
 
-	err = r.store.Set(ctx, objectTypeRefreshToken, getObjectID(objectTypeRefreshToken, s.ID), s.RefreshToken, duration)
-	if err != nil {
-		errs = append(errs, err)
+	if err := r.store.transaction(ctx, cmds...); err != nil {
+		// Remove the objects written by the commands which have not failed
+		if err := r.DeleteSession(ctx, s); err != nil {
+			slogctx.Error(ctx, "couldn't delete session during rollback", "error", err)
+		}
+		return errors.Join(ErrStoreSession, err)
 	}
 
-	err = r.store.Set(ctx, objectTypeSession, s.ID, s, duration)
-	if err != nil {
-		errs = append(errs, err)
+	return nil
+}
+
+// storeRefreshedSessionScript sets the refresh token, the access token and the
+// session objects only if the refresh token object holds the previous token.
+var storeRefreshedSessionScript = valkey.NewLuaScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[5])
+redis.call("SET", KEYS[2], ARGV[3], "PX", ARGV[5])
+redis.call("SET", KEYS[3], ARGV[4], "PX", ARGV[5])
+return 1`)
+
+func (r *Repository) StoreRefreshedSession(ctx context.Context, s session.Session, previousRefreshToken string) error {
+	keys := []string{
+		r.store.key(objectTypeRefreshToken, getObjectID(objectTypeRefreshToken, s.ID)),
+		r.store.key(objectTypeAccessToken, getObjectID(objectTypeAccessToken, s.ID)),
+		r.store.key(objectTypeSession, s.ID),
 	}
 
-	if len(errs) > 0 {
-		err := r.DeleteSession(ctx, s)
+	args := make([]string, 0, 5)
+	for _, val := range []any{previousRefreshToken, s.RefreshToken, s.AccessToken, s} {
+		bytes, err := r.store.encode(val)
 		if err != nil {
-			slogctx.Error(ctx, "couldn't delete session during rollback", "error", err)
-			return err
+			return errors.Join(ErrStoreSession, err)
 		}
-		return ErrStoreSession
+		args = append(args, string(bytes))
+	}
+	args = append(args, strconv.FormatInt(time.Until(s.Expiry).Milliseconds(), 10))
+
+	stored, err := storeRefreshedSessionScript.Exec(ctx, r.store.valkey, keys, args).AsInt64()
+	if err != nil {
+		return errors.Join(ErrStoreSession, err)
+	}
+	if stored == 0 {
+		return serviceerr.ErrConflict
 	}
 
 	return nil
 }
 
+// indexSessionScript adds the session ID with the session expiry as score to
+// the index sets, removes expired members and extends the TTL of the sets to
+// the latest expiry of their members.
+const indexSessionScript = `
+for _, key in ipairs(KEYS) do
+	redis.call("ZADD", key, ARGV[2], ARGV[1])
+	redis.call("ZREMRANGEBYSCORE", key, "-inf", "(" .. ARGV[3])
+	if redis.call("PTTL", key) < tonumber(ARGV[2]) - tonumber(ARGV[3]) then
+		redis.call("PEXPIREAT", key, ARGV[2])
+	end
+end
+return 1`
+
+// indexSessionCommand returns the command adding the session to the tenant and
+// subject indices.
+func (r *Repository) indexSessionCommand(s session.Session) valkey.Completed {
+	keys := []string{r.tenantIndexKey(s.TenantID)}
+	if s.Issuer != "" && s.ProviderSubject != "" {
+		keys = append(keys, r.subjectIndexKey(s.Issuer, s.ProviderSubject))
+	}
+	args := []string{
+		s.ID,
+		strconv.FormatInt(s.Expiry.UnixMilli(), 10),
+		strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}
+
+	return r.store.valkey.B().Eval().Script(indexSessionScript).Numkeys(int64(len(keys))).Key(keys...).Arg(args...).Build()
+}
+
+func (r *Repository) tenantIndexKey(tenantID string) string {
+	return r.store.key(objectTypeTenantSessions, getObjectID(objectTypeTenantSessions, tenantID))
+}
+
+func (r *Repository) subjectIndexKey(issuer, subject string) string {
+	// The issuer is part of the ID as subjects are only unique per issuer
+	return r.store.key(objectTypeSubjectSessions, getObjectID(objectTypeSubjectSessions, issuer+" "+subject))
+}
+
 func (r *Repository) DeleteState(ctx context.Context, stateID string) error {
 	err := r.store.Destroy(ctx, objectTypeState, stateID)
 	if err != nil {
@@ -177,23 +335,25 @@ func (r *Repository) DeleteState(ctx context.Context, stateID string) error {
 	return nil
 }
 
+// DeleteSession deletes the session along with its provider session, access
+// token and refresh token objects and removes it from the indices in a single
+// transaction.
 func (r *Repository) DeleteSession(ctx context.Context, s session.Session) error {
-	err := r.store.Destroy(ctx, objectTypeSession, s.ID)
-	if err != nil {
-		return err
-	}
-	err = r.store.Destroy(ctx, objectTypeProviderSession, getObjectID(objectTypeProviderSession, s.ProviderID))
+	client := r.store.valkey
+	err := r.store.transaction(ctx,
+		client.B().Del().Key(
+			r.store.key(objectTypeSession, s.ID),
+			r.store.key(objectTypeProviderSession, getObjectID(objectTypeProviderSession, s.ProviderID)),
+			r.store.key(objectTypeAccessToken, getObjectID(objectTypeAccessToken, s.ID)),
+			r.store.key(objectTypeRefreshToken, getObjectID(objectTypeRefreshToken, s.ID)),
+		).Build(),
+		client.B().Zrem().Key(r.tenantIndexKey(s.TenantID)).Member(s.ID).Build(),
+		client.B().Zrem().Key(r.subjectIndexKey(s.Issuer, s.ProviderSubject)).Member(s.ID).Build(),
+	)
 	if err != nil {
-		return err
-	}
-	err = r.store.Destroy(ctx, objectTypeAccessToken, getObjectID(objectTypeAccessToken, s.ID))
-	if err != nil {
-		return err
-	}
-	err = r.store.Destroy(ctx, objectTypeRefreshToken, getObjectID(objectTypeRefreshToken, s.ID))
-	if err != nil {
-		return err
+		return fmt.Errorf("deleting session from store: %w", err)
 	}
+
 	return nil
 }
 
@@ -218,6 +378,53 @@ func (r *Repository) BumpActive(ctx context.Context, sessionID string, timeout t
 	return nil
 }
 
+func (r *Repository) StoreLogoutTokenID(ctx context.Context, issuer, tokenID string, expiry time.Time) error {
+	// The issuer is part of the ID as token IDs are only unique per issuer
+	id := getObjectID(objectTypeLogoutToken, issuer+" "+tokenID)
+	err := r.store.SetNX(ctx, objectTypeLogoutToken, id, true, time.Until(expiry))
+	if err != nil {
+		if errors.Is(err, serviceerr.ErrConflict) {
+			return err
+		}
+
+		return errors.Join(ErrStoreLogoutTokenID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteLogoutTokenID(ctx context.Context, issuer, tokenID string) error {
+	err := r.store.Destroy(ctx, objectTypeLogoutToken, getObjectID(objectTypeLogoutToken, issuer+" "+tokenID))
+	if err != nil {
+		return fmt.Errorf("deleting logout token ID from store: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) AcquireRefreshLock(ctx context.Context, sessionID, owner string, ttl time.Duration) error {
+	err := r.store.SetNX(ctx, objectTypeRefreshLock, getObjectID(objectTypeRefreshLock, sessionID), owner, ttl)
+	if err != nil {
+		if errors.Is(err, serviceerr.ErrConflict) {
+			return err
+		}
+
+		return errors.Join(ErrAcquireRefreshLock, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) ReleaseRefreshLock(ctx context.Context, sessionID, owner string) error {
+	// The lock may have expired and been acquired by another owner meanwhile
+	err := r.store.DestroyIfEqual(ctx, objectTypeRefreshLock, getObjectID(objectTypeRefreshLock, sessionID), owner)
+	if err != nil {
+		return errors.Join(ErrReleaseRefreshLock, err)
+	}
+
+	return nil
+}
+
 func getObjectID(prefix ObjectType, objectID string) string {
 	return fmt.Sprintf("%s_%s", prefix, objectID)
 }
@@ -14,7 +14,9 @@ import (
 
 	"github.com/openkcm/session-manager/internal/dbtest/valkeytest"
 	"github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/internal/session/sessiontest"
 	sessionvalkey "github.com/openkcm/session-manager/internal/session/valkey"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
 )
 
 var client valkey.Client
@@ -342,6 +344,42 @@ func TestRepository_StoreSession_Fail(t *testing.T) {
 	}
 }
 
+func TestRepository_StoreSession_Atomic(t *testing.T) {
+	const prefix = "session-manager-store-session-atomic-test"
+
+	r := sessionvalkey.NewRepository(client, prefix)
+	sess := session.Session{
+		ID:           "sessionid-atomic",
+		TenantID:     "tenant-atomic",
+		ProviderID:   "provider-atomic",
+		AccessToken:  "access-token-atomic",
+		RefreshToken: "refresh-token-atomic",
+		Expiry:       testTime,
+	}
+
+	// A tenant index of the wrong type fails the transaction at execution
+	indexKey := prefix + ":tenantSessions:tenantSessions_tenant-atomic"
+	err := client.Do(t.Context(), client.B().Set().Key(indexKey).Value("not a sorted set").Build()).Error()
+	require.NoError(t, err)
+
+	err = r.StoreSession(t.Context(), sess)
+	require.ErrorIs(t, err, sessionvalkey.ErrStoreSession)
+
+	keys, err := client.Do(t.Context(), client.B().Keys().Pattern(prefix+":*").Build()).AsStrSlice()
+	require.NoError(t, err)
+	assert.Equal(t, []string{indexKey}, keys, "no session objects must be left behind")
+
+	err = client.Do(t.Context(), client.B().Del().Key(indexKey).Build()).Error()
+	require.NoError(t, err)
+
+	require.NoError(t, r.StoreSession(t.Context(), sess))
+	require.NoError(t, r.DeleteSession(t.Context(), sess))
+
+	keys, err = client.Do(t.Context(), client.B().Keys().Pattern(prefix+":*").Build()).AsStrSlice()
+	require.NoError(t, err)
+	assert.Empty(t, keys, "all session objects must be deleted")
+}
+
 func TestRepository_ListSessions(t *testing.T) {
 	const prefix = "session-manager-list-sessions-test"
 
@@ -425,6 +463,194 @@ func TestRepository_ListSessions(t *testing.T) {
 	}
 }
 
+func TestRepository_ListSessionsBySubject(t *testing.T) {
+	const prefix = "session-manager-list-sessions-by-subject-test"
+
+	sessions := []session.Session{
+		{ID: "sessionid-one", TenantID: "tenant1-id", Issuer: "https://issuer-one.example.com", ProviderSubject: "subject-one", Expiry: testTime},
+		{ID: "sessionid-two", TenantID: "tenant2-id", Issuer: "https://issuer-one.example.com", ProviderSubject: "subject-one", Expiry: testTime},
+		{ID: "sessionid-three", TenantID: "tenant1-id", Issuer: "https://issuer-one.example.com", ProviderSubject: "subject-two", Expiry: testTime},
+		{ID: "sessionid-four", TenantID: "tenant3-id", Issuer: "https://issuer-two.example.com", ProviderSubject: "subject-one", Expiry: testTime},
+	}
+	r := sessionvalkey.NewRepository(client, prefix)
+	for _, s := range sessions {
+		require.NoError(t, r.StoreSession(t.Context(), s))
+	}
+
+	gotSessions, err := r.ListSessionsBySubject(t.Context(), "https://issuer-one.example.com", "subject-one")
+	require.NoError(t, err)
+
+	sort.Slice(gotSessions, func(i, j int) bool { return gotSessions[i].ID < gotSessions[j].ID })
+	assert.Equal(t, []session.Session{sessions[0], sessions[1]}, gotSessions)
+
+	require.NoError(t, r.DeleteSession(t.Context(), sessions[0]))
+	gotSessions, err = r.ListSessionsBySubject(t.Context(), "https://issuer-one.example.com", "subject-one")
+	require.NoError(t, err)
+	assert.Equal(t, []session.Session{sessions[1]}, gotSessions, "deleted sessions must be removed from the index")
+}
+
+func TestRepository_ListSessionsByTenant(t *testing.T) {
+	const prefix = "session-manager-list-sessions-by-tenant-test"
+
+	sessions := []session.Session{
+		{ID: "sessionid-one", TenantID: "tenant1-id", Issuer: "https://issuer-one.example.com", ProviderSubject: "subject-one", Expiry: testTime},
+		{ID: "sessionid-two", TenantID: "tenant2-id", Issuer: "https://issuer-one.example.com", ProviderSubject: "subject-one", Expiry: testTime},
+		{ID: "sessionid-three", TenantID: "tenant1-id", Issuer: "https://issuer-one.example.com", ProviderSubject: "subject-two", Expiry: testTime},
+	}
+	r := sessionvalkey.NewRepository(client, prefix)
+	for _, s := range sessions {
+		require.NoError(t, r.StoreSession(t.Context(), s))
+	}
+
+	gotSessions, err := r.ListSessionsByTenant(t.Context(), "tenant1-id")
+	require.NoError(t, err)
+
+	sort.Slice(gotSessions, func(i, j int) bool { return gotSessions[i].ID < gotSessions[j].ID })
+	assert.Equal(t, []session.Session{sessions[2], sessions[0]}, gotSessions)
+
+	gotSessions, err = r.ListSessionsByTenant(t.Context(), "unknown-tenant")
+	require.NoError(t, err)
+	assert.Empty(t, gotSessions)
+}
+
+func TestRepository_ListSessionsByTenant_RemovesStaleMembers(t *testing.T) {
+	const prefix = "session-manager-list-sessions-stale-index-test"
+
+	r := sessionvalkey.NewRepository(client, prefix)
+
+	expiring := session.Session{ID: "sessionid-expiring", TenantID: "tenant-id", Expiry: time.Now().Add(2 * time.Second)}
+	removed := session.Session{ID: "sessionid-removed", TenantID: "tenant-id", Expiry: testTime}
+	remaining := session.Session{ID: "sessionid-remaining", TenantID: "tenant-id", Expiry: testTime}
+	for _, s := range []session.Session{expiring, removed, remaining} {
+		require.NoError(t, r.StoreSession(t.Context(), s))
+	}
+
+	// Remove the session key only, as if it expired with a shorter TTL
+	indexKey := prefix + ":tenantSessions:tenantSessions_tenant-id"
+	err := client.Do(t.Context(), client.B().Del().Key(prefix+":session:sessionid-removed").Build()).Error()
+	require.NoError(t, err)
+
+	time.Sleep(2500 * time.Millisecond)
+
+	gotSessions, err := r.ListSessionsByTenant(t.Context(), "tenant-id")
+	require.NoError(t, err)
+	assert.Equal(t, []session.Session{remaining}, gotSessions)
+
+	members, err := client.Do(t.Context(), client.B().Zrange().Key(indexKey).Min("0").Max("-1").Build()).AsStrSlice()
+	require.NoError(t, err)
+	assert.NotContains(t, members, "sessionid-removed", "members of removed sessions must be removed from the index")
+
+	// Storing a session removes the expired members
+	require.NoError(t, r.StoreSession(t.Context(), session.Session{ID: "sessionid-new", TenantID: "tenant-id", Expiry: testTime}))
+	members, err = client.Do(t.Context(), client.B().Zrange().Key(indexKey).Min("0").Max("-1").Build()).AsStrSlice()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sessionid-remaining", "sessionid-new"}, members)
+
+	ttl, err := client.Do(t.Context(), client.B().Pttl().Key(indexKey).Build()).AsInt64()
+	require.NoError(t, err)
+	assert.InDelta(t, time.Until(testTime).Milliseconds(), ttl, float64(time.Minute.Milliseconds()), "index must expire with its last session")
+}
+
+func TestRepository_StoreLogoutTokenID(t *testing.T) {
+	const prefix = "session-manager-store-logout-token-id-test"
+
+	r := sessionvalkey.NewRepository(client, prefix)
+	expiry := time.Now().Add(time.Minute)
+
+	err := r.StoreLogoutTokenID(t.Context(), "https://issuer-one.example.com", "jti-one", expiry)
+	require.NoError(t, err)
+
+	err = r.StoreLogoutTokenID(t.Context(), "https://issuer-one.example.com", "jti-one", expiry)
+	assert.ErrorIs(t, err, serviceerr.ErrConflict, "replayed token ID must be rejected")
+
+	err = r.StoreLogoutTokenID(t.Context(), "https://issuer-two.example.com", "jti-one", expiry)
+	assert.NoError(t, err, "token IDs are unique per issuer")
+}
+
+func TestRepository_StoreLogoutTokenID_SubSecondExpiry(t *testing.T) {
+	const prefix = "session-manager-store-logout-token-id-sub-second-test"
+
+	r := sessionvalkey.NewRepository(client, prefix)
+
+	err := r.StoreLogoutTokenID(t.Context(), "https://issuer-one.example.com", "jti-one", time.Now().Add(500*time.Millisecond))
+	require.NoError(t, err, "token IDs expiring in less than a second must be stored")
+
+	err = r.StoreLogoutTokenID(t.Context(), "https://issuer-one.example.com", "jti-one", time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, serviceerr.ErrConflict, "replayed token ID must be rejected")
+}
+
+func TestRepository_RefreshLock(t *testing.T) {
+	const prefix = "session-manager-refresh-lock-test"
+
+	r := sessionvalkey.NewRepository(client, prefix)
+
+	err := r.AcquireRefreshLock(t.Context(), "session-one", "owner-one", time.Minute)
+	require.NoError(t, err)
+
+	err = r.AcquireRefreshLock(t.Context(), "session-one", "owner-two", time.Minute)
+	assert.ErrorIs(t, err, serviceerr.ErrConflict, "held lock must not be acquired")
+
+	err = r.AcquireRefreshLock(t.Context(), "session-two", "owner-two", time.Minute)
+	assert.NoError(t, err, "locks are held per session")
+
+	err = r.ReleaseRefreshLock(t.Context(), "session-one", "owner-two")
+	require.NoError(t, err)
+
+	err = r.AcquireRefreshLock(t.Context(), "session-one", "owner-two", time.Minute)
+	assert.ErrorIs(t, err, serviceerr.ErrConflict, "lock must only be released by its owner")
+
+	err = r.ReleaseRefreshLock(t.Context(), "session-one", "owner-one")
+	require.NoError(t, err)
+
+	err = r.AcquireRefreshLock(t.Context(), "session-one", "owner-two", time.Minute)
+	assert.NoError(t, err, "released lock must be acquired")
+}
+
+func TestRepository_StoreRefreshedSession(t *testing.T) {
+	const prefix = "session-manager-store-refreshed-session-test"
+
+	r := sessionvalkey.NewRepository(client, prefix)
+
+	sess := session.Session{
+		ID:           "session-refreshed",
+		TenantID:     "tenant-refreshed",
+		ProviderID:   "provider-refreshed",
+		AccessToken:  "old-access-token",
+		RefreshToken: "old-refresh-token",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	err := r.StoreSession(t.Context(), sess)
+	require.NoError(t, err)
+
+	refreshed := sess
+	refreshed.AccessToken = "new-access-token"
+	refreshed.RefreshToken = "new-refresh-token"
+	err = r.StoreRefreshedSession(t.Context(), refreshed, "old-refresh-token")
+	require.NoError(t, err)
+
+	concurrent := sess
+	concurrent.AccessToken = "concurrent-access-token"
+	concurrent.RefreshToken = "concurrent-refresh-token"
+	err = r.StoreRefreshedSession(t.Context(), concurrent, "old-refresh-token")
+	assert.ErrorIs(t, err, serviceerr.ErrConflict, "replaced tokens must not be overwritten")
+
+	loaded, err := r.LoadSession(t.Context(), sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new-access-token", loaded.AccessToken)
+	assert.Equal(t, "new-refresh-token", loaded.RefreshToken)
+
+	accessToken, err := r.GetAccessTokenForSession(t.Context(), sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new-access-token", accessToken)
+
+	refreshToken, err := r.GetRefreshTokenForSession(t.Context(), sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new-refresh-token", refreshToken)
+
+	err = r.StoreRefreshedSession(t.Context(), session.Session{ID: "non-existent-session", Expiry: time.Now().Add(time.Hour)}, "")
+	assert.ErrorIs(t, err, serviceerr.ErrConflict, "deleted sessions must not be recreated")
+}
+
 func TestRepository_DeleteState(t *testing.T) {
 	const tenantID = "tenant-delete"
 	const stateID = "stateid-delete"
@@ -606,3 +832,9 @@ func TestRepository_BumpActive_ThenExpires(t *testing.T) {
 	assert.NoError(t, err, "Repository.IsActive() should not return error for expired session")
 	assert.False(t, gotActive, "Session should not be active after timeout expires")
 }
+
+func TestRepository_Conformance(t *testing.T) {
+	sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+		return sessionvalkey.NewRepository(client, "session-manager-conformance-test")
+	})
+}
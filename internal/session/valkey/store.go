@@ -47,6 +47,50 @@ func (s *store) Set(ctx context.Context, objectType ObjectType, id string, val a
 	return nil
 }
 
+// SetNX sets the value only if the key does not exist yet. It returns
+// serviceerr.ErrConflict if the key already exists.
+func (s *store) SetNX(ctx context.Context, objectType ObjectType, id string, val any, duration time.Duration) error {
+	key := s.key(objectType, id)
+	bytes, err := s.encode(val)
+	if err != nil {
+		return fmt.Errorf("encoding data: %w", err)
+	}
+
+	err = s.valkey.Do(ctx, s.valkey.B().Set().Key(key).Value(valkey.BinaryString(bytes)).Nx().Px(duration).Build()).Error()
+	if err != nil {
+		if valkey.IsValkeyNil(err) {
+			return serviceerr.ErrConflict
+		}
+
+		return fmt.Errorf("executing set command: %w", err)
+	}
+
+	return nil
+}
+
+// destroyIfEqualScript deletes the key only if it holds the expected value.
+var destroyIfEqualScript = valkey.NewLuaScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// DestroyIfEqual deletes the object only if it holds the given value.
+func (s *store) DestroyIfEqual(ctx context.Context, objectType ObjectType, id string, val any) error {
+	key := s.key(objectType, id)
+	bytes, err := s.encode(val)
+	if err != nil {
+		return fmt.Errorf("encoding data: %w", err)
+	}
+
+	err = destroyIfEqualScript.Exec(ctx, s.valkey, []string{key}, []string{string(bytes)}).Error()
+	if err != nil {
+		return fmt.Errorf("executing del script: %w", err)
+	}
+
+	return nil
+}
+
 func (s *store) Destroy(ctx context.Context, objectType ObjectType, id string) error {
 	key := s.key(objectType, id)
 	err := s.valkey.Do(ctx, s.valkey.B().Del().Key(key).Build()).Error()
@@ -57,6 +101,35 @@ func (s *store) Destroy(ctx context.Context, objectType ObjectType, id string) e
 	return nil
 }
 
+// transaction executes the commands atomically in a MULTI/EXEC transaction.
+// Like in any transaction, commands which fail at execution do not roll back
+// the others.
+func (s *store) transaction(ctx context.Context, cmds ...valkey.Completed) error {
+	multi := make(valkey.Commands, 0, len(cmds)+2)
+	multi = append(multi, s.valkey.B().Multi().Build())
+	multi = append(multi, cmds...)
+	multi = append(multi, s.valkey.B().Exec().Build())
+
+	resps := s.valkey.DoMulti(ctx, multi...)
+	for _, resp := range resps[:len(resps)-1] {
+		if err := resp.Error(); err != nil {
+			return fmt.Errorf("queueing command: %w", err)
+		}
+	}
+
+	results, err := resps[len(resps)-1].ToArray()
+	if err != nil {
+		return fmt.Errorf("executing transaction: %w", err)
+	}
+	for _, result := range results {
+		if err := result.Error(); err != nil {
+			return fmt.Errorf("executing transaction command: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (s *store) get(ctx context.Context, key string, decodeInto any) error {
 	bytes, err := s.valkey.Do(ctx, s.valkey.B().Get().Key(key).Build()).AsBytes()
 	if err != nil {
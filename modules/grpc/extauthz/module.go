@@ -0,0 +1,150 @@
+// Package extauthz provides the service.module.grpc.extauthz module: a gRPC
+// service module that registers the envoy.service.auth.v3.Authorization
+// service onto a grpc.ServiceRegistrar supplied by app.module.grpcserver, so
+// Envoy can authorise requests with the session cookie directly.
+package extauthz
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/credentials"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/modules/grpc/session"
+)
+
+const moduleID = "service.module.grpc.extauthz"
+
+func init() {
+	sessionmanager.RegisterModule(new(Module))
+}
+
+func newModule() sessionmanager.Module {
+	return new(Module)
+}
+
+// credentialsBuilder is the interface satisfied by a credentials module
+// (e.g. credentials.module.oauth2).
+type credentialsBuilder interface {
+	Builder() credentials.Builder
+}
+
+// Module is the service.module.grpc.extauthz module. It wires its three
+// dependencies (trust, session store, credentials) by ID via ctx.GetModule
+// and owns a *Server that implements the proto.
+type Module struct {
+	Mod          string `yaml:"module"`
+	Trust        string `yaml:"trust"        default:"trust.module.oidc"        dep:"sessionmanager.Trust"`
+	SessionStore string `yaml:"sessionStore" default:"sessionstore.module.valkey" dep:"session.Repository"`
+	Credentials  string `yaml:"credentials"  default:"credentials.module.oauth2"  dep:"credentials.Builder"`
+
+	AllowHttpScheme bool `yaml:"allowHttpScheme"`
+	// TenantHeader is the request header which holds the tenant ID. It is
+	// overwritten with the tenant of the session for the upstream. Empty
+	// disables it.
+	TenantHeader string `yaml:"tenantHeader"`
+	// TenantPathSegment is the 1-based segment of the request path which holds
+	// the tenant ID. It takes precedence over the tenant header, which must
+	// match it if present. Zero disables it.
+	TenantPathSegment int `yaml:"tenantPathSegment"`
+	// CSRFHeader is the request header which holds the CSRF token of requests
+	// with unsafe methods.
+	CSRFHeader string `yaml:"csrfHeader" default:"x-csrf-token"`
+	// AuthURL is the URL of the auth endpoint to which unauthenticated
+	// navigations are redirected.
+	AuthURL string `yaml:"authURL" default:"/sm/auth"`
+	// TokenRefreshWindow defines the duration before token expiry when the
+	// access token of a session is refreshed.
+	TokenRefreshWindow time.Duration `yaml:"tokenRefreshWindow" default:"1m"`
+
+	server *Server
+}
+
+func (m *Module) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  moduleID,
+		New: newModule,
+	}
+}
+
+func (m *Module) Provision(ctx *sessionmanager.Context) error {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		return errors.New("config not found in context")
+	}
+
+	// The session manager depends on the secrets, which are usually loaded
+	// by the caller of LoadAll already
+	if err := cfg.SessionManager.LoadSecrets(); err != nil {
+		return fmt.Errorf("loading session manager secrets: %w", err)
+	}
+
+	if m.TenantHeader == "" && m.TenantPathSegment <= 0 {
+		return errors.New("either tenantHeader or tenantPathSegment is required")
+	}
+
+	trust, err := sessionmanager.GetModuleAs[sessionmanager.Trust](ctx, m.Trust)
+	if err != nil {
+		return fmt.Errorf("getting trust module %q: %w", m.Trust, err)
+	}
+
+	repo, err := sessionmanager.GetModuleAs[internalsession.Repository](ctx, m.SessionStore)
+	if err != nil {
+		return fmt.Errorf("getting session-store module %q: %w", m.SessionStore, err)
+	}
+
+	creds, err := sessionmanager.GetModuleAs[credentialsBuilder](ctx, m.Credentials)
+	if err != nil {
+		return fmt.Errorf("getting credentials module %q: %w", m.Credentials, err)
+	}
+
+	auditLogger, err := otlpaudit.NewLogger(&cfg.Audit)
+	if err != nil {
+		return fmt.Errorf("creating audit logger: %w", err)
+	}
+
+	manager, err := internalsession.NewManager(ctx,
+		&cfg.SessionManager,
+		trust,
+		repo,
+		auditLogger,
+		internalsession.WithTransportCredentials(creds.Builder()),
+		internalsession.WithAllowHttpScheme(m.AllowHttpScheme),
+	)
+	if err != nil {
+		return fmt.Errorf("creating session manager: %w", err)
+	}
+
+	sessions := session.NewServer(
+		ctx,
+		repo,
+		trust,
+		cfg.SessionManager.IdleSessionTimeout,
+		session.WithTransportCredentials(creds.Builder()),
+		session.WithAllowHttpScheme(m.AllowHttpScheme),
+		session.WithTokenRefresh(manager, m.TokenRefreshWindow),
+	)
+
+	// Envoy passes the request headers with lowercase keys
+	m.server = NewServer(sessions, manager,
+		WithTenantHeader(strings.ToLower(m.TenantHeader)),
+		WithTenantPathSegment(m.TenantPathSegment),
+		WithCSRFHeader(strings.ToLower(m.CSRFHeader)),
+		WithAuthURL(m.AuthURL),
+	)
+
+	return nil
+}
+
+func (m *Module) Register(s grpc.ServiceRegistrar) {
+	authv3.RegisterAuthorizationServer(s, m.server)
+}
@@ -0,0 +1,151 @@
+package extauthz_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openkcm/common-sdk/pkg/csrf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/config"
+	_ "github.com/openkcm/session-manager/modules/standard"
+)
+
+func TestModule_Registration(t *testing.T) {
+	info, err := sessionmanager.GetModule("service.module.grpc.extauthz")
+	require.NoError(t, err)
+	assert.Equal(t, "service.module.grpc.extauthz", info.ID)
+}
+
+// stubTrustModule is a trust module without any trusts.
+type stubTrustModule struct{ id string }
+
+func (s *stubTrustModule) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  s.id,
+		New: func() sessionmanager.Module { return s },
+	}
+}
+
+var errNoTrust = errors.New("no trust")
+
+func (*stubTrustModule) Apply(context.Context, *trustv1.Trust) error { return nil }
+func (*stubTrustModule) Block(context.Context, string) error         { return nil }
+func (*stubTrustModule) Remove(context.Context, string) error        { return nil }
+func (*stubTrustModule) Unblock(context.Context, string) error       { return nil }
+func (*stubTrustModule) Get(context.Context, string) (*trustv1.Trust, error) {
+	return nil, errNoTrust
+}
+func (*stubTrustModule) ListByIssuer(context.Context, string) ([]*trustv1.Trust, error) {
+	return nil, errNoTrust
+}
+
+// serviceRegistrar captures the implementation of the registered service.
+type serviceRegistrar struct{ impl any }
+
+func (r *serviceRegistrar) RegisterService(_ *grpc.ServiceDesc, impl any) { r.impl = impl }
+
+func TestModule_ProvisionFromConfig(t *testing.T) {
+	const csrfSecret = "0123456789abcdef0123456789abcdef"
+
+	trustID := "trust.module.test." + t.Name()
+	sessionmanager.RegisterModule(&stubTrustModule{id: trustID})
+
+	yaml := `
+trust:
+  module: ` + trustID + `
+valkey:
+  module: sessionstore.module.memory
+  prefix: ` + t.Name() + `
+sessionManager:
+  callbackURL: http://localhost/sm/callback
+  clientAuth:
+    type: insecure
+  sessionCookieTemplate:
+    name: SESSION
+  csrfSecret:
+    source: embedded
+    value: ` + csrfSecret + `
+apps:
+  grpc:
+    module: app.module.grpcserver
+    services:
+      - module: service.module.grpc.extauthz
+        trust: ` + trustID + `
+        sessionStore: sessionstore.module.memory
+        tenantHeader: x-tenant-id
+`
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0o600))
+
+	cfg, err := config.Load("", dir)
+	require.NoError(t, err)
+
+	ctx, cancel := sessionmanager.NewContext(t.Context())
+	defer cancel(nil)
+	ctx = config.WithContext(ctx, cfg)
+
+	require.NoError(t, ctx.LoadAll([]sessionmanager.LoadSpec{
+		{Cfg: &cfg.Trust},
+		{Cfg: &cfg.ValKey},
+		{Cfg: &cfg.Credentials},
+		{Cfg: cfg.Apps["grpc"].Services[0]},
+	}))
+
+	service, err := sessionmanager.GetModuleAs[interface{ Register(grpc.ServiceRegistrar) }](ctx, "service.module.grpc.extauthz")
+	require.NoError(t, err)
+	registrar := &serviceRegistrar{}
+	service.Register(registrar)
+	server, ok := registrar.impl.(authv3.AuthorizationServer)
+	require.True(t, ok)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode codes.Code
+	}{
+		{
+			// The session is unknown, so the request is not authenticated
+			name:     "CSRF token signed with the configured secret",
+			token:    csrf.NewToken(sessionID, []byte(csrfSecret)),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "CSRF token signed with an empty secret",
+			token:    csrf.NewToken(sessionID, nil),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.Check(ctx, &authv3.CheckRequest{
+				Attributes: &authv3.AttributeContext{
+					Request: &authv3.AttributeContext_Request{
+						Http: &authv3.AttributeContext_HttpRequest{
+							Method: http.MethodPost,
+							Path:   "/ui",
+							Headers: map[string]string{
+								"x-tenant-id":  tenantID,
+								"cookie":       "SESSION-" + tenantID + "=" + sessionID,
+								"x-csrf-token": tt.token,
+							},
+						},
+					},
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int32(tt.wantCode), resp.GetStatus().GetCode())
+		})
+	}
+}
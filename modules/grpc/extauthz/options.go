@@ -0,0 +1,41 @@
+package extauthz
+
+const (
+	defaultCSRFHeader = "x-csrf-token"
+	defaultAuthURL    = "/sm/auth"
+)
+
+type Option func(*Server)
+
+// WithTenantHeader sets the request header which holds the tenant ID. The
+// header is overwritten with the tenant of the session for the upstream.
+func WithTenantHeader(header string) Option {
+	return func(s *Server) {
+		s.tenantHeader = header
+	}
+}
+
+// WithTenantPathSegment sets the 1-based segment of the request path which
+// holds the tenant ID. It takes precedence over the tenant header, which must
+// match it if present.
+func WithTenantPathSegment(segment int) Option {
+	return func(s *Server) {
+		s.tenantPathSegment = segment
+	}
+}
+
+// WithCSRFHeader sets the request header which holds the CSRF token of
+// requests with unsafe methods.
+func WithCSRFHeader(header string) Option {
+	return func(s *Server) {
+		s.csrfHeader = header
+	}
+}
+
+// WithAuthURL sets the URL of the auth endpoint to which unauthenticated
+// navigations are redirected.
+func WithAuthURL(authURL string) Option {
+	return func(s *Server) {
+		s.authURL = authURL
+	}
+}
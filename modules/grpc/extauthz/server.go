@@ -0,0 +1,255 @@
+package extauthz
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/genproto/googleapis/rpc/status"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	sessionv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/sessionmanager/session/v1"
+	slogctx "github.com/veqryn/slog-context"
+	grpccodes "google.golang.org/grpc/codes"
+)
+
+// Headers with the identity of the session user which are sent upstream with
+// authorised requests. They are removed from requests which do not set them.
+const (
+	HeaderTenantID   = "x-session-tenant-id"
+	HeaderIssuer     = "x-session-issuer"
+	HeaderSubject    = "x-session-subject"
+	HeaderGivenName  = "x-session-given-name"
+	HeaderFamilyName = "x-session-family-name"
+	HeaderEmail      = "x-session-email"
+	HeaderGroups     = "x-session-groups"
+)
+
+// SessionValidator validates sessions, e.g. the *session.Server of the
+// service.module.grpc.session module.
+type SessionValidator interface {
+	GetSession(ctx context.Context, req *sessionv1.GetSessionRequest) (*sessionv1.GetSessionResponse, error)
+}
+
+// SessionManager is implemented by *internalsession.Manager.
+type SessionManager interface {
+	MakeSessionCookie(ctx context.Context, tenantID, value string) (*http.Cookie, error)
+	ValidateCSRFToken(token, sessionID string) bool
+}
+
+type Server struct {
+	authv3.UnimplementedAuthorizationServer
+
+	sessions SessionValidator
+	manager  SessionManager
+
+	tenantHeader      string
+	tenantPathSegment int
+	csrfHeader        string
+	authURL           string
+}
+
+func NewServer(sessions SessionValidator, manager SessionManager, opts ...Option) *Server {
+	s := &Server{
+		sessions:   sessions,
+		manager:    manager,
+		csrfHeader: defaultCSRFHeader,
+		authURL:    defaultAuthURL,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	return s
+}
+
+// Check authorises the request if it carries the session cookie of a valid
+// session of the tenant and, for unsafe methods, a valid CSRF token.
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "ext_authz_check")
+	defer span.End()
+
+	httpReq := req.GetAttributes().GetRequest().GetHttp()
+
+	tenantID := s.tenantID(httpReq)
+	if tenantID == "" {
+		span.SetStatus(codes.Ok, "missing tenant")
+		slogctx.Warn(ctx, "Could not determine the tenant of the request", "path", httpReq.GetPath())
+		return denied(grpccodes.InvalidArgument, typev3.StatusCode_BadRequest, nil), nil
+	}
+	ctx = slogctx.With(ctx, "tenantId", tenantID)
+
+	sessionCookie, err := s.manager.MakeSessionCookie(ctx, tenantID, "")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to make the session cookie")
+		slogctx.Error(ctx, "Could not make the session cookie", "error", err)
+		return denied(grpccodes.Internal, typev3.StatusCode_InternalServerError, nil), nil
+	}
+
+	sessionID := cookieValue(httpReq.GetHeaders()["cookie"], sessionCookie.Name)
+	if sessionID == "" {
+		span.SetStatus(codes.Ok, "missing session cookie")
+		return s.unauthenticated(httpReq, tenantID), nil
+	}
+
+	if !isSafeMethod(httpReq.GetMethod()) && !s.manager.ValidateCSRFToken(httpReq.GetHeaders()[s.csrfHeader], sessionID) {
+		span.SetStatus(codes.Ok, "invalid CSRF token")
+		slogctx.Warn(ctx, "Is this an attack? Invalid CSRF token", "method", httpReq.GetMethod())
+		return denied(grpccodes.PermissionDenied, typev3.StatusCode_Forbidden, nil), nil
+	}
+
+	sess, err := s.sessions.GetSession(ctx, &sessionv1.GetSessionRequest{
+		SessionId: sessionID,
+		TenantId:  tenantID,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get the session")
+		slogctx.Error(ctx, "Could not get the session", "error", err)
+		return denied(grpccodes.PermissionDenied, typev3.StatusCode_Forbidden, nil), nil
+	}
+	if !sess.GetValid() {
+		span.SetStatus(codes.Ok, "invalid session")
+		return s.unauthenticated(httpReq, tenantID), nil
+	}
+
+	identity := map[string]string{
+		HeaderTenantID:   tenantID,
+		HeaderIssuer:     sess.GetIssuer(),
+		HeaderSubject:    sess.GetSubject(),
+		HeaderGivenName:  sess.GetGivenName(),
+		HeaderFamilyName: sess.GetFamilyName(),
+		HeaderEmail:      sess.GetEmail(),
+		HeaderGroups:     strings.Join(sess.GetGroups(), ","),
+	}
+
+	okResponse := &authv3.OkHttpResponse{}
+	if s.tenantHeader != "" {
+		// The tenant header is sent by the client, so it is overwritten
+		// with the tenant of the session
+		okResponse.Headers = append(okResponse.Headers, header(s.tenantHeader, tenantID))
+	}
+	for _, key := range []string{HeaderTenantID, HeaderIssuer, HeaderSubject, HeaderGivenName, HeaderFamilyName, HeaderEmail, HeaderGroups} {
+		// Identity headers must never be passed on from the client
+		if identity[key] == "" {
+			okResponse.HeadersToRemove = append(okResponse.HeadersToRemove, key)
+			continue
+		}
+		okResponse.Headers = append(okResponse.Headers, header(key, identity[key]))
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return &authv3.CheckResponse{
+		Status:       &status.Status{Code: int32(grpccodes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: okResponse},
+	}, nil
+}
+
+// tenantID returns the tenant ID from the configured segment of the request
+// path or, if no segment is configured, from the tenant header. A tenant header
+// which contradicts the path is rejected by returning an empty tenant ID.
+func (s *Server) tenantID(httpReq *authv3.AttributeContext_HttpRequest) string {
+	var headerTenantID string
+	if s.tenantHeader != "" {
+		headerTenantID = httpReq.GetHeaders()[s.tenantHeader]
+	}
+
+	if s.tenantPathSegment <= 0 {
+		return headerTenantID
+	}
+
+	path, _, _ := strings.Cut(httpReq.GetPath(), "?")
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if s.tenantPathSegment > len(segments) {
+		return ""
+	}
+	tenantID, err := url.PathUnescape(segments[s.tenantPathSegment-1])
+	if err != nil {
+		return ""
+	}
+	if headerTenantID != "" && headerTenantID != tenantID {
+		return ""
+	}
+
+	return tenantID
+}
+
+// unauthenticated redirects navigations to the auth endpoint, which starts the
+// login and returns to the requested URL afterwards. Other requests cannot
+// follow the redirect and are denied.
+func (s *Server) unauthenticated(httpReq *authv3.AttributeContext_HttpRequest, tenantID string) *authv3.CheckResponse {
+	if !isSafeMethod(httpReq.GetMethod()) || httpReq.GetHost() == "" {
+		return denied(grpccodes.Unauthenticated, typev3.StatusCode_Unauthorized, nil)
+	}
+
+	scheme := httpReq.GetScheme()
+	if scheme == "" {
+		scheme = "https"
+	}
+	requestURI := scheme + "://" + httpReq.GetHost() + httpReq.GetPath()
+
+	query := url.Values{}
+	query.Set("tenant_id", tenantID)
+	query.Set("request_uri", requestURI)
+
+	location := s.authURL + "?" + query.Encode()
+	if strings.Contains(s.authURL, "?") {
+		location = s.authURL + "&" + query.Encode()
+	}
+
+	return denied(grpccodes.Unauthenticated, typev3.StatusCode_Found, []*corev3.HeaderValueOption{
+		header("location", location),
+		header("cache-control", "no-store"),
+	})
+}
+
+func denied(code grpccodes.Code, httpStatus typev3.StatusCode, headers []*corev3.HeaderValueOption) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(code)},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+			Status:  &typev3.HttpStatus{Code: httpStatus},
+			Headers: headers,
+		}},
+	}
+}
+
+func header(key, value string) *corev3.HeaderValueOption {
+	return &corev3.HeaderValueOption{
+		Header:       &corev3.HeaderValue{Key: key, Value: value},
+		AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+	}
+}
+
+// cookieValue returns the value of the named cookie of the Cookie header.
+// Malformed cookies, e.g. set by other applications of the domain, are skipped
+// instead of failing the whole header.
+func cookieValue(cookieHeader, name string) string {
+	r := http.Request{Header: http.Header{"Cookie": {cookieHeader}}}
+	c, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+
+	return c.Value
+}
+
+// isSafeMethod reports whether the method is safe as defined in
+// https://www.rfc-editor.org/rfc/rfc9110.html#section-9.2.1 and thus not
+// protected against CSRF.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,340 @@
+package extauthz_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/openkcm/common-sdk/pkg/csrf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	sessionv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/sessionmanager/session/v1"
+
+	"github.com/openkcm/session-manager/internal/config"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	"github.com/openkcm/session-manager/modules/grpc/extauthz"
+)
+
+const (
+	sessionID = "session-123"
+	tenantID  = "tenant-123"
+)
+
+var csrfSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// sessionValidator answers GetSession with a fixed response.
+type sessionValidator struct {
+	resp *sessionv1.GetSessionResponse
+	err  error
+	req  *sessionv1.GetSessionRequest
+}
+
+func (v *sessionValidator) GetSession(_ context.Context, req *sessionv1.GetSessionRequest) (*sessionv1.GetSessionResponse, error) {
+	v.req = req
+	return v.resp, v.err
+}
+
+func TestServer_Check(t *testing.T) {
+	validSession := &sessionv1.GetSessionResponse{
+		Valid:      true,
+		Issuer:     "https://issuer.example.com",
+		Subject:    "subject-123",
+		GivenName:  "Jane",
+		FamilyName: "Doe",
+		Email:      "jane.doe@example.com",
+		Groups:     []string{"admins", "users"},
+	}
+
+	tests := []struct {
+		name        string
+		opts        []extauthz.Option
+		method      string
+		path        string
+		headers     map[string]string
+		resp        *sessionv1.GetSessionResponse
+		err         error
+		wantCode    codes.Code
+		wantStatus  typev3.StatusCode
+		wantHeaders map[string]string
+		wantRemoved []string
+	}{
+		{
+			name:   "Valid session",
+			method: http.MethodGet,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "other=value; __Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp:     validSession,
+			wantCode: codes.OK,
+			wantHeaders: map[string]string{
+				"x-tenant-id":             tenantID,
+				extauthz.HeaderTenantID:   tenantID,
+				extauthz.HeaderIssuer:     "https://issuer.example.com",
+				extauthz.HeaderSubject:    "subject-123",
+				extauthz.HeaderGivenName:  "Jane",
+				extauthz.HeaderFamilyName: "Doe",
+				extauthz.HeaderEmail:      "jane.doe@example.com",
+				extauthz.HeaderGroups:     "admins,users",
+			},
+		},
+		{
+			name:   "Malformed cookie of another application",
+			method: http.MethodGet,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "other=\"unbalanced; __Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp:        validSession,
+			wantCode:    codes.OK,
+			wantHeaders: map[string]string{extauthz.HeaderTenantID: tenantID},
+		},
+		{
+			name:   "Removes absent identity headers",
+			method: http.MethodGet,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp: &sessionv1.GetSessionResponse{
+				Valid:   true,
+				Issuer:  "https://issuer.example.com",
+				Subject: "subject-123",
+			},
+			wantCode: codes.OK,
+			wantHeaders: map[string]string{
+				extauthz.HeaderTenantID: tenantID,
+				extauthz.HeaderIssuer:   "https://issuer.example.com",
+				extauthz.HeaderSubject:  "subject-123",
+			},
+			wantRemoved: []string{
+				extauthz.HeaderGivenName,
+				extauthz.HeaderFamilyName,
+				extauthz.HeaderEmail,
+				extauthz.HeaderGroups,
+			},
+		},
+		{
+			name:   "Tenant from the path",
+			opts:   []extauthz.Option{extauthz.WithTenantPathSegment(2)},
+			method: http.MethodGet,
+			path:   "/tenants/" + tenantID + "/keys?page=1",
+			headers: map[string]string{
+				"cookie": "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp:        validSession,
+			wantCode:    codes.OK,
+			wantHeaders: map[string]string{extauthz.HeaderTenantID: tenantID},
+		},
+		{
+			name:   "Tenant header matching the path",
+			opts:   []extauthz.Option{extauthz.WithTenantPathSegment(2)},
+			method: http.MethodGet,
+			path:   "/tenants/" + tenantID + "/keys",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp:     validSession,
+			wantCode: codes.OK,
+			wantHeaders: map[string]string{
+				"x-tenant-id":           tenantID,
+				extauthz.HeaderTenantID: tenantID,
+			},
+		},
+		{
+			name:   "Tenant header contradicting the path",
+			opts:   []extauthz.Option{extauthz.WithTenantPathSegment(2)},
+			method: http.MethodGet,
+			path:   "/tenants/" + tenantID + "/keys",
+			headers: map[string]string{
+				"x-tenant-id": "other-tenant",
+				"cookie":      "__Host-Http-SESSION-other-tenant=" + sessionID,
+			},
+			resp:       validSession,
+			wantCode:   codes.InvalidArgument,
+			wantStatus: typev3.StatusCode_BadRequest,
+		},
+		{
+			name:   "Disabled tenant header",
+			opts:   []extauthz.Option{extauthz.WithTenantHeader(""), extauthz.WithTenantPathSegment(2)},
+			method: http.MethodGet,
+			path:   "/tenants/" + tenantID + "/keys",
+			headers: map[string]string{
+				"x-tenant-id": "other-tenant",
+				"cookie":      "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp:     validSession,
+			wantCode: codes.OK,
+			wantHeaders: map[string]string{
+				"x-tenant-id":           "",
+				extauthz.HeaderTenantID: tenantID,
+			},
+		},
+		{
+			name:       "Missing tenant",
+			method:     http.MethodGet,
+			path:       "/ui",
+			headers:    map[string]string{"cookie": "__Host-Http-SESSION-" + tenantID + "=" + sessionID},
+			resp:       validSession,
+			wantCode:   codes.InvalidArgument,
+			wantStatus: typev3.StatusCode_BadRequest,
+		},
+		{
+			name:   "Session cookie of another tenant",
+			method: http.MethodPost,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "__Host-Http-SESSION-other-tenant=" + sessionID,
+			},
+			resp:       validSession,
+			wantCode:   codes.Unauthenticated,
+			wantStatus: typev3.StatusCode_Unauthorized,
+		},
+		{
+			name:   "Invalid session redirects navigations",
+			method: http.MethodGet,
+			path:   "/ui?tab=keys",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp:       &sessionv1.GetSessionResponse{Valid: false},
+			wantCode:   codes.Unauthenticated,
+			wantStatus: typev3.StatusCode_Found,
+			wantHeaders: map[string]string{
+				"location": "/sm/auth?" + url.Values{
+					"tenant_id":   {tenantID},
+					"request_uri": {"https://cmk.example.com/ui?tab=keys"},
+				}.Encode(),
+			},
+		},
+		{
+			name:   "Unsafe method with a valid CSRF token",
+			method: http.MethodPost,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id":  tenantID,
+				"cookie":       "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+				"x-csrf-token": csrf.NewToken(sessionID, csrfSecret),
+			},
+			resp:        validSession,
+			wantCode:    codes.OK,
+			wantHeaders: map[string]string{extauthz.HeaderSubject: "subject-123"},
+		},
+		{
+			name:   "Unsafe method with an invalid CSRF token",
+			method: http.MethodPost,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id":  tenantID,
+				"cookie":       "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+				"x-csrf-token": csrf.NewToken("other-session", csrfSecret),
+			},
+			resp:       validSession,
+			wantCode:   codes.PermissionDenied,
+			wantStatus: typev3.StatusCode_Forbidden,
+		},
+		{
+			name:   "Unsafe method without a CSRF token",
+			method: http.MethodDelete,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			resp:       validSession,
+			wantCode:   codes.PermissionDenied,
+			wantStatus: typev3.StatusCode_Forbidden,
+		},
+		{
+			name:   "Session validation fails",
+			method: http.MethodGet,
+			path:   "/ui",
+			headers: map[string]string{
+				"x-tenant-id": tenantID,
+				"cookie":      "__Host-Http-SESSION-" + tenantID + "=" + sessionID,
+			},
+			err:        errors.New("tenant blocked"),
+			wantCode:   codes.PermissionDenied,
+			wantStatus: typev3.StatusCode_Forbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &sessionValidator{resp: tt.resp, err: tt.err}
+			opts := append([]extauthz.Option{extauthz.WithTenantHeader("x-tenant-id")}, tt.opts...)
+			server := extauthz.NewServer(validator, newManager(t), opts...)
+
+			resp, err := server.Check(t.Context(), &authv3.CheckRequest{
+				Attributes: &authv3.AttributeContext{
+					Request: &authv3.AttributeContext_Request{
+						Http: &authv3.AttributeContext_HttpRequest{
+							Method:  tt.method,
+							Scheme:  "https",
+							Host:    "cmk.example.com",
+							Path:    tt.path,
+							Headers: tt.headers,
+						},
+					},
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int32(tt.wantCode), resp.GetStatus().GetCode())
+
+			if tt.wantCode != codes.OK {
+				assert.Nil(t, resp.GetOkResponse())
+				assert.Equal(t, tt.wantStatus, resp.GetDeniedResponse().GetStatus().GetCode())
+				headers := make(map[string]string)
+				for _, h := range resp.GetDeniedResponse().GetHeaders() {
+					headers[h.GetHeader().GetKey()] = h.GetHeader().GetValue()
+				}
+				for k, v := range tt.wantHeaders {
+					assert.Equal(t, v, headers[k], "header %s", k)
+				}
+				return
+			}
+
+			require.NotNil(t, validator.req)
+			assert.Equal(t, sessionID, validator.req.GetSessionId())
+			assert.Equal(t, tenantID, validator.req.GetTenantId())
+
+			headers := make(map[string]string)
+			for _, h := range resp.GetOkResponse().GetHeaders() {
+				headers[h.GetHeader().GetKey()] = h.GetHeader().GetValue()
+			}
+			for k, v := range tt.wantHeaders {
+				assert.Equal(t, v, headers[k], "header %s", k)
+			}
+			assert.ElementsMatch(t, tt.wantRemoved, resp.GetOkResponse().GetHeadersToRemove())
+		})
+	}
+}
+
+func newManager(t *testing.T) *internalsession.Manager {
+	t.Helper()
+
+	manager, err := internalsession.NewManager(t.Context(),
+		&config.SessionManager{
+			CSRFSecretParsed:      csrfSecret,
+			SessionCookieTemplate: config.CookieTemplate{Name: "__Host-Http-SESSION"},
+		},
+		nil,
+		sessionmock.NewInMemRepository(),
+		nil,
+	)
+	require.NoError(t, err)
+
+	return manager
+}
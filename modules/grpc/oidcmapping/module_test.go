@@ -28,6 +28,10 @@ func (stubTrust) Get(context.Context, string) (*trustv1.Trust, error) {
 	return nil, errStubTrustGet
 }
 
+func (stubTrust) ListByIssuer(context.Context, string) ([]*trustv1.Trust, error) {
+	return nil, errStubTrustGet
+}
+
 type stubTrustModule struct {
 	stubTrust
 
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc/status"
+
+	rpcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/rpc/v1"
+	slogctx "github.com/veqryn/slog-context"
+	grpccodes "google.golang.org/grpc/codes"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	csrfv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/csrf/v1"
+)
+
+// CSRFValidator validates CSRF tokens against the CSRF secret, e.g.
+// *internalsession.Manager.
+type CSRFValidator interface {
+	ValidateCSRFToken(token, sessionID string) bool
+}
+
+// CSRFServer implements the kms.api.cmk.sessionmanager.csrf.v1.Service proto.
+type CSRFServer struct {
+	csrfv1.UnimplementedServiceServer
+
+	sessionRepo internalsession.Repository
+	trust       sessionmanager.Trust
+	validator   CSRFValidator
+}
+
+func NewCSRFServer(sessionRepo internalsession.Repository, trust sessionmanager.Trust, validator CSRFValidator) *CSRFServer {
+	return &CSRFServer{
+		sessionRepo: sessionRepo,
+		trust:       trust,
+		validator:   validator,
+	}
+}
+
+// ValidateCSRF performs the double-submit check of the CSRF token of an active
+// session of a tenant which is not blocked: the token must equal the token of
+// the session and be signed with the CSRF secret for the session.
+func (s *CSRFServer) ValidateCSRF(ctx context.Context, req *csrfv1.ValidateCSRFRequest) (*csrfv1.ValidateCSRFResponse, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "validate_csrf")
+	defer span.End()
+
+	if req.GetSessionId() == "" || req.GetTenantId() == "" {
+		span.SetStatus(codes.Ok, "missing session or tenant id")
+		return nil, status.Error(grpccodes.InvalidArgument, "session_id and tenant_id are required")
+	}
+
+	active, err := s.sessionRepo.IsActive(ctx, req.GetSessionId())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to check session state")
+		slogctx.Error(ctx, "Could not check the session state", "error", err)
+		return nil, status.Error(grpccodes.Internal, "failed to check the session state")
+	}
+	if !active {
+		span.SetStatus(codes.Ok, "inactive session")
+		return nil, sessionInvalid(ctx, req.GetSessionId())
+	}
+
+	sess, err := s.sessionRepo.LoadSession(ctx, req.GetSessionId())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Ok, "failed to load a session")
+		slogctx.Warn(ctx, "Is this an attack? Could not load session", "error", err)
+		return nil, sessionInvalid(ctx, req.GetSessionId())
+	}
+
+	if sess.TenantID != req.GetTenantId() {
+		span.SetStatus(codes.Ok, "tenant id mismatch")
+		slogctx.Warn(ctx, "Is this an attack? Tenant IDs do not match", "sessionTenantId", sess.TenantID, "requestTenantId", req.GetTenantId())
+		return nil, sessionInvalid(ctx, req.GetSessionId())
+	}
+
+	trust, err := s.trust.Get(ctx, req.GetTenantId())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Ok, "failed to get trust")
+		slogctx.Warn(ctx, "Is this an attack? Could not get trust", "error", err)
+		return nil, sessionInvalid(ctx, req.GetSessionId())
+	}
+	if trust.GetBlocked() {
+		span.SetStatus(codes.Ok, "the tenant is blocked")
+		slogctx.Warn(ctx, "Tenant is blocked")
+		return nil, preconditionFailure(ctx, "the tenant is blocked", &rpcv1.PreconditionFailure_Violation{
+			Type:        violationTenantBlocked,
+			Subject:     "tenant:" + req.GetTenantId(),
+			Description: "The tenant is blocked",
+		})
+	}
+
+	if sess.CSRFToken == "" ||
+		subtle.ConstantTimeCompare([]byte(req.GetToken()), []byte(sess.CSRFToken)) != 1 ||
+		!s.validator.ValidateCSRFToken(req.GetToken(), req.GetSessionId()) {
+		span.SetStatus(codes.Ok, "csrf token mismatch")
+		slogctx.Warn(ctx, "Is this an attack? CSRF token does not match")
+		return nil, preconditionFailure(ctx, "the csrf token does not match", &rpcv1.PreconditionFailure_Violation{
+			Type:        violationCSRFMismatch,
+			Subject:     "session:" + req.GetSessionId(),
+			Description: "The CSRF token does not match the session",
+		})
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return &csrfv1.ValidateCSRFResponse{}, nil
+}
+
+// sessionInvalid returns the error of a session which is not valid. It does not
+// reveal why, e.g. whether the session exists for another tenant.
+func sessionInvalid(ctx context.Context, sessionID string) error {
+	return preconditionFailure(ctx, "the session is invalid", &rpcv1.PreconditionFailure_Violation{
+		Type:        violationSessionInvalid,
+		Subject:     "session:" + sessionID,
+		Description: "The session is invalid",
+	})
+}
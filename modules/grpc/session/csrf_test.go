@@ -0,0 +1,182 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/csrf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	rpcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/rpc/v1"
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+
+	"github.com/openkcm/session-manager/internal/config"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	"github.com/openkcm/session-manager/modules/grpc/session"
+	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+	csrfv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/csrf/v1"
+)
+
+func TestValidateCSRF(t *testing.T) {
+	const (
+		sessionID = "session-123"
+		tenantID  = "tenant-123"
+	)
+
+	csrfSecret := []byte("0123456789abcdef0123456789abcdef")
+	csrfToken := csrf.NewToken(sessionID, csrfSecret)
+
+	tests := []struct {
+		name          string
+		sessionToken  string
+		inactive      bool
+		blocked       bool
+		req           *csrfv1.ValidateCSRFRequest
+		wantCode      codes.Code
+		wantViolation string
+		wantSubject   string
+	}{
+		{
+			name:         "Valid token",
+			sessionToken: csrfToken,
+			req:          newValidateCSRFRequest(sessionID, tenantID, csrfToken),
+			wantCode:     codes.OK,
+		},
+		{
+			name:          "Token of another session",
+			sessionToken:  csrfToken,
+			req:           newValidateCSRFRequest(sessionID, tenantID, csrf.NewToken("other-session", csrfSecret)),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "csrf_token_mismatch",
+			wantSubject:   "session:" + sessionID,
+		},
+		{
+			name:          "Token signed with another secret",
+			sessionToken:  csrf.NewToken(sessionID, []byte("another-secret-another-secret-00")),
+			req:           newValidateCSRFRequest(sessionID, tenantID, csrf.NewToken(sessionID, []byte("another-secret-another-secret-00"))),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "csrf_token_mismatch",
+			wantSubject:   "session:" + sessionID,
+		},
+		{
+			name:          "Missing token",
+			sessionToken:  csrfToken,
+			req:           newValidateCSRFRequest(sessionID, tenantID, ""),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "csrf_token_mismatch",
+			wantSubject:   "session:" + sessionID,
+		},
+		{
+			name:          "Session without token",
+			req:           newValidateCSRFRequest(sessionID, tenantID, ""),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "csrf_token_mismatch",
+			wantSubject:   "session:" + sessionID,
+		},
+		{
+			name:          "Unknown session",
+			sessionToken:  csrfToken,
+			req:           newValidateCSRFRequest("unknown-session", tenantID, csrfToken),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "session_invalid",
+			wantSubject:   "session:unknown-session",
+		},
+		{
+			name:          "Session of another tenant",
+			sessionToken:  csrfToken,
+			req:           newValidateCSRFRequest(sessionID, "other-tenant", csrfToken),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "session_invalid",
+			wantSubject:   "session:" + sessionID,
+		},
+		{
+			name:          "Inactive session",
+			sessionToken:  csrfToken,
+			inactive:      true,
+			req:           newValidateCSRFRequest(sessionID, tenantID, csrfToken),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "session_invalid",
+			wantSubject:   "session:" + sessionID,
+		},
+		{
+			name:          "Blocked tenant",
+			sessionToken:  csrfToken,
+			blocked:       true,
+			req:           newValidateCSRFRequest(sessionID, tenantID, csrfToken),
+			wantCode:      codes.FailedPrecondition,
+			wantViolation: "tenant_blocked",
+			wantSubject:   "tenant:" + tenantID,
+		},
+		{
+			name:         "Missing session ID",
+			sessionToken: csrfToken,
+			req:          newValidateCSRFRequest("", tenantID, csrfToken),
+			wantCode:     codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionRepo := sessionmock.NewInMemRepository(sessionmock.WithSession(internalsession.Session{
+				ID:        sessionID,
+				TenantID:  tenantID,
+				CSRFToken: tt.sessionToken,
+				Expiry:    time.Now().Add(time.Hour),
+			}))
+			if !tt.inactive {
+				require.NoError(t, sessionRepo.BumpActive(t.Context(), sessionID, time.Hour))
+			}
+
+			trust := newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(tt.blocked),
+				Oidc: oidcv1.OIDC_builder{
+					Issuer:   new("https://issuer.example.com"),
+					ClientId: new("test-client-id"),
+				}.Build(),
+			}.Build())))
+
+			manager, err := internalsession.NewManager(t.Context(),
+				&config.SessionManager{CSRFSecretParsed: csrfSecret},
+				nil,
+				sessionRepo,
+				nil,
+			)
+			require.NoError(t, err)
+
+			server := session.NewCSRFServer(sessionRepo, trust, manager)
+
+			resp, err := server.ValidateCSRF(t.Context(), tt.req)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantCode, st.Code(), "error: %v", err)
+			if tt.wantCode == codes.OK {
+				assert.NotNil(t, resp)
+				return
+			}
+			assert.Nil(t, resp)
+
+			if tt.wantViolation != "" {
+				details := st.Details()
+				require.Len(t, details, 1)
+				pf, ok := details[0].(*rpcv1.PreconditionFailure)
+				require.True(t, ok)
+				require.Len(t, pf.GetViolations(), 1)
+				assert.Equal(t, tt.wantViolation, pf.GetViolations()[0].GetType())
+				assert.Equal(t, tt.wantSubject, pf.GetViolations()[0].GetSubject())
+			}
+		})
+	}
+}
+
+func newValidateCSRFRequest(sessionID, tenantID, token string) *csrfv1.ValidateCSRFRequest {
+	return &csrfv1.ValidateCSRFRequest{
+		SessionId: new(sessionID),
+		TenantId:  new(tenantID),
+		Token:     new(token),
+	}
+}
@@ -1,21 +1,25 @@
 // Package session provides the service.module.grpc.session module: a gRPC
 // service module that registers the kms.api.cmk.sessionmanager.session.v1.Service
-// proto onto a grpc.ServiceRegistrar supplied by app.module.grpcserver.
+// and kms.api.cmk.sessionmanager.csrf.v1.Service protos onto a
+// grpc.ServiceRegistrar supplied by app.module.grpcserver.
 package session
 
 import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"google.golang.org/grpc"
 
 	sessionv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/sessionmanager/session/v1"
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
 
 	sessionmanager "github.com/openkcm/session-manager"
 	"github.com/openkcm/session-manager/internal/config"
 	"github.com/openkcm/session-manager/internal/credentials"
 	internalsession "github.com/openkcm/session-manager/internal/session"
+	csrfv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/csrf/v1"
 )
 
 const moduleID = "service.module.grpc.session"
@@ -47,8 +51,24 @@ type Module struct {
 
 	AllowHttpScheme           bool     `yaml:"allowHttpScheme"`
 	QueryParametersIntrospect []string `yaml:"queryParametersIntrospect"`
+	// TokenRefreshWindow defines the duration before token expiry when GetSession
+	// refreshes the access token of a session.
+	TokenRefreshWindow time.Duration `yaml:"tokenRefreshWindow" default:"1m"`
+	// AccessTokenRelay returns the access token of the session to allowed
+	// callers of GetSession. It is disabled by default.
+	AccessTokenRelay AccessTokenRelay `yaml:"accessTokenRelay"`
+
+	server     *Server
+	csrfServer *CSRFServer
+}
 
-	server *Server
+// AccessTokenRelay configures relaying the access token of the session in the
+// response header metadata of GetSession.
+type AccessTokenRelay struct {
+	Enabled bool `yaml:"enabled"`
+	// AllowedCallers are the identities of the callers which receive the access
+	// token: the common name or a DNS or URI SAN of their client certificate.
+	AllowedCallers []string `yaml:"allowedCallers"`
 }
 
 func (m *Module) Module() sessionmanager.ModuleInfo {
@@ -64,6 +84,12 @@ func (m *Module) Provision(ctx *sessionmanager.Context) error {
 		return errors.New("config not found in context")
 	}
 
+	// The session manager depends on the secrets, which are usually loaded
+	// by the caller of LoadAll already
+	if err := cfg.SessionManager.LoadSecrets(); err != nil {
+		return fmt.Errorf("loading session manager secrets: %w", err)
+	}
+
 	trust, err := sessionmanager.GetModuleAs[sessionmanager.Trust](ctx, m.Trust)
 	if err != nil {
 		return fmt.Errorf("getting trust module %q: %w", m.Trust, err)
@@ -79,13 +105,37 @@ func (m *Module) Provision(ctx *sessionmanager.Context) error {
 		return fmt.Errorf("getting credentials module %q: %w", m.Credentials, err)
 	}
 
+	auditLogger, err := otlpaudit.NewLogger(&cfg.Audit)
+	if err != nil {
+		return fmt.Errorf("creating audit logger: %w", err)
+	}
+
+	manager, err := internalsession.NewManager(ctx,
+		&cfg.SessionManager,
+		trust,
+		repo,
+		auditLogger,
+		internalsession.WithTransportCredentials(creds.Builder()),
+		internalsession.WithAllowHttpScheme(m.AllowHttpScheme),
+	)
+	if err != nil {
+		return fmt.Errorf("creating session manager: %w", err)
+	}
+
 	opts := []Option{
 		WithTransportCredentials(creds.Builder()),
 		WithAllowHttpScheme(m.AllowHttpScheme),
+		WithTokenRefresh(manager, m.TokenRefreshWindow),
 	}
 	if m.QueryParametersIntrospect != nil {
 		opts = append(opts, WithQueryParametersIntrospect(m.QueryParametersIntrospect))
 	}
+	if m.AccessTokenRelay.Enabled {
+		if len(m.AccessTokenRelay.AllowedCallers) == 0 {
+			return errors.New("accessTokenRelay requires at least one allowed caller")
+		}
+		opts = append(opts, WithAccessTokenRelay(m.AccessTokenRelay.AllowedCallers))
+	}
 
 	m.server = NewServer(
 		ctx,
@@ -94,10 +144,12 @@ func (m *Module) Provision(ctx *sessionmanager.Context) error {
 		cfg.SessionManager.IdleSessionTimeout,
 		opts...,
 	)
+	m.csrfServer = NewCSRFServer(repo, trust, manager)
 
 	return nil
 }
 
 func (m *Module) Register(s grpc.ServiceRegistrar) {
 	sessionv1.RegisterServiceServer(s, m.server)
+	csrfv1.RegisterServiceServer(s, m.csrfServer)
 }
@@ -0,0 +1,148 @@
+package session_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/csrf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/config"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	csrfv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/csrf/v1"
+)
+
+// stubTrustModule is a trust module with an unblocked trust for every tenant.
+type stubTrustModule struct{ id string }
+
+func (s *stubTrustModule) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  s.id,
+		New: func() sessionmanager.Module { return s },
+	}
+}
+
+func (*stubTrustModule) Apply(context.Context, *trustv1.Trust) error { return nil }
+func (*stubTrustModule) Block(context.Context, string) error         { return nil }
+func (*stubTrustModule) Remove(context.Context, string) error        { return nil }
+func (*stubTrustModule) Unblock(context.Context, string) error       { return nil }
+func (*stubTrustModule) Get(context.Context, string) (*trustv1.Trust, error) {
+	return &trustv1.Trust{}, nil
+}
+func (*stubTrustModule) ListByIssuer(context.Context, string) ([]*trustv1.Trust, error) {
+	return nil, nil
+}
+
+// serviceRegistrar captures the implementations of the registered services.
+type serviceRegistrar struct{ impls []any }
+
+func (r *serviceRegistrar) RegisterService(_ *grpc.ServiceDesc, impl any) {
+	r.impls = append(r.impls, impl)
+}
+
+func TestModule_ProvisionFromConfig(t *testing.T) {
+	const (
+		csrfSecret = "0123456789abcdef0123456789abcdef"
+		tenantID   = "tenant-123"
+	)
+
+	trustID := "trust.module.test." + t.Name()
+	sessionmanager.RegisterModule(&stubTrustModule{id: trustID})
+
+	yaml := `
+trust:
+  module: ` + trustID + `
+valkey:
+  module: sessionstore.module.memory
+  prefix: ` + t.Name() + `
+sessionManager:
+  callbackURL: http://localhost/sm/callback
+  clientAuth:
+    type: insecure
+  csrfSecret:
+    source: embedded
+    value: ` + csrfSecret + `
+apps:
+  grpc:
+    module: app.module.grpcserver
+    services:
+      - module: service.module.grpc.session
+        trust: ` + trustID + `
+        sessionStore: sessionstore.module.memory
+`
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0o600))
+
+	cfg, err := config.Load("", dir)
+	require.NoError(t, err)
+
+	ctx, cancel := sessionmanager.NewContext(t.Context())
+	defer cancel(nil)
+	ctx = config.WithContext(ctx, cfg)
+
+	require.NoError(t, ctx.LoadAll([]sessionmanager.LoadSpec{
+		{Cfg: &cfg.Trust},
+		{Cfg: &cfg.ValKey},
+		{Cfg: &cfg.Credentials},
+		{Cfg: cfg.Apps["grpc"].Services[0]},
+	}))
+
+	service, err := sessionmanager.GetModuleAs[interface{ Register(grpc.ServiceRegistrar) }](ctx, "service.module.grpc.session")
+	require.NoError(t, err)
+	registrar := &serviceRegistrar{}
+	service.Register(registrar)
+	var server csrfv1.ServiceServer
+	for _, impl := range registrar.impls {
+		if s, ok := impl.(csrfv1.ServiceServer); ok {
+			server = s
+		}
+	}
+	require.NotNil(t, server)
+
+	repo, err := sessionmanager.GetModuleAs[internalsession.Repository](ctx, "sessionstore.module.memory")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		sessionID string
+		secret    []byte
+		wantCode  codes.Code
+	}{
+		{
+			name:      "CSRF token signed with the configured secret",
+			sessionID: "session-configured-secret",
+			secret:    []byte(csrfSecret),
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "CSRF token signed with an empty secret",
+			sessionID: "session-empty-secret",
+			wantCode:  codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := csrf.NewToken(tt.sessionID, tt.secret)
+			require.NoError(t, repo.StoreSession(ctx, internalsession.Session{
+				ID:        tt.sessionID,
+				TenantID:  tenantID,
+				CSRFToken: token,
+				Expiry:    time.Now().Add(time.Hour),
+			}))
+			require.NoError(t, repo.BumpActive(ctx, tt.sessionID, time.Hour))
+
+			_, err := server.ValidateCSRF(ctx, newValidateCSRFRequest(tt.sessionID, tenantID, token))
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
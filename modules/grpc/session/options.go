@@ -1,6 +1,10 @@
 package session
 
-import "github.com/openkcm/session-manager/internal/credentials"
+import (
+	"time"
+
+	"github.com/openkcm/session-manager/internal/credentials"
+)
 
 type Option func(*Server)
 
@@ -21,3 +25,21 @@ func WithTransportCredentials(b credentials.Builder) Option {
 		s.newCreds = b
 	}
 }
+
+// WithTokenRefresh enables the refresh of access tokens which expire within
+// the given window when a session is requested.
+func WithTokenRefresh(r SessionRefresher, window time.Duration) Option {
+	return func(s *Server) {
+		s.refresher = r
+		s.tokenRefreshWindow = window
+	}
+}
+
+// WithAccessTokenRelay enables relaying the access token of the session to the
+// given callers in the response header metadata of GetSession. Callers are
+// identified by the common name or a DNS or URI SAN of their client certificate.
+func WithAccessTokenRelay(allowedCallers []string) Option {
+	return func(s *Server) {
+		s.accessTokenRelayCallers = allowedCallers
+	}
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+// MetadataKeyAccessToken is the key of the response header metadata of
+// GetSession which holds the access token of the session if it is relayed.
+const MetadataKeyAccessToken = "x-session-access-token"
+
+// relayAccessToken sends the current access token of the session in the
+// response header metadata if the caller is allowed to receive it. The token
+// is read from the repository as it may have been refreshed meanwhile.
+func (s *Server) relayAccessToken(ctx context.Context, sessionID string) error {
+	if len(s.accessTokenRelayCallers) == 0 {
+		return nil
+	}
+
+	identities := CallerIdentities(ctx)
+	if !slices.ContainsFunc(identities, func(id string) bool {
+		return slices.Contains(s.accessTokenRelayCallers, id)
+	}) {
+		slogctx.Debug(ctx, "Caller is not allowed to receive access tokens", "identities", identities)
+		return nil
+	}
+
+	accessToken, err := s.sessionRepo.GetAccessTokenForSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return grpc.SetHeader(ctx, metadata.Pairs(MetadataKeyAccessToken, accessToken))
+}
+
+// CallerIdentities returns the identities of the caller taken from its verified
+// client certificate: the common name and the DNS and URI SANs, e.g. SPIFFE IDs.
+func CallerIdentities(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	identities := make([]string, 0, 1+len(cert.DNSNames)+len(cert.URIs))
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	identities = append(identities, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+
+	return identities
+}
@@ -4,34 +4,51 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/openkcm/common-sdk/pkg/oidc"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
-	"google.golang.org/grpc/status"
 
 	rpcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/rpc/v1"
 	sessionv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/sessionmanager/session/v1"
 	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
 	typesv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/types/v1"
 	slogctx "github.com/veqryn/slog-context"
-	grpccodes "google.golang.org/grpc/codes"
 
 	sessionmanager "github.com/openkcm/session-manager"
 	"github.com/openkcm/session-manager/internal/credentials"
 	"github.com/openkcm/session-manager/internal/debugtools"
 	internalsession "github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
 )
 
 const defaultIntrospectionCacheExpiration = 30 * time.Second
 
+// Keys of the authentication strength in the auth context of a session.
+const (
+	AuthContextKeyACR      = "acr"
+	AuthContextKeyAMR      = "amr"
+	AuthContextKeyAuthTime = "auth_time"
+)
+
 var debugSettingSMDumpTransport = debugtools.NewSetting("smdumptransport")
 
+// SessionRefresher refreshes the access token of a session, e.g. *internalsession.Manager.
+type SessionRefresher interface {
+	RefreshExpiringSession(ctx context.Context, s internalsession.Session, window time.Duration) (internalsession.Session, error)
+}
+
 type Server struct {
 	sessionv1.UnimplementedServiceServer
 
@@ -43,8 +60,23 @@ type Server struct {
 	idleSessionTimeout        time.Duration
 	allowHttpScheme           bool
 
+	// refreshes access tokens which expire within the window
+	refresher          SessionRefresher
+	tokenRefreshWindow time.Duration
+
+	// identities of the callers which receive the access token of the session
+	accessTokenRelayCallers []string
+
 	// cache introspection results
-	introspectionCache *ttlcache.Cache[string, oidc.Introspection]
+	introspectionCache *ttlcache.Cache[string, introspection]
+}
+
+// introspection is the result of a token introspection along with all claims
+// of the introspection response.
+type introspection struct {
+	oidc.Introspection
+
+	Claims map[string]any
 }
 
 func NewServer(
@@ -66,7 +98,7 @@ func NewServer(
 		}
 	}
 
-	s.introspectionCache = ttlcache.New(ttlcache.WithTTL[string, oidc.Introspection](defaultIntrospectionCacheExpiration))
+	s.introspectionCache = ttlcache.New(ttlcache.WithTTL[string, introspection](defaultIntrospectionCacheExpiration))
 	go s.introspectionCache.Start()
 	context.AfterFunc(ctx, s.introspectionCache.Stop)
 
@@ -114,22 +146,11 @@ func (s *Server) GetSession(ctx context.Context, req *sessionv1.GetSessionReques
 	if trust.GetBlocked() {
 		slogctx.Warn(ctx, "Tenant is blocked", "issuer", sess.Issuer)
 		span.SetStatus(codes.Ok, "the tenant is blocked")
-		st := status.New(grpccodes.FailedPrecondition, "the tenant is blocked")
-		dt, err := st.WithDetails(&rpcv1.PreconditionFailure{
-			Violations: []*rpcv1.PreconditionFailure_Violation{
-				{
-					Type:        violationTenantBlocked,
-					Subject:     "tenant:" + req.GetTenantId(),
-					Description: "The tenant is blocked",
-				},
-			},
+		return nil, preconditionFailure(ctx, "the tenant is blocked", &rpcv1.PreconditionFailure_Violation{
+			Type:        violationTenantBlocked,
+			Subject:     "tenant:" + req.GetTenantId(),
+			Description: "The tenant is blocked",
 		})
-		if err != nil {
-			slogctx.Error(ctx, "Failed to add error details", "error", err)
-			return nil, st.Err()
-		}
-
-		return nil, dt.Err()
 	}
 
 	// Compare tenant IDs
@@ -139,15 +160,28 @@ func (s *Server) GetSession(ctx context.Context, req *sessionv1.GetSessionReques
 		return &sessionv1.GetSessionResponse{Valid: false}, nil
 	}
 
+	// Refresh the access token if it has expired or is about to expire. If the
+	// refresh fails, the introspection decides on the current access token.
+	if s.refresher != nil {
+		refreshed, err := s.refresher.RefreshExpiringSession(ctx, sess, s.tokenRefreshWindow)
+		if errors.Is(err, serviceerr.ErrInvalidGrant) {
+			// The provider rejected the refresh token and the session has ended
+			span.SetStatus(codes.Ok, "refresh token rejected")
+			slogctx.Warn(ctx, "Refresh token has been rejected", "error", err)
+			return &sessionv1.GetSessionResponse{Valid: false}, nil
+		}
+		if err != nil {
+			span.RecordError(err)
+			slogctx.Error(ctx, "Could not refresh the access token", "error", err)
+		} else {
+			sess = refreshed
+		}
+	}
+
 	response := &sessionv1.GetSessionResponse{
 		Valid:       true,
 		Issuer:      sess.Issuer,
-		Subject:     sess.Claims.Subject,
-		GivenName:   sess.Claims.GivenName,
-		FamilyName:  sess.Claims.FamilyName,
-		Email:       sess.Claims.Email,
-		Groups:      sess.Claims.Groups,
-		AuthContext: sess.AuthContext,
+		AuthContext: authContext(sess),
 	}
 
 	// Introspect access token
@@ -164,10 +198,29 @@ func (s *Server) GetSession(ctx context.Context, req *sessionv1.GetSessionReques
 		return &sessionv1.GetSessionResponse{Valid: false}, nil
 	}
 
+	claims := sess.Claims
 	if result.Groups != nil {
-		response.Groups = result.Groups
+		claims.Groups = result.Groups
+	}
+
+	// Apply the claim mapping of the trust to the introspection result
+	claimMapping, err := internalsession.ClaimMappingOf(trust.GetOidc())
+	if err == nil {
+		err = claimMapping.Apply(result.Claims, &claims)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to map the introspected claims")
+		slogctx.Error(ctx, "Could not map the introspected claims", "error", err)
+		return &sessionv1.GetSessionResponse{Valid: false}, nil
 	}
 
+	response.Subject = claims.Subject
+	response.GivenName = claims.GivenName
+	response.FamilyName = claims.FamilyName
+	response.Email = claims.Email
+	response.Groups = claims.Groups
+
 	// Bump the session to keep it active
 	if err := s.sessionRepo.BumpActive(ctx, req.GetSessionId(), s.idleSessionTimeout); err != nil {
 		span.RecordError(err)
@@ -176,11 +229,41 @@ func (s *Server) GetSession(ctx context.Context, req *sessionv1.GetSessionReques
 		return &sessionv1.GetSessionResponse{Valid: false}, nil
 	}
 
+	// Relay the access token to allowed callers, e.g. to call APIs on behalf of the user
+	if err := s.relayAccessToken(ctx, req.GetSessionId()); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to relay the access token")
+		slogctx.Error(ctx, "Could not relay the access token", "error", err)
+		return &sessionv1.GetSessionResponse{Valid: false}, nil
+	}
+
 	// Return info of the valid session
 	span.SetStatus(codes.Ok, "")
 	return response, nil
 }
 
+// authContext returns the auth context of the session along with the strength
+// of the user authentication. The authentication methods are separated by spaces
+// and the authentication time is given in seconds since the Unix epoch.
+func authContext(sess internalsession.Session) map[string]string {
+	authCtx := maps.Clone(sess.AuthContext)
+	if authCtx == nil {
+		authCtx = make(map[string]string, 3)
+	}
+
+	if sess.ACR != "" {
+		authCtx[AuthContextKeyACR] = sess.ACR
+	}
+	if len(sess.AMR) > 0 {
+		authCtx[AuthContextKeyAMR] = strings.Join(sess.AMR, " ")
+	}
+	if !sess.AuthTime.IsZero() {
+		authCtx[AuthContextKeyAuthTime] = strconv.FormatInt(sess.AuthTime.Unix(), 10)
+	}
+
+	return authCtx
+}
+
 // GetOIDCProvider implements a compatibility level with the OIDC API.
 // Deprecated: use GetTrust instead.
 // TODO: remove this method once the lifecycle of deprecated and compatibility layers is reached to the end.
@@ -240,7 +323,7 @@ func (s *Server) httpClient(clientID string) (*http.Client, error) {
 	}, nil
 }
 
-func (s *Server) introspectToken(ctx context.Context, token string, oidcTrust *oidcv1.OIDC) (oidc.Introspection, error) {
+func (s *Server) introspectToken(ctx context.Context, token string, oidcTrust *oidcv1.OIDC) (introspection, error) {
 	// first check the cache for a recent introspection result for this token
 	hashedSuffix := sha256.Sum256([]byte(token))
 	cacheKey := base64.RawURLEncoding.EncodeToString(hashedSuffix[:])
@@ -251,7 +334,7 @@ func (s *Server) introspectToken(ctx context.Context, token string, oidcTrust *o
 	httpClient, err := s.httpClient(oidcTrust.GetClientId())
 	if err != nil {
 		slogctx.Error(ctx, "Could not create HTTP client for OpenID provider", "issuer", oidcTrust.GetIssuer(), "error", err)
-		return oidc.Introspection{Active: false}, err
+		return introspection{}, err
 	}
 
 	// create the provider for the given issuer
@@ -261,18 +344,25 @@ func (s *Server) introspectToken(ctx context.Context, token string, oidcTrust *o
 	)
 	if err != nil {
 		slogctx.Error(ctx, "Could not create OpenID provider", "issuer", oidcTrust.GetIssuer(), "error", err)
-		return oidc.Introspection{Active: false}, err
+		return introspection{}, err
+	}
+
+	cfg, err := provider.GetConfiguration(ctx)
+	if err != nil {
+		slogctx.Error(ctx, "Could not get OpenID configuration", "issuer", provider.Issuer, "error", err)
+		return introspection{}, err
+	}
+
+	if cfg.IntrospectionEndpoint == "" {
+		slogctx.Debug(ctx, "No introspection endpoint configured", "issuer", provider.Issuer)
+		return introspection{Introspection: oidc.Introspection{Active: true}}, nil
 	}
 
 	// introspect the token
-	intr, err := provider.IntrospectToken(ctx, token)
+	intr, err := introspect(ctx, httpClient, cfg.IntrospectionEndpoint, token)
 	if err != nil {
-		if errors.Is(err, oidc.ErrNoIntrospectionEndpoint) {
-			slogctx.Debug(ctx, "No introspection endpoint configured", "issuer", provider.Issuer)
-			return oidc.Introspection{Active: true}, nil
-		}
 		slogctx.Error(ctx, "Could not introspect token", "error", err)
-		return oidc.Introspection{Active: false}, err
+		return introspection{}, err
 	}
 
 	// Cache the result with TTL
@@ -280,3 +370,42 @@ func (s *Server) introspectToken(ctx context.Context, token string, oidcTrust *o
 
 	return intr, nil
 }
+
+// introspect requests the introspection of the token as described in
+// https://datatracker.ietf.org/doc/html/rfc7662.
+func introspect(ctx context.Context, client *http.Client, endpoint, token string) (introspection, error) {
+	body := url.Values{}
+	body.Set("token", token)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(body.Encode()))
+	if err != nil {
+		return introspection{}, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return introspection{}, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return introspection{}, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return introspection{}, fmt.Errorf("introspection failed with status: %d", resp.StatusCode)
+	}
+
+	var intr introspection
+	if err := json.Unmarshal(respBody, &intr.Introspection); err != nil {
+		return introspection{}, fmt.Errorf("decoding response: %w", err)
+	}
+	if err := json.Unmarshal(respBody, &intr.Claims); err != nil {
+		return introspection{}, fmt.Errorf("decoding response claims: %w", err)
+	}
+
+	return intr, nil
+}
@@ -1,28 +1,42 @@
 package session_test
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/openkcm/common-sdk/pkg/oidc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	rpcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/rpc/v1"
 	sessionv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/sessionmanager/session/v1"
 	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
 	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
 
+	"github.com/openkcm/session-manager/internal/config"
 	internalsession "github.com/openkcm/session-manager/internal/session"
 	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
 	"github.com/openkcm/session-manager/modules/grpc/session"
 	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
 )
 
 func TestNewSessionServer(t *testing.T) {
@@ -148,6 +162,74 @@ func TestGetSession(t *testing.T) {
 		assert.Equal(t, map[string]string{"key": "value"}, resp.GetAuthContext())
 	})
 
+	t.Run("success - authentication strength in auth context", func(t *testing.T) {
+		var testServer *httptest.Server
+		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/.well-known/openid-configuration":
+				_ = json.NewEncoder(w).Encode(oidc.Configuration{
+					Issuer:                testServer.URL,
+					IntrospectionEndpoint: testServer.URL + "/introspect",
+				})
+			case "/introspect":
+				_ = json.NewEncoder(w).Encode(oidc.Introspection{
+					Active: true,
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer testServer.Close()
+
+		sess := internalsession.Session{
+			ID:          "session-123",
+			TenantID:    "tenant-123",
+			Issuer:      testServer.URL,
+			AccessToken: "access-token-123",
+			Claims:      internalsession.Claims{Subject: "user-123"},
+			AuthContext: map[string]string{"key": "value"},
+			ACR:         "urn:mfa",
+			AMR:         []string{"pwd", "hwk"},
+			AuthTime:    time.Unix(1700000000, 0),
+		}
+
+		trustData := trustv1.Trust_builder{
+			TenantId: new(sess.TenantID),
+			Blocked:  new(false),
+			Oidc: oidcv1.OIDC_builder{
+				Issuer:   new(testServer.URL),
+				ClientId: new("test-client-id"),
+			}.Build(),
+		}.Build()
+
+		sessionRepo := sessionmock.NewInMemRepository(
+			sessionmock.WithSession(sess),
+		)
+		_ = sessionRepo.BumpActive(ctx, sess.ID, 1*time.Hour)
+
+		server := session.NewServer(ctx, sessionRepo, newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustData))), 90*time.Minute,
+			session.WithAllowHttpScheme(true),
+		)
+
+		resp, err := server.GetSession(ctx, &sessionv1.GetSessionRequest{
+			SessionId: "session-123",
+			TenantId:  "tenant-123",
+		})
+
+		require.NoError(t, err)
+		assert.True(t, resp.GetValid())
+		assert.Equal(t, map[string]string{
+			"key":                          "value",
+			session.AuthContextKeyACR:      "urn:mfa",
+			session.AuthContextKeyAMR:      "pwd hwk",
+			session.AuthContextKeyAuthTime: "1700000000",
+		}, resp.GetAuthContext())
+
+		stored, err := sessionRepo.LoadSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"key": "value"}, stored.AuthContext, "the stored auth context must not be modified")
+	})
+
 	t.Run("success - introspection returns groups overriding session groups", func(t *testing.T) {
 		// Setup test server for OIDC endpoints that returns groups in introspection
 		var testServer *httptest.Server
@@ -214,6 +296,99 @@ func TestGetSession(t *testing.T) {
 		assert.Equal(t, []string{"introspect-group1", "introspect-group2"}, resp.GetGroups())
 	})
 
+	t.Run("claim mapping of the trust applies to introspection claims", func(t *testing.T) {
+		var testServer *httptest.Server
+		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/.well-known/openid-configuration":
+				_ = json.NewEncoder(w).Encode(oidc.Configuration{
+					Issuer:                testServer.URL,
+					IntrospectionEndpoint: testServer.URL + "/introspect",
+				})
+			case "/introspect":
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"active":         true,
+					"oid":            "object-id",
+					"email_verified": true,
+					"realm_access":   map[string]any{"roles": []string{"viewer", "editor"}},
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer testServer.Close()
+
+		tests := []struct {
+			name       string
+			mapping    *smoidcv1.ClaimMapping
+			wantValid  bool
+			wantSub    string
+			wantGroups []string
+		}{
+			{
+				name:       "mapped claims override session claims",
+				mapping:    &smoidcv1.ClaimMapping{Subject: new("oid"), Groups: new("realm_access.roles")},
+				wantValid:  true,
+				wantSub:    "object-id",
+				wantGroups: []string{"viewer", "editor"},
+			},
+			{
+				name:    "invalid mapped claim fails closed",
+				mapping: &smoidcv1.ClaimMapping{Email: new("email_verified")},
+			},
+		}
+		for i, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				sess := internalsession.Session{
+					ID:          "session-claim-mapping",
+					TenantID:    "tenant-claim-mapping",
+					Issuer:      testServer.URL,
+					AccessToken: fmt.Sprintf("access-token-claim-mapping-%d", i),
+					Claims: internalsession.Claims{
+						Subject: "user-claim-mapping",
+						Email:   "jane.doe@example.com",
+						Groups:  []string{"session-group"},
+					},
+				}
+
+				oidcTrust := oidcv1.OIDC_builder{
+					Issuer:   new(testServer.URL),
+					ClientId: new("test-client-id"),
+				}.Build()
+				proto.SetExtension(oidcTrust, smoidcv1.E_ClaimMapping, tt.mapping)
+				trustData := trustv1.Trust_builder{
+					TenantId: new(sess.TenantID),
+					Blocked:  new(false),
+					Oidc:     oidcTrust,
+				}.Build()
+
+				sessionRepo := sessionmock.NewInMemRepository(
+					sessionmock.WithSession(sess),
+				)
+				_ = sessionRepo.BumpActive(ctx, sess.ID, 1*time.Hour)
+
+				trust := newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustData)))
+				server := session.NewServer(ctx, sessionRepo, trust, 90*time.Minute,
+					session.WithAllowHttpScheme(true),
+				)
+
+				resp, err := server.GetSession(ctx, &sessionv1.GetSessionRequest{
+					SessionId: sess.ID,
+					TenantId:  sess.TenantID,
+				})
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantValid, resp.GetValid())
+				if !tt.wantValid {
+					return
+				}
+				assert.Equal(t, tt.wantSub, resp.GetSubject())
+				assert.Equal(t, "jane.doe@example.com", resp.GetEmail())
+				assert.Equal(t, tt.wantGroups, resp.GetGroups())
+			})
+		}
+	})
+
 	t.Run("success - valid session without introspection endpoint", func(t *testing.T) {
 		// Setup test server without introspection endpoint
 		var testServer *httptest.Server
@@ -740,3 +915,303 @@ func TestGetOIDCProvider(t *testing.T) {
 		assert.Contains(t, err.Error(), "getting odic provider")
 	})
 }
+
+func TestGetSession_TokenRefresh(t *testing.T) {
+	tests := []struct {
+		name            string
+		expiry          time.Duration
+		failRefresh     bool
+		rejectRefresh   bool
+		wantValid       bool
+		wantAccessToken string
+	}{
+		{
+			name:            "Valid access token",
+			expiry:          time.Hour,
+			wantValid:       true,
+			wantAccessToken: "old-access-token",
+		},
+		{
+			name:            "Expired access token is refreshed",
+			expiry:          -time.Minute,
+			wantValid:       true,
+			wantAccessToken: "new-access-token",
+		},
+		{
+			name:            "Nearly expired access token is refreshed",
+			expiry:          30 * time.Second,
+			wantValid:       true,
+			wantAccessToken: "new-access-token",
+		},
+		{
+			name:            "Refresh fails",
+			expiry:          -time.Minute,
+			failRefresh:     true,
+			wantAccessToken: "old-access-token",
+		},
+		{
+			name:          "Refresh token is rejected",
+			expiry:        30 * time.Second,
+			rejectRefresh: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			// The provider considers the old access token active until it expires
+			var testServer *httptest.Server
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/.well-known/openid-configuration":
+					_ = json.NewEncoder(w).Encode(oidc.Configuration{
+						Issuer:                testServer.URL,
+						TokenEndpoint:         testServer.URL + "/token",
+						IntrospectionEndpoint: testServer.URL + "/introspect",
+					})
+				case "/token":
+					if tt.failRefresh {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					if tt.rejectRefresh {
+						w.WriteHeader(http.StatusBadRequest)
+						_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+						return
+					}
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"access_token":  "new-access-token",
+						"refresh_token": "new-refresh-token",
+						"expires_in":    3600,
+					})
+				case "/introspect":
+					_ = r.ParseForm()
+					_ = json.NewEncoder(w).Encode(oidc.Introspection{
+						Active: r.Form.Get("token") == "new-access-token" || tt.expiry > 0,
+					})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			sess := internalsession.Session{
+				ID:                "session-123",
+				TenantID:          "tenant-123",
+				Issuer:            testServer.URL,
+				AccessToken:       "old-access-token",
+				RefreshToken:      "old-refresh-token",
+				AccessTokenExpiry: time.Now().Add(tt.expiry),
+				Expiry:            time.Now().Add(time.Hour),
+			}
+
+			trustData := trustv1.Trust_builder{
+				TenantId: new(sess.TenantID),
+				Blocked:  new(false),
+				Oidc: oidcv1.OIDC_builder{
+					Issuer:   new(testServer.URL),
+					ClientId: new("test-client-id"),
+				}.Build(),
+			}.Build()
+
+			sessionRepo := sessionmock.NewInMemRepository(sessionmock.WithSession(sess))
+			_ = sessionRepo.BumpActive(ctx, sess.ID, time.Hour)
+
+			trust := newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustData)))
+			manager, err := internalsession.NewManager(ctx,
+				&config.SessionManager{CSRFSecretParsed: []byte("0123456789abcdef0123456789abcdef")},
+				trust,
+				sessionRepo,
+				nil,
+				internalsession.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			server := session.NewServer(ctx, sessionRepo, trust, 90*time.Minute,
+				session.WithAllowHttpScheme(true),
+				session.WithTokenRefresh(manager, time.Minute),
+			)
+
+			resp, err := server.GetSession(ctx, &sessionv1.GetSessionRequest{
+				SessionId: sess.ID,
+				TenantId:  sess.TenantID,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantValid, resp.GetValid())
+
+			stored, err := sessionRepo.LoadSession(ctx, sess.ID)
+			if tt.rejectRefresh {
+				require.ErrorIs(t, err, serviceerr.ErrNotFound, "session must end with its refresh token")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAccessToken, stored.AccessToken)
+		})
+	}
+}
+
+// headerStream captures the header metadata sent by a gRPC handler.
+type headerStream struct {
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string { return sessionv1.Service_GetSession_FullMethodName }
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+func (s *headerStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+func (s *headerStream) SetTrailer(metadata.MD) error    { return nil }
+
+// withCaller returns a context of a call from a client presenting a verified
+// certificate with the given common name and URI SAN.
+func withCaller(t *testing.T, ctx context.Context, commonName, uri string) context.Context {
+	t.Helper()
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	if uri != "" {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		cert.URIs = []*url.URL{u}
+	}
+
+	return peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func TestGetSession_AccessTokenRelay(t *testing.T) {
+	const extAuthzID = "spiffe://cluster.local/ns/istio-system/sa/ext-authz"
+
+	tests := []struct {
+		name            string
+		allowedCallers  []string
+		commonName      string
+		uri             string
+		expiry          time.Duration
+		wantAccessToken string
+	}{
+		{
+			name:       "Relay is disabled",
+			commonName: "ext-authz",
+			uri:        extAuthzID,
+			expiry:     time.Hour,
+		},
+		{
+			name:           "Caller without client certificate",
+			allowedCallers: []string{extAuthzID},
+			expiry:         time.Hour,
+		},
+		{
+			name:           "Caller is not allowed",
+			allowedCallers: []string{extAuthzID},
+			commonName:     "other-service",
+			uri:            "spiffe://cluster.local/ns/default/sa/other-service",
+			expiry:         time.Hour,
+		},
+		{
+			name:            "Caller is allowed by URI SAN",
+			allowedCallers:  []string{extAuthzID},
+			commonName:      "ext-authz",
+			uri:             extAuthzID,
+			expiry:          time.Hour,
+			wantAccessToken: "old-access-token",
+		},
+		{
+			name:            "Caller is allowed by common name",
+			allowedCallers:  []string{"ext-authz"},
+			commonName:      "ext-authz",
+			expiry:          time.Hour,
+			wantAccessToken: "old-access-token",
+		},
+		{
+			name:            "Refreshed access token is relayed",
+			allowedCallers:  []string{extAuthzID},
+			commonName:      "ext-authz",
+			uri:             extAuthzID,
+			expiry:          30 * time.Second,
+			wantAccessToken: "new-access-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testServer *httptest.Server
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/.well-known/openid-configuration":
+					_ = json.NewEncoder(w).Encode(oidc.Configuration{
+						Issuer:                testServer.URL,
+						TokenEndpoint:         testServer.URL + "/token",
+						IntrospectionEndpoint: testServer.URL + "/introspect",
+					})
+				case "/token":
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"access_token":  "new-access-token",
+						"refresh_token": "new-refresh-token",
+						"expires_in":    3600,
+					})
+				case "/introspect":
+					_ = json.NewEncoder(w).Encode(oidc.Introspection{Active: true})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			sess := internalsession.Session{
+				ID:                "session-123",
+				TenantID:          "tenant-123",
+				Issuer:            testServer.URL,
+				AccessToken:       "old-access-token",
+				RefreshToken:      "old-refresh-token",
+				AccessTokenExpiry: time.Now().Add(tt.expiry),
+				Expiry:            time.Now().Add(time.Hour),
+			}
+
+			sessionRepo := sessionmock.NewInMemRepository(sessionmock.WithSession(sess))
+			_ = sessionRepo.BumpActive(t.Context(), sess.ID, time.Hour)
+
+			trust := newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustv1.Trust_builder{
+				TenantId: new(sess.TenantID),
+				Blocked:  new(false),
+				Oidc: oidcv1.OIDC_builder{
+					Issuer:   new(testServer.URL),
+					ClientId: new("test-client-id"),
+				}.Build(),
+			}.Build())))
+			manager, err := internalsession.NewManager(t.Context(),
+				&config.SessionManager{CSRFSecretParsed: []byte("0123456789abcdef0123456789abcdef")},
+				trust,
+				sessionRepo,
+				nil,
+				internalsession.WithAllowHttpScheme(true),
+			)
+			require.NoError(t, err)
+
+			server := session.NewServer(t.Context(), sessionRepo, trust, 90*time.Minute,
+				session.WithAllowHttpScheme(true),
+				session.WithTokenRefresh(manager, time.Minute),
+				session.WithAccessTokenRelay(tt.allowedCallers),
+			)
+
+			stream := &headerStream{}
+			ctx := grpc.NewContextWithServerTransportStream(t.Context(), stream)
+			if tt.commonName != "" || tt.uri != "" {
+				ctx = withCaller(t, ctx, tt.commonName, tt.uri)
+			}
+
+			resp, err := server.GetSession(ctx, &sessionv1.GetSessionRequest{
+				SessionId: sess.ID,
+				TenantId:  sess.TenantID,
+			})
+			require.NoError(t, err)
+			assert.True(t, resp.GetValid())
+
+			if tt.wantAccessToken == "" {
+				assert.Empty(t, stream.header.Get(session.MetadataKeyAccessToken))
+				return
+			}
+			assert.Equal(t, []string{tt.wantAccessToken}, stream.header.Get(session.MetadataKeyAccessToken))
+		})
+	}
+}
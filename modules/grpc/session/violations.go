@@ -1,5 +1,32 @@
 package session
 
+import (
+	"context"
+
+	"google.golang.org/grpc/status"
+
+	rpcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/rpc/v1"
+	slogctx "github.com/veqryn/slog-context"
+	grpccodes "google.golang.org/grpc/codes"
+)
+
 const (
-	violationTenantBlocked = "tenant_blocked"
+	violationTenantBlocked  = "tenant_blocked"
+	violationSessionInvalid = "session_invalid"
+	violationCSRFMismatch   = "csrf_token_mismatch"
 )
+
+// preconditionFailure returns a FailedPrecondition error with the violation
+// as rpcv1.PreconditionFailure detail.
+func preconditionFailure(ctx context.Context, msg string, violation *rpcv1.PreconditionFailure_Violation) error {
+	st := status.New(grpccodes.FailedPrecondition, msg)
+	dt, err := st.WithDetails(&rpcv1.PreconditionFailure{
+		Violations: []*rpcv1.PreconditionFailure_Violation{violation},
+	})
+	if err != nil {
+		slogctx.Error(ctx, "Failed to add error details", "error", err)
+		return st.Err()
+	}
+
+	return dt.Err()
+}
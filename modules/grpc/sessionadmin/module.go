@@ -0,0 +1,79 @@
+// Package sessionadmin provides the service.module.grpc.sessionadmin module: a
+// gRPC service module that registers the
+// kms.api.cmk.sessionmanager.sessionadmin.v1.Service proto onto a
+// grpc.ServiceRegistrar supplied by app.module.grpcserver.
+package sessionadmin
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/config"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	// registers the session.Repository dep interface
+	_ "github.com/openkcm/session-manager/modules/grpc/session"
+	sessionadminv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/sessionadmin/v1"
+)
+
+const moduleID = "service.module.grpc.sessionadmin"
+
+func init() {
+	sessionmanager.RegisterModule(new(Module))
+}
+
+func newModule() sessionmanager.Module {
+	return new(Module)
+}
+
+// Module is the service.module.grpc.sessionadmin module. It wires the session
+// store by ID via ctx.GetModule and owns a *Server that implements the proto.
+type Module struct {
+	Mod          string `yaml:"module"`
+	SessionStore string `yaml:"sessionStore" default:"sessionstore.module.valkey" dep:"session.Repository"`
+	// AllowedCallers are the identities of the callers which may administer
+	// sessions: the common name or a DNS or URI SAN of their client certificate.
+	AllowedCallers []string `yaml:"allowedCallers"`
+
+	server *Server
+}
+
+func (m *Module) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  moduleID,
+		New: newModule,
+	}
+}
+
+func (m *Module) Provision(ctx *sessionmanager.Context) error {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		return errors.New("config not found in context")
+	}
+
+	if len(m.AllowedCallers) == 0 {
+		return errors.New("at least one allowed caller is required")
+	}
+
+	repo, err := sessionmanager.GetModuleAs[internalsession.Repository](ctx, m.SessionStore)
+	if err != nil {
+		return fmt.Errorf("getting session-store module %q: %w", m.SessionStore, err)
+	}
+
+	auditLogger, err := otlpaudit.NewLogger(&cfg.Audit)
+	if err != nil {
+		return fmt.Errorf("creating audit logger: %w", err)
+	}
+
+	m.server = NewServer(repo, auditLogger, WithAllowedCallers(m.AllowedCallers))
+
+	return nil
+}
+
+func (m *Module) Register(s grpc.ServiceRegistrar) {
+	sessionadminv1.RegisterServiceServer(s, m.server)
+}
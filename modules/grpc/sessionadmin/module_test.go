@@ -0,0 +1,17 @@
+package sessionadmin_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	_ "github.com/openkcm/session-manager/modules/standard"
+)
+
+func TestModule_Registration(t *testing.T) {
+	info, err := sessionmanager.GetModule("service.module.grpc.sessionadmin")
+	require.NoError(t, err)
+	assert.Equal(t, "service.module.grpc.sessionadmin", info.ID)
+}
@@ -0,0 +1,11 @@
+package sessionadmin
+
+type Option func(*Server)
+
+// WithAllowedCallers sets the identities of the callers which may call the
+// service: the common name or a DNS or URI SAN of their client certificate.
+func WithAllowedCallers(allowedCallers []string) Option {
+	return func(s *Server) {
+		s.allowedCallers = allowedCallers
+	}
+}
@@ -0,0 +1,398 @@
+package sessionadmin
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc/status"
+
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+	slogctx "github.com/veqryn/slog-context"
+	grpccodes "google.golang.org/grpc/codes"
+
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/modules/grpc/session"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+	sessionadminv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/sessionadmin/v1"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	// auditChannelType is the channel type of the audit events of read operations.
+	auditChannelType = "grpc"
+	// auditTenantAll is the tenant of audit events of operations across tenants.
+	auditTenantAll = "*"
+	// redactedTokenSuffixLength is the number of characters of a token which
+	// are shown in the session details.
+	redactedTokenSuffixLength = 4
+)
+
+type Server struct {
+	sessionadminv1.UnimplementedServiceServer
+
+	sessionRepo    internalsession.Repository
+	audit          *otlpaudit.AuditLogger
+	allowedCallers []string
+}
+
+func NewServer(sessionRepo internalsession.Repository, auditLogger *otlpaudit.AuditLogger, opts ...Option) *Server {
+	s := &Server{
+		sessionRepo: sessionRepo,
+		audit:       auditLogger,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	return s
+}
+
+func (s *Server) ListSessions(ctx context.Context, req *sessionadminv1.ListSessionsRequest) (*sessionadminv1.ListSessionsResponse, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "admin_list_sessions")
+	defer span.End()
+
+	ctx, initiator, err := s.authorize(ctx, req.GetOperator())
+	if err != nil {
+		span.SetStatus(codes.Ok, "request not authorized")
+		return nil, err
+	}
+
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		span.SetStatus(codes.Ok, "invalid page size")
+		return nil, status.Error(grpccodes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(req.GetPageToken())
+	if err != nil {
+		span.SetStatus(codes.Ok, "invalid page token")
+		return nil, status.Error(grpccodes.InvalidArgument, "invalid page_token")
+	}
+
+	// Use the indices of the repository for the selective filters
+	var sessions []internalsession.Session
+	switch {
+	case req.GetIssuer() != "" && req.GetSubject() != "":
+		sessions, err = s.sessionRepo.ListSessionsBySubject(ctx, req.GetIssuer(), req.GetSubject())
+	case req.GetTenantId() != "":
+		sessions, err = s.sessionRepo.ListSessionsByTenant(ctx, req.GetTenantId())
+	default:
+		sessions, err = s.sessionRepo.ListSessions(ctx)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list sessions")
+		slogctx.Error(ctx, "Could not list sessions", "error", err)
+		return nil, status.Error(grpccodes.Internal, "failed to list sessions")
+	}
+
+	sessions = slices.DeleteFunc(sessions, func(sess internalsession.Session) bool {
+		return !matches(sess, req) || sess.ID <= string(after)
+	})
+	slices.SortFunc(sessions, func(a, b internalsession.Session) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	resp := &sessionadminv1.ListSessionsResponse{}
+	if len(sessions) > pageSize {
+		sessions = sessions[:pageSize]
+		resp.NextPageToken = new(base64.RawURLEncoding.EncodeToString([]byte(sessions[pageSize-1].ID)))
+	}
+	for _, sess := range sessions {
+		resp.Sessions = append(resp.Sessions, toProto(sess))
+	}
+
+	s.sendReadAudit(ctx, initiator, cmp.Or(req.GetTenantId(), auditTenantAll), "sessions", "ListSessions", url.Values{
+		"tenant_id": {req.GetTenantId()},
+		"subject":   {req.GetSubject()},
+		"email":     {req.GetEmail()},
+		"issuer":    {req.GetIssuer()},
+	}.Encode())
+
+	span.SetStatus(codes.Ok, "")
+	return resp, nil
+}
+
+func (s *Server) GetSessionDetails(ctx context.Context, req *sessionadminv1.GetSessionDetailsRequest) (*sessionadminv1.GetSessionDetailsResponse, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "admin_get_session_details")
+	defer span.End()
+
+	ctx, initiator, err := s.authorize(ctx, req.GetOperator())
+	if err != nil {
+		span.SetStatus(codes.Ok, "request not authorized")
+		return nil, err
+	}
+
+	if req.GetSessionId() == "" {
+		span.SetStatus(codes.Ok, "missing session id")
+		return nil, status.Error(grpccodes.InvalidArgument, "session_id is required")
+	}
+
+	sess, err := s.loadSession(ctx, req.GetSessionId())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Ok, "failed to load the session")
+		return nil, err
+	}
+
+	active, err := s.sessionRepo.IsActive(ctx, sess.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to check session state")
+		slogctx.Error(ctx, "Could not get the session active state", "error", err)
+		return nil, status.Error(grpccodes.Internal, "failed to check the session state")
+	}
+
+	resp := &sessionadminv1.GetSessionDetailsResponse{
+		Session:           toProto(sess),
+		AccessToken:       new(redact(sess.AccessToken)),
+		RefreshToken:      new(redact(sess.RefreshToken)),
+		CsrfToken:         new(redact(sess.CSRFToken)),
+		Active:            new(active),
+		ProviderSessionId: new(sess.ProviderID),
+		Acr:               new(sess.ACR),
+		Amr:               sess.AMR,
+		AuthTime:          new(unix(sess.AuthTime)),
+	}
+
+	s.sendReadAudit(ctx, initiator, sess.TenantID, sess.ID, "GetSessionDetails", sess.ID)
+
+	span.SetStatus(codes.Ok, "")
+	return resp, nil
+}
+
+func (s *Server) RevokeSession(ctx context.Context, req *sessionadminv1.RevokeSessionRequest) (*sessionadminv1.RevokeSessionResponse, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "admin_revoke_session")
+	defer span.End()
+
+	ctx, initiator, err := s.authorize(ctx, req.GetOperator())
+	if err != nil {
+		span.SetStatus(codes.Ok, "request not authorized")
+		return nil, err
+	}
+
+	if req.GetSessionId() == "" {
+		span.SetStatus(codes.Ok, "missing session id")
+		return nil, status.Error(grpccodes.InvalidArgument, "session_id is required")
+	}
+
+	sess, err := s.loadSession(ctx, req.GetSessionId())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Ok, "failed to load the session")
+		return nil, err
+	}
+
+	if err := s.revoke(ctx, initiator, sess); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to revoke the session")
+		return nil, status.Error(grpccodes.Internal, "failed to revoke the session")
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return &sessionadminv1.RevokeSessionResponse{}, nil
+}
+
+func (s *Server) RevokeAllSessionsForUser(ctx context.Context, req *sessionadminv1.RevokeAllSessionsForUserRequest) (*sessionadminv1.RevokeAllSessionsForUserResponse, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "admin_revoke_all_sessions_for_user")
+	defer span.End()
+
+	ctx, initiator, err := s.authorize(ctx, req.GetOperator())
+	if err != nil {
+		span.SetStatus(codes.Ok, "request not authorized")
+		return nil, err
+	}
+
+	if req.GetIssuer() == "" || req.GetSubject() == "" {
+		span.SetStatus(codes.Ok, "missing issuer or subject")
+		return nil, status.Error(grpccodes.InvalidArgument, "issuer and subject are required")
+	}
+
+	sessions, err := s.sessionRepo.ListSessionsBySubject(ctx, req.GetIssuer(), req.GetSubject())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list sessions")
+		slogctx.Error(ctx, "Could not list the sessions of the user", "error", err)
+		return nil, status.Error(grpccodes.Internal, "failed to list the sessions of the user")
+	}
+
+	var revoked int32
+	for _, sess := range sessions {
+		if req.GetTenantId() != "" && sess.TenantID != req.GetTenantId() {
+			continue
+		}
+		if err := s.revoke(ctx, initiator, sess); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to revoke a session")
+			return nil, status.Errorf(grpccodes.Internal, "failed to revoke the sessions of the user, %d revoked", revoked)
+		}
+		revoked++
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return &sessionadminv1.RevokeAllSessionsForUserResponse{RevokedSessions: new(revoked)}, nil
+}
+
+// authorize returns the initiator of the request if the caller is allowed to
+// call the service. The caller is authenticated with its client certificate and
+// acts on behalf of the operator of the request; the initiator names both, e.g.
+// "spiffe://cluster.local/ns/support/sa/session-admin/jane.doe".
+func (s *Server) authorize(ctx context.Context, operator string) (context.Context, string, error) {
+	identities := session.CallerIdentities(ctx)
+	if len(identities) == 0 {
+		slogctx.Warn(ctx, "Caller without client certificate", "operator", operator)
+		return ctx, "", status.Error(grpccodes.Unauthenticated, "client certificate is required")
+	}
+
+	i := slices.IndexFunc(identities, func(id string) bool {
+		return slices.Contains(s.allowedCallers, id)
+	})
+	if i < 0 {
+		slogctx.Warn(ctx, "Caller is not allowed to administer sessions", "identities", identities, "operator", operator)
+		return ctx, "", status.Error(grpccodes.PermissionDenied, "caller is not allowed")
+	}
+
+	caller := identities[i]
+	if operator == "" {
+		slogctx.Warn(ctx, "Caller without operator", "caller", caller)
+		return ctx, "", status.Error(grpccodes.InvalidArgument, "operator is required")
+	}
+	ctx = slogctx.With(ctx, "caller", caller, "operator", operator)
+
+	return ctx, caller + "/" + operator, nil
+}
+
+// loadSession loads the session and maps the errors to gRPC status errors.
+func (s *Server) loadSession(ctx context.Context, sessionID string) (internalsession.Session, error) {
+	sess, err := s.sessionRepo.LoadSession(ctx, sessionID)
+	if errors.Is(err, serviceerr.ErrNotFound) {
+		return internalsession.Session{}, status.Error(grpccodes.NotFound, "session not found")
+	}
+	if err != nil {
+		slogctx.Error(ctx, "Could not load the session", "error", err)
+		return internalsession.Session{}, status.Error(grpccodes.Internal, "failed to load the session")
+	}
+
+	return sess, nil
+}
+
+// revoke deletes the session and records the revocation by the initiator.
+func (s *Server) revoke(ctx context.Context, initiator string, sess internalsession.Session) error {
+	if err := s.sessionRepo.DeleteSession(ctx, sess); err != nil {
+		slogctx.Error(ctx, "Could not delete the session", "sessionId", sess.ID, "error", err)
+		return err
+	}
+	slogctx.Info(ctx, "Revoked session", "sessionId", sess.ID, "tenantId", sess.TenantID)
+
+	if s.audit == nil {
+		slogctx.Warn(ctx, "audit logger is nil; skipping session revocation event")
+		return nil
+	}
+
+	metadata, err := otlpaudit.NewEventMetadata(initiator, sess.TenantID, uuid.Must(uuid.NewV4()).String())
+	if err != nil {
+		slogctx.Error(ctx, "creating audit metadata", "error", err)
+		return nil
+	}
+
+	event, err := otlpaudit.NewCredentialRevokationEvent(metadata, sess.ID, otlpaudit.CREDTYPE_SECRET)
+	if err != nil {
+		slogctx.Error(ctx, "creating audit log", "error", err)
+		return nil
+	}
+
+	if err := s.audit.SendEvent(ctx, event); err != nil {
+		slogctx.Error(ctx, "Failed to send audit log for session revocation", "error", err)
+	}
+
+	return nil
+}
+
+// sendReadAudit records that the initiator has read the object. The value must
+// be comparable. Like the other audit helpers, it only logs errors.
+func (s *Server) sendReadAudit(ctx context.Context, initiator, tenantID, objectID, rpc, value string) {
+	if s.audit == nil {
+		slogctx.Warn(ctx, "audit logger is nil; skipping session read event")
+		return
+	}
+
+	metadata, err := otlpaudit.NewEventMetadata(initiator, tenantID, uuid.Must(uuid.NewV4()).String())
+	if err != nil {
+		slogctx.Error(ctx, "creating audit metadata", "error", err)
+		return
+	}
+
+	event, err := otlpaudit.NewConfigurationReadEvent(metadata, objectID, auditChannelType, rpc, value)
+	if err != nil {
+		slogctx.Error(ctx, "creating audit log", "error", err)
+		return
+	}
+
+	if err := s.audit.SendEvent(ctx, event); err != nil {
+		slogctx.Error(ctx, "Failed to send audit log for session read", "error", err)
+	}
+}
+
+// matches reports whether the session matches all filters of the request.
+func matches(sess internalsession.Session, req *sessionadminv1.ListSessionsRequest) bool {
+	return (req.GetTenantId() == "" || sess.TenantID == req.GetTenantId()) &&
+		(req.GetSubject() == "" || sess.ProviderSubject == req.GetSubject()) &&
+		(req.GetEmail() == "" || strings.EqualFold(sess.Claims.Email, req.GetEmail())) &&
+		(req.GetIssuer() == "" || sess.Issuer == req.GetIssuer())
+}
+
+func toProto(sess internalsession.Session) *sessionadminv1.Session {
+	return &sessionadminv1.Session{
+		SessionId:             new(sess.ID),
+		TenantId:              new(sess.TenantID),
+		Issuer:                new(sess.Issuer),
+		Subject:               new(sess.ProviderSubject),
+		Email:                 new(sess.Claims.Email),
+		GivenName:             new(sess.Claims.GivenName),
+		FamilyName:            new(sess.Claims.FamilyName),
+		Groups:                sess.Claims.Groups,
+		ExpiresAt:             new(unix(sess.Expiry)),
+		AccessTokenExpiresAt:  new(unix(sess.AccessTokenExpiry)),
+		RefreshTokenExpiresAt: new(unix(sess.RefreshTokenExpiry)),
+	}
+}
+
+// redact replaces all but the last characters of the token so that operators
+// can tell tokens apart without being able to use them.
+func redact(token string) string {
+	if len(token) <= 2*redactedTokenSuffixLength {
+		return strings.Repeat("*", len(token))
+	}
+
+	return strings.Repeat("*", len(token)-redactedTokenSuffixLength) + token[len(token)-redactedTokenSuffixLength:]
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
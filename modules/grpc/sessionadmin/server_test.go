@@ -0,0 +1,403 @@
+package sessionadmin_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	"github.com/openkcm/session-manager/modules/grpc/sessionadmin"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+	sessionadminv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/sessionadmin/v1"
+)
+
+const (
+	caller   = "spiffe://cluster.local/ns/support/sa/session-admin"
+	operator = "support-operator"
+	issuer   = "https://issuer.example.com"
+)
+
+var testSessions = []internalsession.Session{
+	newSession("session-1", "tenant-a", "alice", "alice@example.com"),
+	newSession("session-2", "tenant-a", "bob", "bob@example.com"),
+	newSession("session-3", "tenant-b", "alice", "alice@example.com"),
+	newSession("session-4", "tenant-b", "carol", "carol@example.com"),
+	newSession("session-5", "tenant-a", "alice", "alice@example.com"),
+}
+
+func newSession(id, tenantID, subject, email string) internalsession.Session {
+	return internalsession.Session{
+		ID:              id,
+		TenantID:        tenantID,
+		Issuer:          issuer,
+		ProviderSubject: subject,
+		ProviderID:      "sid-" + id,
+		CSRFToken:       "csrf-token-" + id,
+		AccessToken:     "access-token-" + id,
+		RefreshToken:    "refresh-token-" + id,
+		Claims: internalsession.Claims{
+			Subject: subject,
+			Email:   email,
+			Groups:  []string{"users"},
+		},
+		Expiry:            time.Now().Add(time.Hour),
+		AccessTokenExpiry: time.Now().Add(5 * time.Minute),
+	}
+}
+
+// auditLog records the requests sent to the audit server.
+type auditLog struct {
+	mu       sync.Mutex
+	requests [][]byte
+}
+
+// Load returns the number of sent audit events.
+func (l *auditLog) Load() int32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int32(len(l.requests))
+}
+
+// Contains reports whether all sent audit events contain the value.
+func (l *auditLog) Contains(value string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, req := range l.requests {
+		if !bytes.Contains(req, []byte(value)) {
+			return false
+		}
+	}
+	return len(l.requests) > 0
+}
+
+// newServer returns a server for the test sessions and a log of the sent
+// audit events.
+func newServer(t *testing.T) (*sessionadmin.Server, *sessionmock.Repository, *auditLog) {
+	t.Helper()
+
+	auditEvents := &auditLog{}
+	auditServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		auditEvents.mu.Lock()
+		auditEvents.requests = append(auditEvents.requests, body)
+		auditEvents.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(auditServer.Close)
+
+	auditLogger, err := otlpaudit.NewLogger(&commoncfg.Audit{Endpoint: auditServer.URL})
+	require.NoError(t, err)
+
+	opts := make([]sessionmock.RepositoryOption, 0, len(testSessions))
+	for _, sess := range testSessions {
+		opts = append(opts, sessionmock.WithSession(sess))
+	}
+	repo := sessionmock.NewInMemRepository(opts...)
+
+	return sessionadmin.NewServer(repo, auditLogger, sessionadmin.WithAllowedCallers([]string{caller})), repo, auditEvents
+}
+
+// callerContext returns a context of a caller authenticated with a client
+// certificate with the URI SAN.
+func callerContext(t *testing.T, uri string) context.Context {
+	t.Helper()
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	cert := &x509.Certificate{URIs: []*url.URL{u}}
+
+	return peer.NewContext(t.Context(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func sessionIDs(sessions []*sessionadminv1.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.GetSessionId())
+	}
+	return ids
+}
+
+func TestServer_ListSessions(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *sessionadminv1.ListSessionsRequest
+		wantCode codes.Code
+		wantIDs  []string
+	}{
+		{
+			name:    "All sessions",
+			req:     &sessionadminv1.ListSessionsRequest{Operator: new(operator)},
+			wantIDs: []string{"session-1", "session-2", "session-3", "session-4", "session-5"},
+		},
+		{
+			name:    "Filter by tenant",
+			req:     &sessionadminv1.ListSessionsRequest{Operator: new(operator), TenantId: new("tenant-b")},
+			wantIDs: []string{"session-3", "session-4"},
+		},
+		{
+			name:    "Filter by issuer and subject",
+			req:     &sessionadminv1.ListSessionsRequest{Operator: new(operator), Issuer: new(issuer), Subject: new("alice")},
+			wantIDs: []string{"session-1", "session-3", "session-5"},
+		},
+		{
+			name:    "Filter by email and tenant",
+			req:     &sessionadminv1.ListSessionsRequest{Operator: new(operator), Email: new("Alice@Example.com"), TenantId: new("tenant-a")},
+			wantIDs: []string{"session-1", "session-5"},
+		},
+		{
+			name:    "Unknown issuer",
+			req:     &sessionadminv1.ListSessionsRequest{Operator: new(operator), Issuer: new("https://other.example.com")},
+			wantIDs: []string{},
+		},
+		{
+			name:     "Without operator",
+			req:      &sessionadminv1.ListSessionsRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Invalid page token",
+			req:      &sessionadminv1.ListSessionsRequest{Operator: new(operator), PageToken: new("not base64!")},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _, auditEvents := newServer(t)
+
+			resp, err := server.ListSessions(callerContext(t, caller), tt.req)
+			require.Equal(t, tt.wantCode, status.Code(err), "error: %v", err)
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, int32(0), auditEvents.Load())
+				return
+			}
+
+			assert.Equal(t, tt.wantIDs, sessionIDs(resp.GetSessions()))
+			assert.Empty(t, resp.GetNextPageToken())
+			assert.Equal(t, int32(1), auditEvents.Load())
+			assert.True(t, auditEvents.Contains(caller+"/"+operator), "audit events must name the caller and the operator")
+		})
+	}
+
+	t.Run("Paginates", func(t *testing.T) {
+		server, _, _ := newServer(t)
+
+		var ids []string
+		var pages int
+		req := &sessionadminv1.ListSessionsRequest{Operator: new(operator), PageSize: new(int32(2))}
+		for {
+			resp, err := server.ListSessions(callerContext(t, caller), req)
+			require.NoError(t, err)
+			pages++
+			assert.LessOrEqual(t, len(resp.GetSessions()), 2)
+			ids = append(ids, sessionIDs(resp.GetSessions())...)
+			if resp.GetNextPageToken() == "" {
+				break
+			}
+			req.PageToken = new(resp.GetNextPageToken())
+		}
+
+		assert.Equal(t, 3, pages)
+		assert.Equal(t, []string{"session-1", "session-2", "session-3", "session-4", "session-5"}, ids)
+	})
+}
+
+func TestServer_GetSessionDetails(t *testing.T) {
+	t.Run("Returns the session with redacted tokens", func(t *testing.T) {
+		server, repo, auditEvents := newServer(t)
+		require.NoError(t, repo.BumpActive(t.Context(), "session-1", time.Hour))
+
+		resp, err := server.GetSessionDetails(callerContext(t, caller), &sessionadminv1.GetSessionDetailsRequest{
+			Operator:  new(operator),
+			SessionId: new("session-1"),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "session-1", resp.GetSession().GetSessionId())
+		assert.Equal(t, "tenant-a", resp.GetSession().GetTenantId())
+		assert.Equal(t, "alice", resp.GetSession().GetSubject())
+		assert.Equal(t, "alice@example.com", resp.GetSession().GetEmail())
+		assert.Equal(t, []string{"users"}, resp.GetSession().GetGroups())
+		assert.Equal(t, testSessions[0].Expiry.Unix(), resp.GetSession().GetExpiresAt())
+		assert.Zero(t, resp.GetSession().GetRefreshTokenExpiresAt())
+		assert.Equal(t, "sid-session-1", resp.GetProviderSessionId())
+		assert.True(t, resp.GetActive())
+
+		assert.Equal(t, "******************on-1", resp.GetAccessToken())
+		assert.NotContains(t, resp.GetRefreshToken(), "refresh-token")
+		assert.NotContains(t, resp.GetCsrfToken(), "csrf-token")
+		assert.Equal(t, int32(1), auditEvents.Load())
+	})
+
+	t.Run("Unknown session", func(t *testing.T) {
+		server, _, auditEvents := newServer(t)
+
+		_, err := server.GetSessionDetails(callerContext(t, caller), &sessionadminv1.GetSessionDetailsRequest{
+			Operator:  new(operator),
+			SessionId: new("unknown-session"),
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, int32(0), auditEvents.Load())
+	})
+
+	t.Run("Missing session ID", func(t *testing.T) {
+		server, _, _ := newServer(t)
+
+		_, err := server.GetSessionDetails(callerContext(t, caller), &sessionadminv1.GetSessionDetailsRequest{
+			Operator: new(operator),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Caller is not allowed", func(t *testing.T) {
+		server, _, auditEvents := newServer(t)
+
+		_, err := server.GetSessionDetails(callerContext(t, "spiffe://cluster.local/ns/default/sa/other"), &sessionadminv1.GetSessionDetailsRequest{
+			Operator:  new(operator),
+			SessionId: new("session-1"),
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, int32(0), auditEvents.Load())
+	})
+}
+
+func TestServer_RevokeSession(t *testing.T) {
+	t.Run("Revokes the session", func(t *testing.T) {
+		server, repo, auditEvents := newServer(t)
+
+		_, err := server.RevokeSession(callerContext(t, caller), &sessionadminv1.RevokeSessionRequest{
+			Operator:  new(operator),
+			SessionId: new("session-2"),
+		})
+		require.NoError(t, err)
+
+		_, err = repo.LoadSession(t.Context(), "session-2")
+		require.ErrorIs(t, err, serviceerr.ErrNotFound)
+		_, err = repo.LoadSession(t.Context(), "session-1")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), auditEvents.Load())
+		assert.True(t, auditEvents.Contains(caller+"/"+operator), "audit events must name the caller and the operator")
+	})
+
+	t.Run("Without operator", func(t *testing.T) {
+		server, repo, auditEvents := newServer(t)
+
+		_, err := server.RevokeSession(callerContext(t, caller), &sessionadminv1.RevokeSessionRequest{
+			SessionId: new("session-2"),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = repo.LoadSession(t.Context(), "session-2")
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), auditEvents.Load())
+	})
+
+	t.Run("Unknown session", func(t *testing.T) {
+		server, _, auditEvents := newServer(t)
+
+		_, err := server.RevokeSession(callerContext(t, caller), &sessionadminv1.RevokeSessionRequest{
+			Operator:  new(operator),
+			SessionId: new("unknown-session"),
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, int32(0), auditEvents.Load())
+	})
+
+	t.Run("Caller without client certificate", func(t *testing.T) {
+		server, repo, auditEvents := newServer(t)
+
+		_, err := server.RevokeSession(t.Context(), &sessionadminv1.RevokeSessionRequest{
+			Operator:  new(operator),
+			SessionId: new("session-2"),
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = repo.LoadSession(t.Context(), "session-2")
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), auditEvents.Load())
+	})
+}
+
+func TestServer_RevokeAllSessionsForUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *sessionadminv1.RevokeAllSessionsForUserRequest
+		wantCode      codes.Code
+		wantRevoked   int32
+		wantRemaining []string
+	}{
+		{
+			name: "Revokes the sessions of the user",
+			req: &sessionadminv1.RevokeAllSessionsForUserRequest{
+				Operator: new(operator),
+				Issuer:   new(issuer),
+				Subject:  new("alice"),
+			},
+			wantRevoked:   3,
+			wantRemaining: []string{"session-2", "session-4"},
+		},
+		{
+			name: "Restricted to a tenant",
+			req: &sessionadminv1.RevokeAllSessionsForUserRequest{
+				Operator: new(operator),
+				Issuer:   new(issuer),
+				Subject:  new("alice"),
+				TenantId: new("tenant-b"),
+			},
+			wantRevoked:   1,
+			wantRemaining: []string{"session-1", "session-2", "session-4", "session-5"},
+		},
+		{
+			name: "User without sessions",
+			req: &sessionadminv1.RevokeAllSessionsForUserRequest{
+				Operator: new(operator),
+				Issuer:   new(issuer),
+				Subject:  new("dave"),
+			},
+			wantRemaining: []string{"session-1", "session-2", "session-3", "session-4", "session-5"},
+		},
+		{
+			name: "Missing subject",
+			req: &sessionadminv1.RevokeAllSessionsForUserRequest{
+				Operator: new(operator),
+				Issuer:   new(issuer),
+			},
+			wantCode:      codes.InvalidArgument,
+			wantRemaining: []string{"session-1", "session-2", "session-3", "session-4", "session-5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _, auditEvents := newServer(t)
+
+			resp, err := server.RevokeAllSessionsForUser(callerContext(t, caller), tt.req)
+			require.Equal(t, tt.wantCode, status.Code(err), "error: %v", err)
+			assert.Equal(t, tt.wantRevoked, resp.GetRevokedSessions())
+			assert.Equal(t, tt.wantRevoked, auditEvents.Load())
+
+			remaining, err := server.ListSessions(callerContext(t, caller), &sessionadminv1.ListSessionsRequest{Operator: new(operator)})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRemaining, sessionIDs(remaining.GetSessions()))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package tokenexchange provides the service.module.grpc.tokenexchange module:
+// a gRPC service module that registers the
+// kms.api.cmk.sessionmanager.tokenexchange.v1.Service proto onto a
+// grpc.ServiceRegistrar supplied by app.module.grpcserver.
+package tokenexchange
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+
+	otlpaudit "github.com/openkcm/common-sdk/pkg/otlp/audit"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/internal/credentials"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	// registers the session.Repository and credentials.Builder dep interfaces
+	_ "github.com/openkcm/session-manager/modules/grpc/session"
+	tokenexchangev1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/tokenexchange/v1"
+)
+
+const moduleID = "service.module.grpc.tokenexchange"
+
+func init() {
+	sessionmanager.RegisterModule(new(Module))
+}
+
+func newModule() sessionmanager.Module {
+	return new(Module)
+}
+
+// credentialsBuilder is the interface satisfied by a credentials module
+// (e.g. credentials.module.oauth2).
+type credentialsBuilder interface {
+	Builder() credentials.Builder
+}
+
+// Module is the service.module.grpc.tokenexchange module. It wires its three
+// dependencies (trust, session store, credentials) by ID via ctx.GetModule
+// and owns a *Server that implements the proto.
+type Module struct {
+	Mod          string `yaml:"module"`
+	Trust        string `yaml:"trust"        default:"trust.module.oidc"        dep:"sessionmanager.Trust"`
+	SessionStore string `yaml:"sessionStore" default:"sessionstore.module.valkey" dep:"session.Repository"`
+	Credentials  string `yaml:"credentials"  default:"credentials.module.oauth2"  dep:"credentials.Builder"`
+
+	AllowHttpScheme bool `yaml:"allowHttpScheme"`
+	// TokenRefreshWindow defines the duration before token expiry when the
+	// access token of a session is refreshed before it is exchanged.
+	TokenRefreshWindow time.Duration `yaml:"tokenRefreshWindow" default:"1m"`
+	// AllowedCallers are the identities of the callers which may exchange
+	// tokens: the common name or a DNS or URI SAN of their client certificate.
+	AllowedCallers []string `yaml:"allowedCallers"`
+
+	server *Server
+}
+
+func (m *Module) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  moduleID,
+		New: newModule,
+	}
+}
+
+func (m *Module) Provision(ctx *sessionmanager.Context) error {
+	cfg, ok := config.FromContext(ctx)
+	if !ok {
+		return errors.New("config not found in context")
+	}
+
+	if len(m.AllowedCallers) == 0 {
+		return errors.New("at least one allowed caller is required")
+	}
+
+	// The session manager depends on the secrets, which are usually loaded
+	// by the caller of LoadAll already
+	if err := cfg.SessionManager.LoadSecrets(); err != nil {
+		return fmt.Errorf("loading session manager secrets: %w", err)
+	}
+
+	trust, err := sessionmanager.GetModuleAs[sessionmanager.Trust](ctx, m.Trust)
+	if err != nil {
+		return fmt.Errorf("getting trust module %q: %w", m.Trust, err)
+	}
+
+	repo, err := sessionmanager.GetModuleAs[internalsession.Repository](ctx, m.SessionStore)
+	if err != nil {
+		return fmt.Errorf("getting session-store module %q: %w", m.SessionStore, err)
+	}
+
+	creds, err := sessionmanager.GetModuleAs[credentialsBuilder](ctx, m.Credentials)
+	if err != nil {
+		return fmt.Errorf("getting credentials module %q: %w", m.Credentials, err)
+	}
+
+	auditLogger, err := otlpaudit.NewLogger(&cfg.Audit)
+	if err != nil {
+		return fmt.Errorf("creating audit logger: %w", err)
+	}
+
+	manager, err := internalsession.NewManager(ctx,
+		&cfg.SessionManager,
+		trust,
+		repo,
+		auditLogger,
+		internalsession.WithTransportCredentials(creds.Builder()),
+		internalsession.WithAllowHttpScheme(m.AllowHttpScheme),
+	)
+	if err != nil {
+		return fmt.Errorf("creating session manager: %w", err)
+	}
+
+	m.server = NewServer(repo, trust, manager, m.TokenRefreshWindow, WithAllowedCallers(m.AllowedCallers))
+
+	return nil
+}
+
+func (m *Module) Register(s grpc.ServiceRegistrar) {
+	tokenexchangev1.RegisterServiceServer(s, m.server)
+}
@@ -0,0 +1,30 @@
+package tokenexchange_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/config"
+	"github.com/openkcm/session-manager/modules/grpc/tokenexchange"
+	_ "github.com/openkcm/session-manager/modules/standard"
+)
+
+func TestModule_Registration(t *testing.T) {
+	info, err := sessionmanager.GetModule("service.module.grpc.tokenexchange")
+	require.NoError(t, err)
+	assert.Equal(t, "service.module.grpc.tokenexchange", info.ID)
+}
+
+func TestModule_ProvisionWithoutAllowedCallersFails(t *testing.T) {
+	ctx, cancel := sessionmanager.NewContext(t.Context())
+	defer cancel(nil)
+	ctx = config.WithContext(ctx, &config.Config{})
+
+	m := &tokenexchange.Module{}
+	err := m.Provision(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least one allowed caller is required")
+}
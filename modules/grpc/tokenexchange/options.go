@@ -0,0 +1,11 @@
+package tokenexchange
+
+type Option func(*Server)
+
+// WithAllowedCallers sets the identities of the callers which may exchange
+// tokens: the common name or a DNS or URI SAN of their client certificate.
+func WithAllowedCallers(allowedCallers []string) Option {
+	return func(s *Server) {
+		s.allowedCallers = allowedCallers
+	}
+}
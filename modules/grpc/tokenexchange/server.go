@@ -0,0 +1,188 @@
+package tokenexchange
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc/status"
+
+	slogctx "github.com/veqryn/slog-context"
+	grpccodes "google.golang.org/grpc/codes"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	"github.com/openkcm/session-manager/modules/grpc/session"
+	"github.com/openkcm/session-manager/pkg/serviceerr"
+	tokenexchangev1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/tokenexchange/v1"
+)
+
+// TokenExchanger exchanges the access tokens of sessions, e.g. *internalsession.Manager.
+type TokenExchanger interface {
+	RefreshExpiringSession(ctx context.Context, s internalsession.Session, window time.Duration) (internalsession.Session, error)
+	ExchangeToken(ctx context.Context, s internalsession.Session, audience string) (internalsession.ExchangedToken, error)
+}
+
+type Server struct {
+	tokenexchangev1.UnimplementedServiceServer
+
+	sessionRepo internalsession.Repository
+	trust       sessionmanager.Trust
+	exchanger   TokenExchanger
+
+	// refreshes access tokens which expire within the window before exchanging them
+	tokenRefreshWindow time.Duration
+	allowedCallers     []string
+}
+
+func NewServer(
+	sessionRepo internalsession.Repository,
+	trust sessionmanager.Trust,
+	exchanger TokenExchanger,
+	tokenRefreshWindow time.Duration,
+	opts ...Option,
+) *Server {
+	s := &Server{
+		sessionRepo:        sessionRepo,
+		trust:              trust,
+		exchanger:          exchanger,
+		tokenRefreshWindow: tokenRefreshWindow,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	return s
+}
+
+func (s *Server) ExchangeToken(ctx context.Context, req *tokenexchangev1.ExchangeTokenRequest) (*tokenexchangev1.ExchangeTokenResponse, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "exchange_token")
+	defer span.End()
+
+	ctx = slogctx.With(ctx, "tenantId", req.GetTenantId(), "audience", req.GetAudience())
+
+	slogctx.Debug(ctx, "ExchangeToken called")
+	defer slogctx.Debug(ctx, "ExchangeToken completed")
+
+	ctx, err := s.authorize(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, "caller is not allowed")
+		return nil, err
+	}
+
+	if req.GetSessionId() == "" || req.GetTenantId() == "" || req.GetAudience() == "" {
+		span.SetStatus(codes.Error, "invalid request")
+		return nil, status.Error(grpccodes.InvalidArgument, "session_id, tenant_id and audience are required")
+	}
+
+	sess, err := s.loadSession(ctx, req.GetSessionId(), req.GetTenantId())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to load a session")
+		return nil, err
+	}
+
+	// The subject token must be valid for the exchange
+	sess, err = s.exchanger.RefreshExpiringSession(ctx, sess, s.tokenRefreshWindow)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to refresh the access token")
+		slogctx.Error(ctx, "Could not refresh the access token", "error", err)
+		if errors.Is(err, serviceerr.ErrInvalidGrant) {
+			return nil, status.Error(grpccodes.Unauthenticated, "the session is not valid")
+		}
+
+		return nil, status.Error(grpccodes.Internal, "failed to refresh the access token")
+	}
+
+	token, err := s.exchanger.ExchangeToken(ctx, sess, req.GetAudience())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to exchange the access token")
+		slogctx.Error(ctx, "Could not exchange the access token", "error", err)
+
+		switch {
+		case errors.Is(err, serviceerr.ErrInvalidTarget):
+			return nil, status.Error(grpccodes.PermissionDenied, "the audience has been rejected")
+		case errors.Is(err, serviceerr.ErrInvalidGrant):
+			return nil, status.Error(grpccodes.PermissionDenied, "the access token has been rejected")
+		default:
+			return nil, status.Error(grpccodes.Internal, "failed to exchange the access token")
+		}
+	}
+
+	response := &tokenexchangev1.ExchangeTokenResponse{
+		AccessToken:     new(token.AccessToken),
+		IssuedTokenType: new(token.IssuedTokenType),
+		TokenType:       new(token.TokenType),
+	}
+	if !token.Expiry.IsZero() {
+		response.ExpiresAt = new(token.Expiry.Unix())
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return response, nil
+}
+
+// authorize checks that the caller presents a client certificate with one of
+// the allowed identities, as the exchanged tokens grant access to other services.
+func (s *Server) authorize(ctx context.Context) (context.Context, error) {
+	identities := session.CallerIdentities(ctx)
+	if len(identities) == 0 {
+		slogctx.Warn(ctx, "Caller without client certificate")
+		return ctx, status.Error(grpccodes.Unauthenticated, "client certificate is required")
+	}
+
+	i := slices.IndexFunc(identities, func(id string) bool {
+		return slices.Contains(s.allowedCallers, id)
+	})
+	if i < 0 {
+		slogctx.Warn(ctx, "Caller is not allowed to exchange tokens", "identities", identities)
+		return ctx, status.Error(grpccodes.PermissionDenied, "caller is not allowed")
+	}
+
+	return slogctx.With(ctx, "caller", identities[i]), nil
+}
+
+// loadSession loads the active session of the tenant. The errors do not reveal
+// whether a session exists for another tenant.
+func (s *Server) loadSession(ctx context.Context, sessionID, tenantID string) (internalsession.Session, error) {
+	errInvalidSession := status.Error(grpccodes.Unauthenticated, "the session is not valid")
+
+	active, err := s.sessionRepo.IsActive(ctx, sessionID)
+	if err != nil {
+		slogctx.Error(ctx, "Could not check the session state", "error", err)
+		return internalsession.Session{}, status.Error(grpccodes.Internal, "failed to check the session state")
+	}
+	if !active {
+		return internalsession.Session{}, errInvalidSession
+	}
+
+	sess, err := s.sessionRepo.LoadSession(ctx, sessionID)
+	if err != nil {
+		slogctx.Warn(ctx, "Is this an attack? Could not load session", "error", err)
+		return internalsession.Session{}, errInvalidSession
+	}
+	if sess.TenantID != tenantID {
+		slogctx.Warn(ctx, "Is this an attack? Tenant IDs do not match", "sessionTenantId", sess.TenantID)
+		return internalsession.Session{}, errInvalidSession
+	}
+
+	trust, err := s.trust.Get(ctx, tenantID)
+	if err != nil {
+		slogctx.Warn(ctx, "Could not get trust", "error", err)
+		return internalsession.Session{}, errInvalidSession
+	}
+	if trust.GetBlocked() {
+		slogctx.Warn(ctx, "Tenant is blocked")
+		return internalsession.Session{}, status.Error(grpccodes.FailedPrecondition, "the tenant is blocked")
+	}
+
+	return sess, nil
+}
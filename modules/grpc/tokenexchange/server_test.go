@@ -0,0 +1,258 @@
+package tokenexchange_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/config"
+	internalsession "github.com/openkcm/session-manager/internal/session"
+	sessionmock "github.com/openkcm/session-manager/internal/session/mock"
+	"github.com/openkcm/session-manager/modules/grpc/tokenexchange"
+	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
+	tokenexchangev1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/tokenexchange/v1"
+)
+
+// startProvider starts an OIDC provider which refreshes and exchanges tokens.
+func startProvider(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":         server.URL,
+				"token_endpoint": server.URL + "/token",
+			})
+		case "/token":
+			_ = r.ParseForm()
+			switch {
+			case r.PostForm.Get("grant_type") == "refresh_token":
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"access_token":  "new-access-token",
+					"refresh_token": "new-refresh-token",
+					"expires_in":    3600,
+				})
+			case r.PostForm.Get("audience") == "unknown-service":
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": "invalid_target"})
+			default:
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"access_token":      r.PostForm.Get("audience") + ":" + r.PostForm.Get("subject_token"),
+					"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+					"token_type":        "Bearer",
+					"expires_in":        300,
+				})
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+const caller = "spiffe://cluster.local/ns/orders/sa/orders-service"
+
+// callerContext returns a context of a caller authenticated with a client
+// certificate with the URI SAN.
+func callerContext(t *testing.T, uri string) context.Context {
+	t.Helper()
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	cert := &x509.Certificate{URIs: []*url.URL{u}}
+
+	return peer.NewContext(t.Context(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func TestServer_ExchangeToken(t *testing.T) {
+	const (
+		sessionID = "session-123"
+		tenantID  = "tenant-123"
+	)
+
+	tests := []struct {
+		name              string
+		req               *tokenexchangev1.ExchangeTokenRequest
+		accessTokenExpiry time.Duration
+		inactive          bool
+		blocked           bool
+		wantCode          codes.Code
+		wantAccessToken   string
+	}{
+		{
+			name:              "Exchanges the access token",
+			req:               newRequest(sessionID, tenantID, "orders-service"),
+			accessTokenExpiry: time.Hour,
+			wantCode:          codes.OK,
+			wantAccessToken:   "orders-service:old-access-token",
+		},
+		{
+			name:              "Refreshes an expiring access token first",
+			req:               newRequest(sessionID, tenantID, "orders-service"),
+			accessTokenExpiry: 30 * time.Second,
+			wantCode:          codes.OK,
+			wantAccessToken:   "orders-service:new-access-token",
+		},
+		{
+			name:              "Missing audience",
+			req:               newRequest(sessionID, tenantID, ""),
+			accessTokenExpiry: time.Hour,
+			wantCode:          codes.InvalidArgument,
+		},
+		{
+			name:              "Inactive session",
+			req:               newRequest(sessionID, tenantID, "orders-service"),
+			accessTokenExpiry: time.Hour,
+			inactive:          true,
+			wantCode:          codes.Unauthenticated,
+		},
+		{
+			name:              "Unknown session",
+			req:               newRequest("unknown-session", tenantID, "orders-service"),
+			accessTokenExpiry: time.Hour,
+			wantCode:          codes.Unauthenticated,
+		},
+		{
+			name:              "Session of another tenant",
+			req:               newRequest(sessionID, "other-tenant", "orders-service"),
+			accessTokenExpiry: time.Hour,
+			wantCode:          codes.Unauthenticated,
+		},
+		{
+			name:              "Blocked tenant",
+			req:               newRequest(sessionID, tenantID, "orders-service"),
+			accessTokenExpiry: time.Hour,
+			blocked:           true,
+			wantCode:          codes.FailedPrecondition,
+		},
+		{
+			name:              "Audience rejected by the provider",
+			req:               newRequest(sessionID, tenantID, "unknown-service"),
+			accessTokenExpiry: time.Hour,
+			wantCode:          codes.PermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := callerContext(t, caller)
+
+			provider := startProvider(t)
+			defer provider.Close()
+
+			sess := internalsession.Session{
+				ID:                sessionID,
+				TenantID:          tenantID,
+				Issuer:            provider.URL,
+				AccessToken:       "old-access-token",
+				RefreshToken:      "old-refresh-token",
+				AccessTokenExpiry: time.Now().Add(tt.accessTokenExpiry),
+				Expiry:            time.Now().Add(time.Hour),
+			}
+			sessionRepo := sessionmock.NewInMemRepository(sessionmock.WithSession(sess))
+			if !tt.inactive {
+				require.NoError(t, sessionRepo.BumpActive(ctx, sessionID, time.Hour))
+			}
+
+			trust := newTrust(mocktrust.NewInMemRepository(mocktrust.WithTrust(trustv1.Trust_builder{
+				TenantId: new(tenantID),
+				Blocked:  new(tt.blocked),
+				Oidc: oidcv1.OIDC_builder{
+					Issuer:   new(provider.URL),
+					ClientId: new("test-client-id"),
+				}.Build(),
+			}.Build())))
+
+			server := tokenexchange.NewServer(sessionRepo, trust, newManager(t, trust, sessionRepo), time.Minute,
+				tokenexchange.WithAllowedCallers([]string{caller}))
+
+			resp, err := server.ExchangeToken(ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err), "error: %v", err)
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			assert.Equal(t, tt.wantAccessToken, resp.GetAccessToken())
+			assert.Equal(t, "urn:ietf:params:oauth:token-type:access_token", resp.GetIssuedTokenType())
+			assert.Equal(t, "Bearer", resp.GetTokenType())
+			assert.InDelta(t, time.Now().Add(300*time.Second).Unix(), resp.GetExpiresAt(), 5)
+		})
+	}
+}
+
+func TestServer_ExchangeToken_Callers(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      func(t *testing.T) context.Context
+		wantCode codes.Code
+	}{
+		{
+			name:     "Caller without client certificate",
+			ctx:      func(t *testing.T) context.Context { return t.Context() },
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "Caller not allowed",
+			ctx: func(t *testing.T) context.Context {
+				return callerContext(t, "spiffe://cluster.local/ns/other/sa/other-service")
+			},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionRepo := sessionmock.NewInMemRepository()
+			trust := newTrust(mocktrust.NewInMemRepository())
+			server := tokenexchange.NewServer(sessionRepo, trust, newManager(t, trust, sessionRepo), time.Minute,
+				tokenexchange.WithAllowedCallers([]string{caller}))
+
+			_, err := server.ExchangeToken(tt.ctx(t), newRequest("session-123", "tenant-123", "orders-service"))
+			assert.Equal(t, tt.wantCode, status.Code(err), "error: %v", err)
+		})
+	}
+}
+
+func newRequest(sessionID, tenantID, audience string) *tokenexchangev1.ExchangeTokenRequest {
+	return &tokenexchangev1.ExchangeTokenRequest{
+		SessionId: new(sessionID),
+		TenantId:  new(tenantID),
+		Audience:  new(audience),
+	}
+}
+
+func newManager(t *testing.T, trust sessionmanager.Trust, sessionRepo internalsession.Repository) *internalsession.Manager {
+	t.Helper()
+
+	manager, err := internalsession.NewManager(t.Context(),
+		&config.SessionManager{CSRFSecretParsed: []byte("0123456789abcdef0123456789abcdef")},
+		trust,
+		sessionRepo,
+		nil,
+		internalsession.WithAllowHttpScheme(true),
+	)
+	require.NoError(t, err)
+
+	return manager
+}
@@ -30,6 +30,10 @@ func (stubTrust) Get(context.Context, string) (*trustv1.Trust, error) {
 	return nil, errStubTrustGet
 }
 
+func (stubTrust) ListByIssuer(context.Context, string) ([]*trustv1.Trust, error) {
+	return nil, errStubTrustGet
+}
+
 // stubTrustModule lets us register a fake trust module with the registry under
 // a custom ID for tests.
 type stubTrustModule struct {
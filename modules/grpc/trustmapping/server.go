@@ -3,16 +3,22 @@ package trustmapping
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	trustmappingv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/sessionmanager/trustmapping/v1"
+	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
 	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
 	slogctx "github.com/veqryn/slog-context"
 
 	sessionmanager "github.com/openkcm/session-manager"
+	internalsession "github.com/openkcm/session-manager/internal/session"
 	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
 )
 
 type Server struct {
@@ -42,6 +48,11 @@ func (srv *Server) ApplyTrustMapping(ctx context.Context, in *trustmappingv1.App
 
 	slogctx.Debug(ctx, "ApplyTrustMapping called")
 
+	if err := validateOIDC(oidc); err != nil {
+		slogctx.Warn(ctx, "Invalid trust", "error", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid trust: %v", err)
+	}
+
 	response := trustmappingv1.ApplyTrustMappingResponse_builder{}.Build()
 
 	if err := srv.trust.Apply(ctx, trust); err != nil {
@@ -60,6 +71,21 @@ func (srv *Server) ApplyTrustMapping(ctx context.Context, in *trustmappingv1.App
 	return response, nil
 }
 
+// validateOIDC validates the session manager specific settings of the trust.
+func validateOIDC(oidc *oidcv1.OIDC) error {
+	//nolint:forcetypeassert
+	scopes := proto.GetExtension(oidc, smoidcv1.E_Scopes).([]string)
+	if len(scopes) > 0 && !slices.Contains(scopes, "openid") {
+		return errors.New("scopes must include openid")
+	}
+
+	if _, err := internalsession.ClaimMappingOf(oidc); err != nil {
+		return fmt.Errorf("claim mapping: %w", err)
+	}
+
+	return nil
+}
+
 // BlockTrustMapping blocks the trust for the specified tenant.
 // It calls the underlying service to set the trust as blocked.
 // Returns a response containing an optional error message if blocking fails.
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	trustmappingv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/sessionmanager/trustmapping/v1"
 	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
@@ -16,6 +17,7 @@ import (
 	"github.com/openkcm/session-manager/modules/grpc/trustmapping"
 	mocktrust "github.com/openkcm/session-manager/modules/oidctrust/mocks"
 	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
 )
 
 func TestNewTrustMappingServer(t *testing.T) {
@@ -139,6 +141,75 @@ func TestApplyTrustMapping(t *testing.T) {
 		assert.Contains(t, st.Message(), "failed to apply trust")
 	})
 
+	t.Run("success - stores scopes", func(t *testing.T) {
+		repo := mocktrust.NewInMemRepository()
+		server := trustmapping.NewServer(newTrust(repo))
+
+		oidc := oidcv1.OIDC_builder{
+			Issuer: new("https://issuer.example.com"),
+		}.Build()
+		proto.SetExtension(oidc, smoidcv1.E_Scopes, []string{"openid", "offline_access"})
+		req := trustmappingv1.ApplyTrustMappingRequest_builder{
+			TenantId: new("tenant-123"),
+			Oidc:     oidc,
+		}.Build()
+
+		resp, err := server.ApplyTrustMapping(ctx, req)
+
+		require.NoError(t, err)
+		assert.True(t, resp.GetSuccess())
+		assert.Equal(t, []string{"openid", "offline_access"}, proto.GetExtension(repo.TGet("tenant-123").GetOidc(), smoidcv1.E_Scopes))
+	})
+
+	t.Run("invalid scopes - returns grpc error", func(t *testing.T) {
+		repo := mocktrust.NewInMemRepository()
+		server := trustmapping.NewServer(newTrust(repo))
+
+		oidc := oidcv1.OIDC_builder{
+			Issuer: new("https://issuer.example.com"),
+		}.Build()
+		proto.SetExtension(oidc, smoidcv1.E_Scopes, []string{"profile", "email"})
+		req := trustmappingv1.ApplyTrustMappingRequest_builder{
+			TenantId: new("tenant-123"),
+			Oidc:     oidc,
+		}.Build()
+
+		resp, err := server.ApplyTrustMapping(ctx, req)
+
+		assert.Nil(t, resp)
+		require.Error(t, err)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Nil(t, repo.TGet("tenant-123"), "invalid trust must not be stored")
+	})
+
+	t.Run("invalid claim mapping - returns grpc error", func(t *testing.T) {
+		repo := mocktrust.NewInMemRepository()
+		server := trustmapping.NewServer(newTrust(repo))
+
+		oidc := oidcv1.OIDC_builder{
+			Issuer: new("https://issuer.example.com"),
+		}.Build()
+		proto.SetExtension(oidc, smoidcv1.E_ClaimMapping, &smoidcv1.ClaimMapping{Groups: new("realm_access.roles[*]")})
+		req := trustmappingv1.ApplyTrustMappingRequest_builder{
+			TenantId: new("tenant-123"),
+			Oidc:     oidc,
+		}.Build()
+
+		resp, err := server.ApplyTrustMapping(ctx, req)
+
+		assert.Nil(t, resp)
+		require.Error(t, err)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Contains(t, st.Message(), "groups")
+		assert.Nil(t, repo.TGet("tenant-123"), "invalid trust must not be stored")
+	})
+
 	t.Run("update error - returns grpc error", func(t *testing.T) {
 		existingTrust := trustv1.Trust_builder{
 			TenantId: new("tenant-123"),
@@ -1,13 +1,14 @@
 -- name: GetTrust :one
-SELECT
-    issuer,
-    blocked,
-    jwks_uri,
-    audiences,
-    client_id
+SELECT *
 FROM trust
 WHERE tenant_id = sqlc.arg(tenant_id);
 
+-- name: ListTrustsByIssuer :many
+SELECT *
+FROM trust
+WHERE issuer = sqlc.arg(issuer)
+ORDER BY tenant_id;
+
 -- name: CreateTrust :exec
 INSERT INTO trust (
     tenant_id,
@@ -15,14 +16,22 @@ INSERT INTO trust (
     issuer,
     jwks_uri,
     audiences,
-    client_id)
+    client_id,
+    require_par,
+    scopes,
+    claim_mapping,
+    fetch_userinfo)
 VALUES (
     sqlc.arg(tenant_id),
     sqlc.arg(blocked),
     sqlc.arg(issuer),
     sqlc.arg(jwks_uri),
     COALESCE(sqlc.arg(audiences)::text[], '{}'::text[]),
-    sqlc.arg(client_id));
+    sqlc.arg(client_id),
+    sqlc.arg(require_par),
+    COALESCE(sqlc.arg(scopes)::text[], '{}'::text[]),
+    sqlc.arg(claim_mapping),
+    sqlc.arg(fetch_userinfo));
 
 -- name: DeleteTrust :execrows
 DELETE FROM trust
@@ -35,6 +44,10 @@ SET
     issuer = sqlc.arg(issuer),
     jwks_uri = sqlc.arg(jwks_uri),
     audiences = COALESCE(sqlc.arg(audiences)::text[], '{}'::text[]),
-    client_id = sqlc.arg(client_id)
+    client_id = sqlc.arg(client_id),
+    require_par = sqlc.arg(require_par),
+    scopes = COALESCE(sqlc.arg(scopes)::text[], '{}'::text[]),
+    claim_mapping = sqlc.arg(claim_mapping),
+    fetch_userinfo = sqlc.arg(fetch_userinfo)
 WHERE
     tenant_id = sqlc.arg(tenant_id);
@@ -9,11 +9,15 @@ import (
 )
 
 type Trust struct {
-	TenantID  string           `db:"tenant_id"`
-	Blocked   bool             `db:"blocked"`
-	Issuer    string           `db:"issuer"`
-	JwksUri   string           `db:"jwks_uri"`
-	Audiences []string         `db:"audiences"`
-	CreatedAt pgtype.Timestamp `db:"created_at"`
-	ClientID  pgtype.Text      `db:"client_id"`
+	TenantID      string           `db:"tenant_id"`
+	Blocked       bool             `db:"blocked"`
+	Issuer        string           `db:"issuer"`
+	JwksUri       string           `db:"jwks_uri"`
+	Audiences     []string         `db:"audiences"`
+	CreatedAt     pgtype.Timestamp `db:"created_at"`
+	ClientID      pgtype.Text      `db:"client_id"`
+	RequirePar    bool             `db:"require_par"`
+	Scopes        []string         `db:"scopes"`
+	ClaimMapping  []byte           `db:"claim_mapping"`
+	FetchUserinfo bool             `db:"fetch_userinfo"`
 }
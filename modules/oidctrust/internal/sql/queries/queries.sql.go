@@ -18,23 +18,35 @@ INSERT INTO trust (
     issuer,
     jwks_uri,
     audiences,
-    client_id)
+    client_id,
+    require_par,
+    scopes,
+    claim_mapping,
+    fetch_userinfo)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     COALESCE($5::text[], '{}'::text[]),
-    $6)
+    $6,
+    $7,
+    COALESCE($8::text[], '{}'::text[]),
+    $9,
+    $10)
 `
 
 type CreateTrustParams struct {
-	TenantID  string      `db:"tenant_id"`
-	Blocked   bool        `db:"blocked"`
-	Issuer    string      `db:"issuer"`
-	JwksUri   string      `db:"jwks_uri"`
-	Audiences []string    `db:"audiences"`
-	ClientID  pgtype.Text `db:"client_id"`
+	TenantID      string      `db:"tenant_id"`
+	Blocked       bool        `db:"blocked"`
+	Issuer        string      `db:"issuer"`
+	JwksUri       string      `db:"jwks_uri"`
+	Audiences     []string    `db:"audiences"`
+	ClientID      pgtype.Text `db:"client_id"`
+	RequirePar    bool        `db:"require_par"`
+	Scopes        []string    `db:"scopes"`
+	ClaimMapping  []byte      `db:"claim_mapping"`
+	FetchUserinfo bool        `db:"fetch_userinfo"`
 }
 
 func (q *Queries) CreateTrust(ctx context.Context, arg CreateTrustParams) error {
@@ -45,6 +57,10 @@ func (q *Queries) CreateTrust(ctx context.Context, arg CreateTrustParams) error
 		arg.JwksUri,
 		arg.Audiences,
 		arg.ClientID,
+		arg.RequirePar,
+		arg.Scopes,
+		arg.ClaimMapping,
+		arg.FetchUserinfo,
 	)
 	return err
 }
@@ -63,37 +79,69 @@ func (q *Queries) DeleteTrust(ctx context.Context, tenantID string) (int64, erro
 }
 
 const getTrust = `-- name: GetTrust :one
-SELECT
-    issuer,
-    blocked,
-    jwks_uri,
-    audiences,
-    client_id
+SELECT tenant_id, blocked, issuer, jwks_uri, audiences, created_at, client_id, require_par, scopes, claim_mapping, fetch_userinfo
 FROM trust
 WHERE tenant_id = $1
 `
 
-type GetTrustRow struct {
-	Issuer    string      `db:"issuer"`
-	Blocked   bool        `db:"blocked"`
-	JwksUri   string      `db:"jwks_uri"`
-	Audiences []string    `db:"audiences"`
-	ClientID  pgtype.Text `db:"client_id"`
-}
-
-func (q *Queries) GetTrust(ctx context.Context, tenantID string) (GetTrustRow, error) {
+func (q *Queries) GetTrust(ctx context.Context, tenantID string) (Trust, error) {
 	row := q.db.QueryRow(ctx, getTrust, tenantID)
-	var i GetTrustRow
+	var i Trust
 	err := row.Scan(
-		&i.Issuer,
+		&i.TenantID,
 		&i.Blocked,
+		&i.Issuer,
 		&i.JwksUri,
 		&i.Audiences,
+		&i.CreatedAt,
 		&i.ClientID,
+		&i.RequirePar,
+		&i.Scopes,
+		&i.ClaimMapping,
+		&i.FetchUserinfo,
 	)
 	return i, err
 }
 
+const listTrustsByIssuer = `-- name: ListTrustsByIssuer :many
+SELECT tenant_id, blocked, issuer, jwks_uri, audiences, created_at, client_id, require_par, scopes, claim_mapping, fetch_userinfo
+FROM trust
+WHERE issuer = $1
+ORDER BY tenant_id
+`
+
+func (q *Queries) ListTrustsByIssuer(ctx context.Context, issuer string) ([]Trust, error) {
+	rows, err := q.db.Query(ctx, listTrustsByIssuer, issuer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Trust
+	for rows.Next() {
+		var i Trust
+		if err := rows.Scan(
+			&i.TenantID,
+			&i.Blocked,
+			&i.Issuer,
+			&i.JwksUri,
+			&i.Audiences,
+			&i.CreatedAt,
+			&i.ClientID,
+			&i.RequirePar,
+			&i.Scopes,
+			&i.ClaimMapping,
+			&i.FetchUserinfo,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTrust = `-- name: UpdateTrust :execrows
 UPDATE trust
 SET
@@ -101,18 +149,26 @@ SET
     issuer = $2,
     jwks_uri = $3,
     audiences = COALESCE($4::text[], '{}'::text[]),
-    client_id = $5
+    client_id = $5,
+    require_par = $6,
+    scopes = COALESCE($7::text[], '{}'::text[]),
+    claim_mapping = $8,
+    fetch_userinfo = $9
 WHERE
-    tenant_id = $6
+    tenant_id = $10
 `
 
 type UpdateTrustParams struct {
-	Blocked   bool        `db:"blocked"`
-	Issuer    string      `db:"issuer"`
-	JwksUri   string      `db:"jwks_uri"`
-	Audiences []string    `db:"audiences"`
-	ClientID  pgtype.Text `db:"client_id"`
-	TenantID  string      `db:"tenant_id"`
+	Blocked       bool        `db:"blocked"`
+	Issuer        string      `db:"issuer"`
+	JwksUri       string      `db:"jwks_uri"`
+	Audiences     []string    `db:"audiences"`
+	ClientID      pgtype.Text `db:"client_id"`
+	RequirePar    bool        `db:"require_par"`
+	Scopes        []string    `db:"scopes"`
+	ClaimMapping  []byte      `db:"claim_mapping"`
+	FetchUserinfo bool        `db:"fetch_userinfo"`
+	TenantID      string      `db:"tenant_id"`
 }
 
 func (q *Queries) UpdateTrust(ctx context.Context, arg UpdateTrustParams) (int64, error) {
@@ -122,6 +178,10 @@ func (q *Queries) UpdateTrust(ctx context.Context, arg UpdateTrustParams) (int64
 		arg.JwksUri,
 		arg.Audiences,
 		arg.ClientID,
+		arg.RequirePar,
+		arg.Scopes,
+		arg.ClaimMapping,
+		arg.FetchUserinfo,
 		arg.TenantID,
 	)
 	if err != nil {
@@ -9,6 +9,8 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
 	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
 	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
@@ -16,6 +18,7 @@ import (
 	sessionmanager "github.com/openkcm/session-manager"
 	"github.com/openkcm/session-manager/modules/oidctrust/internal/sql/queries"
 	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
 )
 
 type Repository struct {
@@ -43,8 +46,44 @@ func (r *Repository) Get(ctx context.Context, tenantID string) (*trustv1.Trust,
 		return nil, err
 	}
 
+	trust, err := trustFromRow(row)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return trust, nil
+}
+
+func (r *Repository) ListByIssuer(ctx context.Context, issuer string) ([]*trustv1.Trust, error) {
+	tracer := otel.GetTracerProvider()
+	ctx, span := tracer.Tracer("").Start(ctx, "list_trusts_by_issuer_sql")
+	defer span.End()
+
+	rows, err := r.queries.ListTrustsByIssuer(ctx, issuer)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("listing trusts: %w", err)
+	}
+
+	trusts := make([]*trustv1.Trust, 0, len(rows))
+	for _, row := range rows {
+		trust, err := trustFromRow(row)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		trusts = append(trusts, trust)
+	}
+
+	return trusts, nil
+}
+
+// trustFromRow converts a row of the trust table into a trust message.
+func trustFromRow(row queries.Trust) (*trustv1.Trust, error) {
 	trust := trustv1.Trust_builder{
-		TenantId: &tenantID,
+		TenantId: &row.TenantID,
 		Blocked:  &row.Blocked,
 		Oidc: oidcv1.OIDC_builder{
 			Audiences: row.Audiences,
@@ -63,6 +102,27 @@ func (r *Repository) Get(ctx context.Context, tenantID string) (*trustv1.Trust,
 		trust.GetOidc().SetClientId(row.ClientID.String)
 	}
 
+	if row.RequirePar {
+		proto.SetExtension(trust.GetOidc(), smoidcv1.E_RequirePushedAuthorizationRequests, true)
+	}
+
+	if len(row.Scopes) > 0 {
+		proto.SetExtension(trust.GetOidc(), smoidcv1.E_Scopes, row.Scopes)
+	}
+
+	if row.FetchUserinfo {
+		proto.SetExtension(trust.GetOidc(), smoidcv1.E_FetchUserinfo, true)
+	}
+
+	if len(row.ClaimMapping) > 0 && string(row.ClaimMapping) != "{}" {
+		claimMapping := &smoidcv1.ClaimMapping{}
+		if err := protojson.Unmarshal(row.ClaimMapping, claimMapping); err != nil {
+			return nil, fmt.Errorf("decoding claim mapping: %w", err)
+		}
+
+		proto.SetExtension(trust.GetOidc(), smoidcv1.E_ClaimMapping, claimMapping)
+	}
+
 	return trust, nil
 }
 
@@ -73,13 +133,23 @@ func (r *Repository) Create(ctx context.Context, trust *trustv1.Trust) error {
 
 	oidc := trust.GetOidc()
 
+	claimMapping, err := claimMapping(oidc)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
 	if err := r.queries.CreateTrust(ctx, queries.CreateTrustParams{
-		TenantID:  trust.GetTenantId(),
-		Blocked:   trust.GetBlocked(),
-		Issuer:    oidc.GetIssuer(),
-		JwksUri:   oidc.GetJwksUri(),
-		Audiences: oidc.GetAudiences(),
-		ClientID:  pgTextOrNull(trust.GetOidc().GetClientId()),
+		TenantID:      trust.GetTenantId(),
+		Blocked:       trust.GetBlocked(),
+		Issuer:        oidc.GetIssuer(),
+		JwksUri:       oidc.GetJwksUri(),
+		Audiences:     oidc.GetAudiences(),
+		ClientID:      pgTextOrNull(trust.GetOidc().GetClientId()),
+		RequirePar:    requirePAR(oidc),
+		Scopes:        scopes(oidc),
+		ClaimMapping:  claimMapping,
+		FetchUserinfo: fetchUserInfo(oidc),
 	}); err != nil {
 		span.RecordError(err)
 		if err, ok := handlePgError(err); ok {
@@ -117,13 +187,23 @@ func (r *Repository) Update(ctx context.Context, trust *trustv1.Trust) error {
 
 	oidc := trust.GetOidc()
 
+	claimMapping, err := claimMapping(oidc)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
 	affected, err := r.queries.UpdateTrust(ctx, queries.UpdateTrustParams{
-		Blocked:   trust.GetBlocked(),
-		Issuer:    oidc.GetIssuer(),
-		JwksUri:   oidc.GetJwksUri(),
-		Audiences: oidc.GetAudiences(),
-		ClientID:  pgTextOrNull(oidc.GetClientId()),
-		TenantID:  trust.GetTenantId(),
+		Blocked:       trust.GetBlocked(),
+		Issuer:        oidc.GetIssuer(),
+		JwksUri:       oidc.GetJwksUri(),
+		Audiences:     oidc.GetAudiences(),
+		ClientID:      pgTextOrNull(oidc.GetClientId()),
+		RequirePar:    requirePAR(oidc),
+		Scopes:        scopes(oidc),
+		ClaimMapping:  claimMapping,
+		FetchUserinfo: fetchUserInfo(oidc),
+		TenantID:      trust.GetTenantId(),
 	})
 	if err != nil {
 		span.RecordError(err)
@@ -144,6 +224,36 @@ func pgTextOrNull(s string) pgtype.Text {
 	}
 }
 
+func requirePAR(oidc *oidcv1.OIDC) bool {
+	//nolint:forcetypeassert
+	return proto.GetExtension(oidc, smoidcv1.E_RequirePushedAuthorizationRequests).(bool)
+}
+
+func scopes(oidc *oidcv1.OIDC) []string {
+	//nolint:forcetypeassert
+	return proto.GetExtension(oidc, smoidcv1.E_Scopes).([]string)
+}
+
+func fetchUserInfo(oidc *oidcv1.OIDC) bool {
+	//nolint:forcetypeassert
+	return proto.GetExtension(oidc, smoidcv1.E_FetchUserinfo).(bool)
+}
+
+func claimMapping(oidc *oidcv1.OIDC) ([]byte, error) {
+	//nolint:forcetypeassert
+	m := proto.GetExtension(oidc, smoidcv1.E_ClaimMapping).(*smoidcv1.ClaimMapping)
+	if m == nil {
+		return []byte("{}"), nil
+	}
+
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("encoding claim mapping: %w", err)
+	}
+
+	return b, nil
+}
+
 func handlePgError(err error) (error, bool) {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -15,6 +15,7 @@ import (
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
 
 	oidcv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
@@ -24,6 +25,7 @@ import (
 	"github.com/openkcm/session-manager/internal/dbtest/postgrestest"
 	sqltrust "github.com/openkcm/session-manager/modules/oidctrust/internal/sql"
 	"github.com/openkcm/session-manager/pkg/serviceerr"
+	smoidcv1 "github.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1"
 )
 
 var dbPool sessionmanager.Database
@@ -84,6 +86,37 @@ func TestRepository_Get(t *testing.T) {
 	}
 }
 
+func TestRepository_ListByIssuer(t *testing.T) {
+	tests := []struct {
+		name       string
+		issuer     string
+		wantTrusts []*trustv1.Trust
+	}{
+		{
+			name:       "Success",
+			issuer:     "url-two",
+			wantTrusts: []*trustv1.Trust{trustv1.Trust_builder{TenantId: new("tenant2-id"), Blocked: new(false), Oidc: oidcv1.OIDC_builder{Issuer: new("url-two"), Audiences: make([]string, 0)}.Build()}.Build()},
+		},
+		{
+			name:       "Unknown issuer",
+			issuer:     "does-not-exist",
+			wantTrusts: []*trustv1.Trust{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := sqltrust.NewRepository(dbPool)
+
+			gotTrusts, err := r.ListByIssuer(t.Context(), tt.issuer)
+			require.NoError(t, err)
+
+			if diff := cmp.Diff(tt.wantTrusts, gotTrusts, protocmp.Transform()); diff != "" {
+				t.Fatalf("trusts not equal:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestRepository_Create(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -115,6 +148,26 @@ func TestRepository_Create(t *testing.T) {
 			trust:     trustv1.Trust_builder{TenantId: new("tenant-id-create-without-aud-success"), Blocked: new(false), Oidc: oidcv1.OIDC_builder{Issuer: new("http://oidc-success-4.example.com"), JwksUri: new("jwks.example.com"), Audiences: []string{}}.Build()}.Build(),
 			assertErr: assert.NoError,
 		},
+		{
+			name:      "Create with required PAR succeeds",
+			trust:     trustv1.Trust_builder{TenantId: new("tenant-id-create-require-par-success"), Blocked: new(false), Oidc: withRequirePAR(oidcv1.OIDC_builder{Issuer: new("http://oidc-success-5.example.com"), Audiences: []string{}}.Build())}.Build(),
+			assertErr: assert.NoError,
+		},
+		{
+			name:      "Create with scopes succeeds",
+			trust:     trustv1.Trust_builder{TenantId: new("tenant-id-create-scopes-success"), Blocked: new(false), Oidc: withScopes(oidcv1.OIDC_builder{Issuer: new("http://oidc-success-6.example.com"), Audiences: []string{}}.Build(), "openid", "offline_access")}.Build(),
+			assertErr: assert.NoError,
+		},
+		{
+			name:      "Create with claim mapping succeeds",
+			trust:     trustv1.Trust_builder{TenantId: new("tenant-id-create-claim-mapping-success"), Blocked: new(false), Oidc: withClaimMapping(oidcv1.OIDC_builder{Issuer: new("http://oidc-success-7.example.com"), Audiences: []string{}}.Build(), &smoidcv1.ClaimMapping{Subject: new("oid"), Groups: new("realm_access.roles")})}.Build(),
+			assertErr: assert.NoError,
+		},
+		{
+			name:      "Create with userinfo enrichment succeeds",
+			trust:     trustv1.Trust_builder{TenantId: new("tenant-id-create-fetch-userinfo-success"), Blocked: new(false), Oidc: withFetchUserInfo(oidcv1.OIDC_builder{Issuer: new("http://oidc-success-8.example.com"), Audiences: []string{}}.Build())}.Build(),
+			assertErr: assert.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -232,6 +285,26 @@ func TestRepository_Update(t *testing.T) {
 	}
 }
 
+func withRequirePAR(oidc *oidcv1.OIDC) *oidcv1.OIDC {
+	proto.SetExtension(oidc, smoidcv1.E_RequirePushedAuthorizationRequests, true)
+	return oidc
+}
+
+func withScopes(oidc *oidcv1.OIDC, scopes ...string) *oidcv1.OIDC {
+	proto.SetExtension(oidc, smoidcv1.E_Scopes, scopes)
+	return oidc
+}
+
+func withFetchUserInfo(oidc *oidcv1.OIDC) *oidcv1.OIDC {
+	proto.SetExtension(oidc, smoidcv1.E_FetchUserinfo, true)
+	return oidc
+}
+
+func withClaimMapping(oidc *oidcv1.OIDC, m *smoidcv1.ClaimMapping) *oidcv1.OIDC {
+	proto.SetExtension(oidc, smoidcv1.E_ClaimMapping, m)
+	return oidc
+}
+
 func TestPgTextOrNull(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE trust
+    ADD COLUMN claim_mapping JSONB NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE trust
+    DROP COLUMN claim_mapping;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE trust
+    ADD COLUMN fetch_userinfo BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE trust
+    DROP COLUMN fetch_userinfo;
+-- +goose StatementEnd
@@ -2,6 +2,8 @@ package mocktrust
 
 import (
 	"context"
+	"slices"
+	"strings"
 
 	trustv1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/v1"
 
@@ -67,6 +69,20 @@ func (r *Repository) Get(_ context.Context, tenantID string) (*trustv1.Trust, er
 	return nil, serviceerr.ErrNotFound
 }
 
+func (r *Repository) ListByIssuer(_ context.Context, issuer string) ([]*trustv1.Trust, error) {
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	var trusts []*trustv1.Trust
+	for _, trust := range r.tenantTrust {
+		if trust.GetOidc().GetIssuer() == issuer {
+			trusts = append(trusts, trust)
+		}
+	}
+	slices.SortFunc(trusts, func(a, b *trustv1.Trust) int { return strings.Compare(a.GetTenantId(), b.GetTenantId()) })
+	return trusts, nil
+}
+
 func (r *Repository) Create(_ context.Context, trust *trustv1.Trust) error {
 	if r.createErr != nil {
 		return r.createErr
@@ -30,6 +30,7 @@ const (
 type RepoWrapper struct {
 	Repo       oidctrust.TrustRepository
 	MockGet    func(ctx context.Context, tenantID string) (*trustv1.Trust, error)
+	MockList   func(ctx context.Context, issuer string) ([]*trustv1.Trust, error)
 	MockCreate func(ctx context.Context, trust *trustv1.Trust) error
 	MockDelete func(ctx context.Context, tenantID string) error
 	MockUpdate func(ctx context.Context, trust *trustv1.Trust) error
@@ -73,6 +74,14 @@ func (m *RepoWrapper) Get(ctx context.Context, tenantID string) (*trustv1.Trust,
 	return m.Repo.Get(ctx, tenantID)
 }
 
+// ListByIssuer implements oidc.OIDCTrustRepository.
+func (m *RepoWrapper) ListByIssuer(ctx context.Context, issuer string) ([]*trustv1.Trust, error) {
+	if m.MockList != nil {
+		return m.MockList(ctx, issuer)
+	}
+	return m.Repo.ListByIssuer(ctx, issuer)
+}
+
 // Update implements oidc.OIDCTrustRepository.
 func (m *RepoWrapper) Update(ctx context.Context, trust *trustv1.Trust) error {
 	if m.MockUpdate != nil {
@@ -94,6 +94,19 @@ func (m *TrustModule) Get(ctx context.Context, tenantID string) (*trustv1.Trust,
 	return trust, nil
 }
 
+// ListByIssuer implements [sessionmanager.Trust].
+func (m *TrustModule) ListByIssuer(ctx context.Context, issuer string) ([]*trustv1.Trust, error) {
+	trusts, err := m.repository.ListByIssuer(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("listing trusts from repository: %w", err)
+	}
+
+	for _, trust := range trusts {
+		m.resolveExtensions(trust)
+	}
+	return trusts, nil
+}
+
 // resolveExtensions sets optional extensions to the Trust message and its details if configured.
 func (m *TrustModule) resolveExtensions(trust *trustv1.Trust) {
 	switch trust.WhichDetails() {
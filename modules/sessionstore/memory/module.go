@@ -0,0 +1,100 @@
+// Package memory provides the sessionstore.module.memory module: a
+// session.Repository kept in the memory of the process, configured by the
+// top-level valkey: config block. It needs no external services and is meant
+// for local development, tests and single-replica deployments.
+//
+// All modules loaded with the same prefix in one process share one repository.
+// The sessions are not shared with other processes, so the housekeeper
+// subcommand does not see them; enable housekeeper.inProcess to run the
+// housekeeping jobs in the api-server instead. Sessions are lost when the
+// process exits.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionmemory "github.com/openkcm/session-manager/internal/session/memory"
+)
+
+const moduleID = "sessionstore.module.memory"
+
+var (
+	repositoriesMu sync.Mutex
+	repositories   = make(map[string]*sessionmemory.Repository)
+)
+
+func init() {
+	sessionmanager.RegisterModule(new(Module))
+}
+
+func newModule() sessionmanager.Module {
+	return new(Module)
+}
+
+// Module is the sessionstore.module.memory module. It exposes the in-memory
+// session.Repository of its prefix.
+type Module struct {
+	*sessionmemory.Repository
+
+	Mod           string        `yaml:"module"`
+	Prefix        string        `yaml:"prefix"`
+	SweepInterval time.Duration `yaml:"sweepInterval" default:"1m"`
+
+	stopSweeper context.CancelFunc
+	sweeper     sync.WaitGroup
+}
+
+func (m *Module) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  moduleID,
+		New: newModule,
+	}
+}
+
+func (m *Module) Provision(ctx *sessionmanager.Context) error {
+	if m.SweepInterval <= 0 {
+		return fmt.Errorf("sweepInterval must be positive, got %s", m.SweepInterval)
+	}
+
+	m.Repository = sharedRepository(m.Prefix)
+
+	sweepCtx, cancel := context.WithCancel(ctx)
+	m.stopSweeper = cancel
+	m.sweeper.Go(func() {
+		m.Repository.Sweep(sweepCtx, m.SweepInterval)
+	})
+
+	return nil
+}
+
+func (m *Module) Close() error {
+	if m.stopSweeper == nil {
+		return nil
+	}
+	m.stopSweeper()
+	m.sweeper.Wait()
+	return nil
+}
+
+// sharedRepository returns the repository of the prefix, creating it on first use.
+func sharedRepository(prefix string) *sessionmemory.Repository {
+	repositoriesMu.Lock()
+	defer repositoriesMu.Unlock()
+
+	r, ok := repositories[prefix]
+	if !ok {
+		r = sessionmemory.NewRepository()
+		repositories[prefix] = r
+	}
+
+	return r
+}
+
+// Compile-time guarantee that the module satisfies session.Repository via the
+// embedded *sessionmemory.Repository.
+var _ session.Repository = (*Module)(nil)
@@ -0,0 +1,62 @@
+package memory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionstorememory "github.com/openkcm/session-manager/modules/sessionstore/memory"
+)
+
+func TestModule_RegistrationAndID(t *testing.T) {
+	info, err := sessionmanager.GetModule("sessionstore.module.memory")
+	require.NoError(t, err)
+	assert.Equal(t, "sessionstore.module.memory", info.ID)
+
+	mod := info.New()
+	require.NotNil(t, mod)
+	_, ok := mod.(*sessionstorememory.Module)
+	assert.True(t, ok, "New() must return *Module")
+}
+
+func TestModule_CloseBeforeProvisionIsSafe(t *testing.T) {
+	m := new(sessionstorememory.Module)
+	require.NoError(t, m.Close(), "Close before Provision must not error")
+}
+
+func TestModule_ProvisionRejectsNonPositiveSweepInterval(t *testing.T) {
+	ctx, cancel := sessionmanager.NewContext(t.Context())
+	defer cancel(nil)
+
+	m := new(sessionstorememory.Module)
+	require.Error(t, m.Provision(ctx))
+	require.NoError(t, m.Close())
+}
+
+func TestModule_SharesRepositoryPerPrefix(t *testing.T) {
+	ctx, cancel := sessionmanager.NewContext(t.Context())
+	defer cancel(nil)
+
+	provision := func(prefix string) *sessionstorememory.Module {
+		m := &sessionstorememory.Module{Prefix: prefix, SweepInterval: time.Minute}
+		require.NoError(t, m.Provision(ctx))
+		t.Cleanup(func() { require.NoError(t, m.Close()) })
+		return m
+	}
+	apiServer := provision("shared-prefix-test")
+	housekeeper := provision("shared-prefix-test")
+	other := provision("other-prefix-test")
+
+	sess := session.Session{ID: "session-id", ProviderID: "provider-id", Expiry: time.Now().Add(time.Hour)}
+	require.NoError(t, apiServer.StoreSession(ctx, sess))
+
+	_, err := housekeeper.LoadSession(ctx, sess.ID)
+	require.NoError(t, err, "modules with the same prefix must share the sessions")
+
+	_, err = other.LoadSession(ctx, sess.ID)
+	require.Error(t, err, "modules with another prefix must not share the sessions")
+}
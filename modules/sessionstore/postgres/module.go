@@ -0,0 +1,90 @@
+// Package postgres provides the sessionstore.module.postgres module: a
+// session.Repository backed by PostgreSQL, configured by the top-level valkey:
+// config block. Its tables are created by the migrate command and expired
+// rows are deleted by a sweeper running while the module is loaded.
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	"github.com/openkcm/session-manager/internal/session"
+	sessionpostgres "github.com/openkcm/session-manager/internal/session/postgres"
+)
+
+const moduleID = "sessionstore.module.postgres"
+
+func init() {
+	sessionmanager.RegisterModule(new(Module))
+}
+
+func newModule() sessionmanager.Module {
+	return new(Module)
+}
+
+// Module is the sessionstore.module.postgres module. It exposes a
+// session.Repository backed by the database module.
+type Module struct {
+	*sessionpostgres.Repository
+
+	Mod           string        `yaml:"module"`
+	DBModule      string        `yaml:"dbModule" default:"database.module.pgxpool" dep:"sessionmanager.Database"`
+	SweepInterval time.Duration `yaml:"sweepInterval" default:"1m"`
+
+	db          sessionmanager.Database
+	stopSweeper context.CancelFunc
+	sweeper     sync.WaitGroup
+}
+
+func (m *Module) Module() sessionmanager.ModuleInfo {
+	return sessionmanager.ModuleInfo{
+		ID:  moduleID,
+		New: newModule,
+	}
+}
+
+func (m *Module) Provision(ctx *sessionmanager.Context) error {
+	if m.SweepInterval <= 0 {
+		return fmt.Errorf("sweepInterval must be positive, got %s", m.SweepInterval)
+	}
+
+	db, err := sessionmanager.GetModuleAs[sessionmanager.Database](ctx, m.DBModule)
+	if err != nil {
+		return fmt.Errorf("getting postgres module: %w", err)
+	}
+
+	m.db = db
+	m.Repository = sessionpostgres.NewRepository(db)
+
+	sweepCtx, cancel := context.WithCancel(ctx)
+	m.stopSweeper = cancel
+	m.sweeper.Go(func() {
+		m.Repository.Sweep(sweepCtx, m.SweepInterval)
+	})
+
+	return nil
+}
+
+// Migrate applies the migrations of the session store tables.
+func (m *Module) Migrate(ctx context.Context) error {
+	return sessionpostgres.Migrate(ctx, m.db.STDAdapter())
+}
+
+func (m *Module) Close() error {
+	if m.stopSweeper == nil {
+		return nil
+	}
+	m.stopSweeper()
+	m.sweeper.Wait()
+	return nil
+}
+
+// Compile-time guarantee that the module satisfies session.Repository via the
+// embedded *sessionpostgres.Repository and is run by the migrate command.
+var (
+	_ session.Repository     = (*Module)(nil)
+	_ sessionmanager.Migrate = (*Module)(nil)
+)
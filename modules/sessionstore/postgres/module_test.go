@@ -0,0 +1,36 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sessionmanager "github.com/openkcm/session-manager"
+	sessionstorepostgres "github.com/openkcm/session-manager/modules/sessionstore/postgres"
+)
+
+func TestModule_RegistrationAndID(t *testing.T) {
+	info, err := sessionmanager.GetModule("sessionstore.module.postgres")
+	require.NoError(t, err)
+	assert.Equal(t, "sessionstore.module.postgres", info.ID)
+
+	mod := info.New()
+	require.NotNil(t, mod)
+	_, ok := mod.(*sessionstorepostgres.Module)
+	assert.True(t, ok, "New() must return *Module")
+}
+
+func TestModule_CloseBeforeProvisionIsSafe(t *testing.T) {
+	m := new(sessionstorepostgres.Module)
+	require.NoError(t, m.Close(), "Close before Provision must not error")
+}
+
+func TestModule_ProvisionRejectsNonPositiveSweepInterval(t *testing.T) {
+	ctx, cancel := sessionmanager.NewContext(t.Context())
+	defer cancel(nil)
+
+	m := &sessionstorepostgres.Module{DBModule: "database.module.pgxpool"}
+	require.Error(t, m.Provision(ctx))
+	require.NoError(t, m.Close())
+}
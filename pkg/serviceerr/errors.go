@@ -34,6 +34,12 @@ const (
 	CodeUnsupportedGrantType Code = "unsupported_grant_type"
 )
 
+// Defined by RFC8693
+// https://www.rfc-editor.org/rfc/rfc8693.html#section-2.2.2
+const (
+	CodeInvalidTarget Code = "invalid_target"
+)
+
 // Custom defined
 const (
 	CodeUnknown                Code = "unknown"
@@ -44,6 +50,15 @@ const (
 	CodeInvalidCSRFToken       Code = "invalid_csrf_token"
 	CodeInvalidLoginCSRFToken  Code = "invalid_login_csrf_token"
 	CodeInvalidAtHashToken     Code = "invalid_at_hash_token"
+	CodeInvalidNonce           Code = "invalid_nonce"
+	CodeInvalidIDTokenIssuer   Code = "invalid_id_token_issuer"
+	CodeInvalidIDTokenAudience Code = "invalid_id_token_audience"
+	CodeInvalidIDTokenAZP      Code = "invalid_id_token_azp"
+	CodeIDTokenExpired         Code = "id_token_expired"
+	CodeIDTokenNotValidYet     Code = "id_token_not_valid_yet"
+	CodeInvalidIDTokenACR      Code = "invalid_id_token_acr"
+	CodeInvalidIDTokenAuthTime Code = "invalid_id_token_auth_time"
+	CodeInvalidUserInfoSubject Code = "invalid_userinfo_subject"
 	CodeEndSessionNotSupported Code = "end_session_not_supported"
 )
 
@@ -68,17 +83,32 @@ var (
 	ErrUnsupportedGrantType = newErr("", CodeUnsupportedGrantType)
 )
 
+// Defined by RFC8693
+// https://www.rfc-editor.org/rfc/rfc8693.html#section-2.2.2
+var (
+	ErrInvalidTarget = newErr("", CodeInvalidTarget)
+)
+
 // Custom defined
 var (
-	ErrUnknown               = newErr("unknown error", CodeUnknown)
-	ErrConflict              = newErr("already exists", CodeConflict)
-	ErrNotFound              = newErr("not found", CodeNotFound)
-	ErrStateExpired          = newErr("state expired", CodeStateExpired)
-	ErrInvalidOIDCProvider   = newErr("invalid OIDC provider", CodeInvalidOIDCProvider)
-	ErrInvalidCSRFToken      = newErr("invalid CSRF token", CodeInvalidCSRFToken)
-	ErrUnauthorized          = newErr("unauthorized", CodeUnauthorizedClient)
-	ErrInvalidAtHash         = newErr("invalid atHash token", CodeInvalidAtHashToken)
-	ErrInvalidLoginCSRFToken = newErr("invalid login CSRF token", CodeInvalidLoginCSRFToken)
+	ErrUnknown                = newErr("unknown error", CodeUnknown)
+	ErrConflict               = newErr("already exists", CodeConflict)
+	ErrNotFound               = newErr("not found", CodeNotFound)
+	ErrStateExpired           = newErr("state expired", CodeStateExpired)
+	ErrInvalidOIDCProvider    = newErr("invalid OIDC provider", CodeInvalidOIDCProvider)
+	ErrInvalidCSRFToken       = newErr("invalid CSRF token", CodeInvalidCSRFToken)
+	ErrUnauthorized           = newErr("unauthorized", CodeUnauthorizedClient)
+	ErrInvalidAtHash          = newErr("invalid atHash token", CodeInvalidAtHashToken)
+	ErrInvalidNonce           = newErr("invalid nonce", CodeInvalidNonce)
+	ErrInvalidIDTokenIssuer   = newErr("invalid ID token issuer", CodeInvalidIDTokenIssuer)
+	ErrInvalidIDTokenAud      = newErr("invalid ID token audience", CodeInvalidIDTokenAudience)
+	ErrInvalidIDTokenAZP      = newErr("invalid ID token authorized party", CodeInvalidIDTokenAZP)
+	ErrIDTokenExpired         = newErr("ID token expired", CodeIDTokenExpired)
+	ErrIDTokenNotValidYet     = newErr("ID token not valid yet", CodeIDTokenNotValidYet)
+	ErrInvalidIDTokenACR      = newErr("insufficient ID token authentication context class", CodeInvalidIDTokenACR)
+	ErrInvalidIDTokenAuthTime = newErr("ID token authentication too old", CodeInvalidIDTokenAuthTime)
+	ErrInvalidUserInfoSubject = newErr("UserInfo subject does not match the ID token", CodeInvalidUserInfoSubject)
+	ErrInvalidLoginCSRFToken  = newErr("invalid login CSRF token", CodeInvalidLoginCSRFToken)
 )
 
 //nolint:recvcheck
@@ -126,6 +156,10 @@ func (e Error) HTTPStatus() int {
 	case CodeUnsupportedGrantType:
 		return http.StatusBadRequest
 
+	// RFC8693
+	case CodeInvalidTarget:
+		return http.StatusBadRequest
+
 	// Custom
 	case CodeUnknown:
 		return http.StatusInternalServerError
@@ -139,6 +173,24 @@ func (e Error) HTTPStatus() int {
 		return http.StatusPreconditionFailed
 	case CodeInvalidAtHashToken:
 		return http.StatusUnauthorized
+	case CodeInvalidNonce:
+		return http.StatusUnauthorized
+	case CodeInvalidIDTokenIssuer:
+		return http.StatusUnauthorized
+	case CodeInvalidIDTokenAudience:
+		return http.StatusUnauthorized
+	case CodeInvalidIDTokenAZP:
+		return http.StatusUnauthorized
+	case CodeIDTokenExpired:
+		return http.StatusUnauthorized
+	case CodeIDTokenNotValidYet:
+		return http.StatusUnauthorized
+	case CodeInvalidIDTokenACR:
+		return http.StatusUnauthorized
+	case CodeInvalidIDTokenAuthTime:
+		return http.StatusUnauthorized
+	case CodeInvalidUserInfoSubject:
+		return http.StatusUnauthorized
 	case CodeEndSessionNotSupported:
 		return http.StatusPreconditionFailed
 	case CodeInvalidCSRFToken:
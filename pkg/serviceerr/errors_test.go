@@ -110,6 +110,13 @@ func TestError_HTTPStatus(t *testing.T) {
 			expectedHTTPStatus: http.StatusBadRequest,
 		},
 
+		// RFC8693 codes
+		{
+			name:               "CodeInvalidTarget returns BadRequest",
+			code:               serviceerr.CodeInvalidTarget,
+			expectedHTTPStatus: http.StatusBadRequest,
+		},
+
 		// Custom codes
 		{
 			name:               "CodeUnknown returns InternalServerError",
@@ -146,6 +153,51 @@ func TestError_HTTPStatus(t *testing.T) {
 			code:               serviceerr.CodeInvalidAtHashToken,
 			expectedHTTPStatus: http.StatusUnauthorized,
 		},
+		{
+			name:               "CodeInvalidNonce returns Unauthorized",
+			code:               serviceerr.CodeInvalidNonce,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeInvalidIDTokenIssuer returns Unauthorized",
+			code:               serviceerr.CodeInvalidIDTokenIssuer,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeInvalidIDTokenAudience returns Unauthorized",
+			code:               serviceerr.CodeInvalidIDTokenAudience,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeInvalidIDTokenAZP returns Unauthorized",
+			code:               serviceerr.CodeInvalidIDTokenAZP,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeIDTokenExpired returns Unauthorized",
+			code:               serviceerr.CodeIDTokenExpired,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeIDTokenNotValidYet returns Unauthorized",
+			code:               serviceerr.CodeIDTokenNotValidYet,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeInvalidIDTokenACR returns StatusUnauthorized",
+			code:               serviceerr.CodeInvalidIDTokenACR,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeInvalidIDTokenAuthTime returns StatusUnauthorized",
+			code:               serviceerr.CodeInvalidIDTokenAuthTime,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:               "CodeInvalidUserInfoSubject returns Unauthorized",
+			code:               serviceerr.CodeInvalidUserInfoSubject,
+			expectedHTTPStatus: http.StatusUnauthorized,
+		},
 		{
 			name:               "CodeEndSessionNotSupported returns PreconditionFailed",
 			code:               serviceerr.CodeEndSessionNotSupported,
@@ -187,6 +239,7 @@ func TestPredefinedErrors(t *testing.T) {
 		{name: "ErrInvalidClient", err: serviceerr.ErrInvalidClient, expectedErr: serviceerr.CodeInvalidClient, hasDesc: false},
 		{name: "ErrInvalidGrant", err: serviceerr.ErrInvalidGrant, expectedErr: serviceerr.CodeInvalidGrant, hasDesc: false},
 		{name: "ErrUnsupportedGrantType", err: serviceerr.ErrUnsupportedGrantType, expectedErr: serviceerr.CodeUnsupportedGrantType, hasDesc: false},
+		{name: "ErrInvalidTarget", err: serviceerr.ErrInvalidTarget, expectedErr: serviceerr.CodeInvalidTarget, hasDesc: false},
 
 		// Custom errors
 		{name: "ErrUnknown", err: serviceerr.ErrUnknown, expectedErr: serviceerr.CodeUnknown, hasDesc: true},
@@ -197,6 +250,15 @@ func TestPredefinedErrors(t *testing.T) {
 		{name: "ErrInvalidCSRFToken", err: serviceerr.ErrInvalidCSRFToken, expectedErr: serviceerr.CodeInvalidCSRFToken, hasDesc: true},
 		{name: "ErrUnauthorized", err: serviceerr.ErrUnauthorized, expectedErr: serviceerr.CodeUnauthorizedClient, hasDesc: true},
 		{name: "ErrInvalidAtHash", err: serviceerr.ErrInvalidAtHash, expectedErr: serviceerr.CodeInvalidAtHashToken, hasDesc: true},
+		{name: "ErrInvalidNonce", err: serviceerr.ErrInvalidNonce, expectedErr: serviceerr.CodeInvalidNonce, hasDesc: true},
+		{name: "ErrInvalidIDTokenIssuer", err: serviceerr.ErrInvalidIDTokenIssuer, expectedErr: serviceerr.CodeInvalidIDTokenIssuer, hasDesc: true},
+		{name: "ErrInvalidIDTokenAud", err: serviceerr.ErrInvalidIDTokenAud, expectedErr: serviceerr.CodeInvalidIDTokenAudience, hasDesc: true},
+		{name: "ErrInvalidIDTokenAZP", err: serviceerr.ErrInvalidIDTokenAZP, expectedErr: serviceerr.CodeInvalidIDTokenAZP, hasDesc: true},
+		{name: "ErrIDTokenExpired", err: serviceerr.ErrIDTokenExpired, expectedErr: serviceerr.CodeIDTokenExpired, hasDesc: true},
+		{name: "ErrIDTokenNotValidYet", err: serviceerr.ErrIDTokenNotValidYet, expectedErr: serviceerr.CodeIDTokenNotValidYet, hasDesc: true},
+		{name: "ErrInvalidIDTokenACR", err: serviceerr.ErrInvalidIDTokenACR, expectedErr: serviceerr.CodeInvalidIDTokenACR, hasDesc: true},
+		{name: "ErrInvalidIDTokenAuthTime", err: serviceerr.ErrInvalidIDTokenAuthTime, expectedErr: serviceerr.CodeInvalidIDTokenAuthTime, hasDesc: true},
+		{name: "ErrInvalidUserInfoSubject", err: serviceerr.ErrInvalidUserInfoSubject, expectedErr: serviceerr.CodeInvalidUserInfoSubject, hasDesc: true},
 	}
 
 	for _, tt := range tests {
@@ -235,6 +297,7 @@ func TestErrorCodes(t *testing.T) {
 		{name: "CodeInvalidClient", code: serviceerr.CodeInvalidClient, expected: "invalid_client"},
 		{name: "CodeInvalidGrant", code: serviceerr.CodeInvalidGrant, expected: "invalid_grant"},
 		{name: "CodeUnsupportedGrantType", code: serviceerr.CodeUnsupportedGrantType, expected: "unsupported_grant_type"},
+		{name: "CodeInvalidTarget", code: serviceerr.CodeInvalidTarget, expected: "invalid_target"},
 
 		// Custom codes
 		{name: "CodeUnknown", code: serviceerr.CodeUnknown, expected: "unknown"},
@@ -245,6 +308,15 @@ func TestErrorCodes(t *testing.T) {
 		{name: "CodeInvalidCSRFToken", code: serviceerr.CodeInvalidCSRFToken, expected: "invalid_csrf_token"},
 		{name: "CodeInvalidAtHashToken", code: serviceerr.CodeInvalidAtHashToken, expected: "invalid_at_hash_token"},
 		{name: "CodeEndSessionNotSupported", code: serviceerr.CodeEndSessionNotSupported, expected: "end_session_not_supported"},
+		{name: "CodeInvalidNonce", code: serviceerr.CodeInvalidNonce, expected: "invalid_nonce"},
+		{name: "CodeInvalidIDTokenIssuer", code: serviceerr.CodeInvalidIDTokenIssuer, expected: "invalid_id_token_issuer"},
+		{name: "CodeInvalidIDTokenAudience", code: serviceerr.CodeInvalidIDTokenAudience, expected: "invalid_id_token_audience"},
+		{name: "CodeInvalidIDTokenAZP", code: serviceerr.CodeInvalidIDTokenAZP, expected: "invalid_id_token_azp"},
+		{name: "CodeIDTokenExpired", code: serviceerr.CodeIDTokenExpired, expected: "id_token_expired"},
+		{name: "CodeIDTokenNotValidYet", code: serviceerr.CodeIDTokenNotValidYet, expected: "id_token_not_valid_yet"},
+		{name: "CodeInvalidIDTokenACR", code: serviceerr.CodeInvalidIDTokenACR, expected: "invalid_id_token_acr"},
+		{name: "CodeInvalidIDTokenAuthTime", code: serviceerr.CodeInvalidIDTokenAuthTime, expected: "invalid_id_token_auth_time"},
+		{name: "CodeInvalidUserInfoSubject", code: serviceerr.CodeInvalidUserInfoSubject, expected: "invalid_userinfo_subject"},
 	}
 
 	for _, tc := range codes {
@@ -0,0 +1,184 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: kms/api/cmk/sessionmanager/csrf/v1/csrf.proto
+
+package csrfv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ValidateCSRFRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId *string                `protobuf:"bytes,1,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	TenantId  *string                `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId" json:"tenant_id,omitempty"`
+	// CSRF token taken from the request header of the client.
+	Token         *string `protobuf:"bytes,3,opt,name=token" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCSRFRequest) Reset() {
+	*x = ValidateCSRFRequest{}
+	mi := &file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCSRFRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCSRFRequest) ProtoMessage() {}
+
+func (x *ValidateCSRFRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCSRFRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCSRFRequest) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ValidateCSRFRequest) GetSessionId() string {
+	if x != nil && x.SessionId != nil {
+		return *x.SessionId
+	}
+	return ""
+}
+
+func (x *ValidateCSRFRequest) GetTenantId() string {
+	if x != nil && x.TenantId != nil {
+		return *x.TenantId
+	}
+	return ""
+}
+
+func (x *ValidateCSRFRequest) GetToken() string {
+	if x != nil && x.Token != nil {
+		return *x.Token
+	}
+	return ""
+}
+
+type ValidateCSRFResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCSRFResponse) Reset() {
+	*x = ValidateCSRFResponse{}
+	mi := &file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCSRFResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCSRFResponse) ProtoMessage() {}
+
+func (x *ValidateCSRFResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCSRFResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCSRFResponse) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescGZIP(), []int{1}
+}
+
+var File_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto protoreflect.FileDescriptor
+
+const file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDesc = "" +
+	"\n" +
+	"-kms/api/cmk/sessionmanager/csrf/v1/csrf.proto\x12\"kms.api.cmk.sessionmanager.csrf.v1\"g\n" +
+	"\x13ValidateCSRFRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\"\x16\n" +
+	"\x14ValidateCSRFResponse2\x8d\x01\n" +
+	"\aService\x12\x81\x01\n" +
+	"\fValidateCSRF\x127.kms.api.cmk.sessionmanager.csrf.v1.ValidateCSRFRequest\x1a8.kms.api.cmk.sessionmanager.csrf.v1.ValidateCSRFResponseBTZRgithub.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/csrf/v1;csrfv1b\beditionsp\xe8\a"
+
+var (
+	file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescOnce sync.Once
+	file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescData []byte
+)
+
+func file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescGZIP() []byte {
+	file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescOnce.Do(func() {
+		file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDesc)))
+	})
+	return file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDescData
+}
+
+var file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_goTypes = []any{
+	(*ValidateCSRFRequest)(nil),  // 0: kms.api.cmk.sessionmanager.csrf.v1.ValidateCSRFRequest
+	(*ValidateCSRFResponse)(nil), // 1: kms.api.cmk.sessionmanager.csrf.v1.ValidateCSRFResponse
+}
+var file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_depIdxs = []int32{
+	0, // 0: kms.api.cmk.sessionmanager.csrf.v1.Service.ValidateCSRF:input_type -> kms.api.cmk.sessionmanager.csrf.v1.ValidateCSRFRequest
+	1, // 1: kms.api.cmk.sessionmanager.csrf.v1.Service.ValidateCSRF:output_type -> kms.api.cmk.sessionmanager.csrf.v1.ValidateCSRFResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_init() }
+func file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_init() {
+	if File_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_goTypes,
+		DependencyIndexes: file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_depIdxs,
+		MessageInfos:      file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_msgTypes,
+	}.Build()
+	File_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto = out.File
+	file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_goTypes = nil
+	file_kms_api_cmk_sessionmanager_csrf_v1_csrf_proto_depIdxs = nil
+}
@@ -0,0 +1,135 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: kms/api/cmk/sessionmanager/csrf/v1/csrf.proto
+
+package csrfv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Service_ValidateCSRF_FullMethodName = "/kms.api.cmk.sessionmanager.csrf.v1.Service/ValidateCSRF"
+)
+
+// ServiceClient is the client API for Service service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Service validates the CSRF tokens of sessions so that downstream services do
+// not need to know the CSRF secret.
+type ServiceClient interface {
+	// ValidateCSRF checks the CSRF token sent by the client against the token
+	// of the session. A mismatch, an inactive session or a blocked tenant is
+	// returned as FAILED_PRECONDITION with a kms.api.cmk.rpc.v1.PreconditionFailure
+	// detail.
+	ValidateCSRF(ctx context.Context, in *ValidateCSRFRequest, opts ...grpc.CallOption) (*ValidateCSRFResponse, error)
+}
+
+type serviceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServiceClient(cc grpc.ClientConnInterface) ServiceClient {
+	return &serviceClient{cc}
+}
+
+func (c *serviceClient) ValidateCSRF(ctx context.Context, in *ValidateCSRFRequest, opts ...grpc.CallOption) (*ValidateCSRFResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateCSRFResponse)
+	err := c.cc.Invoke(ctx, Service_ValidateCSRF_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ServiceServer is the server API for Service service.
+// All implementations must embed UnimplementedServiceServer
+// for forward compatibility.
+//
+// Service validates the CSRF tokens of sessions so that downstream services do
+// not need to know the CSRF secret.
+type ServiceServer interface {
+	// ValidateCSRF checks the CSRF token sent by the client against the token
+	// of the session. A mismatch, an inactive session or a blocked tenant is
+	// returned as FAILED_PRECONDITION with a kms.api.cmk.rpc.v1.PreconditionFailure
+	// detail.
+	ValidateCSRF(context.Context, *ValidateCSRFRequest) (*ValidateCSRFResponse, error)
+	mustEmbedUnimplementedServiceServer()
+}
+
+// UnimplementedServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedServiceServer struct{}
+
+func (UnimplementedServiceServer) ValidateCSRF(context.Context, *ValidateCSRFRequest) (*ValidateCSRFResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateCSRF not implemented")
+}
+func (UnimplementedServiceServer) mustEmbedUnimplementedServiceServer() {}
+func (UnimplementedServiceServer) testEmbeddedByValue()                 {}
+
+// UnsafeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ServiceServer will
+// result in compilation errors.
+type UnsafeServiceServer interface {
+	mustEmbedUnimplementedServiceServer()
+}
+
+func RegisterServiceServer(s grpc.ServiceRegistrar, srv ServiceServer) {
+	// If the following call pancis, it indicates UnimplementedServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Service_ServiceDesc, srv)
+}
+
+func _Service_ValidateCSRF_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateCSRFRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ValidateCSRF(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ValidateCSRF_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ValidateCSRF(ctx, req.(*ValidateCSRFRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Service_ServiceDesc is the grpc.ServiceDesc for Service service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Service_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kms.api.cmk.sessionmanager.csrf.v1.Service",
+	HandlerType: (*ServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateCSRF",
+			Handler:    _Service_ValidateCSRF_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kms/api/cmk/sessionmanager/csrf/v1/csrf.proto",
+}
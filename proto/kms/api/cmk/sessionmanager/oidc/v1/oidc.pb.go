@@ -0,0 +1,244 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: kms/api/cmk/sessionmanager/oidc/v1/oidc.proto
+
+package smoidcv1
+
+import (
+	v1 "github.com/openkcm/api-sdk/proto/kms/api/cmk/trust/oidc/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ClaimMapping selects the claims of the session from the claims issued by the
+// provider. Each field holds a JSONPath-like member expression such as `oid`,
+// `realm_access.roles` or `$['https://example.com/groups']`. The default claim
+// is used for fields which are not set.
+type ClaimMapping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       *string                `protobuf:"bytes,1,opt,name=subject" json:"subject,omitempty"`
+	UserUuid      *string                `protobuf:"bytes,2,opt,name=user_uuid,json=userUuid" json:"user_uuid,omitempty"`
+	GivenName     *string                `protobuf:"bytes,3,opt,name=given_name,json=givenName" json:"given_name,omitempty"`
+	FamilyName    *string                `protobuf:"bytes,4,opt,name=family_name,json=familyName" json:"family_name,omitempty"`
+	Email         *string                `protobuf:"bytes,5,opt,name=email" json:"email,omitempty"`
+	Groups        *string                `protobuf:"bytes,6,opt,name=groups" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimMapping) Reset() {
+	*x = ClaimMapping{}
+	mi := &file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimMapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimMapping) ProtoMessage() {}
+
+func (x *ClaimMapping) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimMapping.ProtoReflect.Descriptor instead.
+func (*ClaimMapping) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ClaimMapping) GetSubject() string {
+	if x != nil && x.Subject != nil {
+		return *x.Subject
+	}
+	return ""
+}
+
+func (x *ClaimMapping) GetUserUuid() string {
+	if x != nil && x.UserUuid != nil {
+		return *x.UserUuid
+	}
+	return ""
+}
+
+func (x *ClaimMapping) GetGivenName() string {
+	if x != nil && x.GivenName != nil {
+		return *x.GivenName
+	}
+	return ""
+}
+
+func (x *ClaimMapping) GetFamilyName() string {
+	if x != nil && x.FamilyName != nil {
+		return *x.FamilyName
+	}
+	return ""
+}
+
+func (x *ClaimMapping) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *ClaimMapping) GetGroups() string {
+	if x != nil && x.Groups != nil {
+		return *x.Groups
+	}
+	return ""
+}
+
+var file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*v1.OIDC)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         100,
+		Name:          "kms.api.cmk.sessionmanager.oidc.v1.require_pushed_authorization_requests",
+		Tag:           "varint,100,opt,name=require_pushed_authorization_requests",
+		Filename:      "kms/api/cmk/sessionmanager/oidc/v1/oidc.proto",
+	},
+	{
+		ExtendedType:  (*v1.OIDC)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         101,
+		Name:          "kms.api.cmk.sessionmanager.oidc.v1.scopes",
+		Tag:           "bytes,101,rep,name=scopes",
+		Filename:      "kms/api/cmk/sessionmanager/oidc/v1/oidc.proto",
+	},
+	{
+		ExtendedType:  (*v1.OIDC)(nil),
+		ExtensionType: (*ClaimMapping)(nil),
+		Field:         102,
+		Name:          "kms.api.cmk.sessionmanager.oidc.v1.claim_mapping",
+		Tag:           "bytes,102,opt,name=claim_mapping",
+		Filename:      "kms/api/cmk/sessionmanager/oidc/v1/oidc.proto",
+	},
+	{
+		ExtendedType:  (*v1.OIDC)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         103,
+		Name:          "kms.api.cmk.sessionmanager.oidc.v1.fetch_userinfo",
+		Tag:           "varint,103,opt,name=fetch_userinfo",
+		Filename:      "kms/api/cmk/sessionmanager/oidc/v1/oidc.proto",
+	},
+}
+
+// Extension fields to v1.OIDC.
+var (
+	// Always use Pushed Authorization Requests (RFC 9126) for this trust and
+	// fail the login if the provider does not advertise a PAR endpoint.
+	//
+	// optional bool require_pushed_authorization_requests = 100;
+	E_RequirePushedAuthorizationRequests = &file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_extTypes[0]
+	// Scopes requested in the authorization requests for this trust. The
+	// default scopes of the session manager are requested if none are set.
+	//
+	// repeated string scopes = 101;
+	E_Scopes = &file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_extTypes[1]
+	// Maps the claims of the ID tokens and introspection responses of this
+	// trust to the claims of the sessions.
+	//
+	// optional kms.api.cmk.sessionmanager.oidc.v1.ClaimMapping claim_mapping = 102;
+	E_ClaimMapping = &file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_extTypes[2]
+	// Enrich the claims of the ID token with the claims returned by the
+	// UserInfo endpoint of the provider on login.
+	//
+	// optional bool fetch_userinfo = 103;
+	E_FetchUserinfo = &file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_extTypes[3]
+)
+
+var File_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto protoreflect.FileDescriptor
+
+const file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDesc = "" +
+	"\n" +
+	"-kms/api/cmk/sessionmanager/oidc/v1/oidc.proto\x12\"kms.api.cmk.sessionmanager.oidc.v1\x1a$kms/api/cmk/trust/oidc/v1/oidc.proto\"\xb3\x01\n" +
+	"\fClaimMapping\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x1b\n" +
+	"\tuser_uuid\x18\x02 \x01(\tR\buserUuid\x12\x1d\n" +
+	"\n" +
+	"given_name\x18\x03 \x01(\tR\tgivenName\x12\x1f\n" +
+	"\vfamily_name\x18\x04 \x01(\tR\n" +
+	"familyName\x12\x14\n" +
+	"\x05email\x18\x05 \x01(\tR\x05email\x12\x16\n" +
+	"\x06groups\x18\x06 \x01(\tR\x06groups:r\n" +
+	"%require_pushed_authorization_requests\x12\x1f.kms.api.cmk.trust.oidc.v1.OIDC\x18d \x01(\bR\"requirePushedAuthorizationRequests:7\n" +
+	"\x06scopes\x12\x1f.kms.api.cmk.trust.oidc.v1.OIDC\x18e \x03(\tR\x06scopes:v\n" +
+	"\rclaim_mapping\x12\x1f.kms.api.cmk.trust.oidc.v1.OIDC\x18f \x01(\v20.kms.api.cmk.sessionmanager.oidc.v1.ClaimMappingR\fclaimMapping:F\n" +
+	"\x0efetch_userinfo\x12\x1f.kms.api.cmk.trust.oidc.v1.OIDC\x18g \x01(\bR\rfetchUserinfoBVZTgithub.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/oidc/v1;smoidcv1b\beditionsp\xe8\a"
+
+var (
+	file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDescOnce sync.Once
+	file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDescData []byte
+)
+
+func file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDescGZIP() []byte {
+	file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDescOnce.Do(func() {
+		file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDesc)))
+	})
+	return file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDescData
+}
+
+var file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_goTypes = []any{
+	(*ClaimMapping)(nil), // 0: kms.api.cmk.sessionmanager.oidc.v1.ClaimMapping
+	(*v1.OIDC)(nil),      // 1: kms.api.cmk.trust.oidc.v1.OIDC
+}
+var file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_depIdxs = []int32{
+	1, // 0: kms.api.cmk.sessionmanager.oidc.v1.require_pushed_authorization_requests:extendee -> kms.api.cmk.trust.oidc.v1.OIDC
+	1, // 1: kms.api.cmk.sessionmanager.oidc.v1.scopes:extendee -> kms.api.cmk.trust.oidc.v1.OIDC
+	1, // 2: kms.api.cmk.sessionmanager.oidc.v1.claim_mapping:extendee -> kms.api.cmk.trust.oidc.v1.OIDC
+	1, // 3: kms.api.cmk.sessionmanager.oidc.v1.fetch_userinfo:extendee -> kms.api.cmk.trust.oidc.v1.OIDC
+	0, // 4: kms.api.cmk.sessionmanager.oidc.v1.claim_mapping:type_name -> kms.api.cmk.sessionmanager.oidc.v1.ClaimMapping
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	4, // [4:5] is the sub-list for extension type_name
+	0, // [0:4] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_init() }
+func file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_init() {
+	if File_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 4,
+			NumServices:   0,
+		},
+		GoTypes:           file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_goTypes,
+		DependencyIndexes: file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_depIdxs,
+		MessageInfos:      file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_msgTypes,
+		ExtensionInfos:    file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_extTypes,
+	}.Build()
+	File_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto = out.File
+	file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_goTypes = nil
+	file_kms_api_cmk_sessionmanager_oidc_v1_oidc_proto_depIdxs = nil
+}
@@ -0,0 +1,805 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: kms/api/cmk/sessionmanager/sessionadmin/v1/sessionadmin.proto
+
+package sessionadminv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Session struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId *string                `protobuf:"bytes,1,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	TenantId  *string                `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId" json:"tenant_id,omitempty"`
+	Issuer    *string                `protobuf:"bytes,3,opt,name=issuer" json:"issuer,omitempty"`
+	// Subject defined by the OIDC provider.
+	Subject    *string  `protobuf:"bytes,4,opt,name=subject" json:"subject,omitempty"`
+	Email      *string  `protobuf:"bytes,5,opt,name=email" json:"email,omitempty"`
+	GivenName  *string  `protobuf:"bytes,6,opt,name=given_name,json=givenName" json:"given_name,omitempty"`
+	FamilyName *string  `protobuf:"bytes,7,opt,name=family_name,json=familyName" json:"family_name,omitempty"`
+	Groups     []string `protobuf:"bytes,8,rep,name=groups" json:"groups,omitempty"`
+	// Expiry times in seconds since the Unix epoch. They are zero if unknown.
+	ExpiresAt             *int64 `protobuf:"varint,9,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	AccessTokenExpiresAt  *int64 `protobuf:"varint,10,opt,name=access_token_expires_at,json=accessTokenExpiresAt" json:"access_token_expires_at,omitempty"`
+	RefreshTokenExpiresAt *int64 `protobuf:"varint,11,opt,name=refresh_token_expires_at,json=refreshTokenExpiresAt" json:"refresh_token_expires_at,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Session) GetSessionId() string {
+	if x != nil && x.SessionId != nil {
+		return *x.SessionId
+	}
+	return ""
+}
+
+func (x *Session) GetTenantId() string {
+	if x != nil && x.TenantId != nil {
+		return *x.TenantId
+	}
+	return ""
+}
+
+func (x *Session) GetIssuer() string {
+	if x != nil && x.Issuer != nil {
+		return *x.Issuer
+	}
+	return ""
+}
+
+func (x *Session) GetSubject() string {
+	if x != nil && x.Subject != nil {
+		return *x.Subject
+	}
+	return ""
+}
+
+func (x *Session) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *Session) GetGivenName() string {
+	if x != nil && x.GivenName != nil {
+		return *x.GivenName
+	}
+	return ""
+}
+
+func (x *Session) GetFamilyName() string {
+	if x != nil && x.FamilyName != nil {
+		return *x.FamilyName
+	}
+	return ""
+}
+
+func (x *Session) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *Session) GetExpiresAt() int64 {
+	if x != nil && x.ExpiresAt != nil {
+		return *x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *Session) GetAccessTokenExpiresAt() int64 {
+	if x != nil && x.AccessTokenExpiresAt != nil {
+		return *x.AccessTokenExpiresAt
+	}
+	return 0
+}
+
+func (x *Session) GetRefreshTokenExpiresAt() int64 {
+	if x != nil && x.RefreshTokenExpiresAt != nil {
+		return *x.RefreshTokenExpiresAt
+	}
+	return 0
+}
+
+type ListSessionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identity of the operator on whose behalf the caller acts. It is required
+	// and recorded along with the caller in the audit events.
+	Operator *string `protobuf:"bytes,1,opt,name=operator" json:"operator,omitempty"`
+	// Optional filters which must all match.
+	TenantId *string `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId" json:"tenant_id,omitempty"`
+	Subject  *string `protobuf:"bytes,3,opt,name=subject" json:"subject,omitempty"`
+	Email    *string `protobuf:"bytes,4,opt,name=email" json:"email,omitempty"`
+	Issuer   *string `protobuf:"bytes,5,opt,name=issuer" json:"issuer,omitempty"`
+	// Maximum number of sessions to return. Defaults to 50, at most 500.
+	PageSize *int32 `protobuf:"varint,6,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	// Token of the page to return as returned by a previous call.
+	PageToken     *string `protobuf:"bytes,7,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListSessionsRequest) GetOperator() string {
+	if x != nil && x.Operator != nil {
+		return *x.Operator
+	}
+	return ""
+}
+
+func (x *ListSessionsRequest) GetTenantId() string {
+	if x != nil && x.TenantId != nil {
+		return *x.TenantId
+	}
+	return ""
+}
+
+func (x *ListSessionsRequest) GetSubject() string {
+	if x != nil && x.Subject != nil {
+		return *x.Subject
+	}
+	return ""
+}
+
+func (x *ListSessionsRequest) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *ListSessionsRequest) GetIssuer() string {
+	if x != nil && x.Issuer != nil {
+		return *x.Issuer
+	}
+	return ""
+}
+
+func (x *ListSessionsRequest) GetPageSize() int32 {
+	if x != nil && x.PageSize != nil {
+		return *x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSessionsRequest) GetPageToken() string {
+	if x != nil && x.PageToken != nil {
+		return *x.PageToken
+	}
+	return ""
+}
+
+type ListSessionsResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Sessions []*Session             `protobuf:"bytes,1,rep,name=sessions" json:"sessions,omitempty"`
+	// Token of the next page. It is empty on the last page.
+	NextPageToken *string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+func (x *ListSessionsResponse) GetNextPageToken() string {
+	if x != nil && x.NextPageToken != nil {
+		return *x.NextPageToken
+	}
+	return ""
+}
+
+type GetSessionDetailsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identity of the operator on whose behalf the caller acts. It is required
+	// and recorded along with the caller in the audit events.
+	Operator      *string `protobuf:"bytes,1,opt,name=operator" json:"operator,omitempty"`
+	SessionId     *string `protobuf:"bytes,2,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionDetailsRequest) Reset() {
+	*x = GetSessionDetailsRequest{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionDetailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionDetailsRequest) ProtoMessage() {}
+
+func (x *GetSessionDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionDetailsRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSessionDetailsRequest) GetOperator() string {
+	if x != nil && x.Operator != nil {
+		return *x.Operator
+	}
+	return ""
+}
+
+func (x *GetSessionDetailsRequest) GetSessionId() string {
+	if x != nil && x.SessionId != nil {
+		return *x.SessionId
+	}
+	return ""
+}
+
+type GetSessionDetailsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Session *Session               `protobuf:"bytes,1,opt,name=session" json:"session,omitempty"`
+	// Tokens of the session with all but a few characters redacted.
+	AccessToken  *string `protobuf:"bytes,2,opt,name=access_token,json=accessToken" json:"access_token,omitempty"`
+	RefreshToken *string `protobuf:"bytes,3,opt,name=refresh_token,json=refreshToken" json:"refresh_token,omitempty"`
+	CsrfToken    *string `protobuf:"bytes,4,opt,name=csrf_token,json=csrfToken" json:"csrf_token,omitempty"`
+	// Whether the session has been used within the idle timeout.
+	Active *bool `protobuf:"varint,5,opt,name=active" json:"active,omitempty"`
+	// Provider session ID (sid claim).
+	ProviderSessionId *string `protobuf:"bytes,6,opt,name=provider_session_id,json=providerSessionId" json:"provider_session_id,omitempty"`
+	// Authentication context class reference and methods of the login.
+	Acr *string  `protobuf:"bytes,7,opt,name=acr" json:"acr,omitempty"`
+	Amr []string `protobuf:"bytes,8,rep,name=amr" json:"amr,omitempty"`
+	// Time of the user authentication in seconds since the Unix epoch.
+	AuthTime      *int64 `protobuf:"varint,9,opt,name=auth_time,json=authTime" json:"auth_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionDetailsResponse) Reset() {
+	*x = GetSessionDetailsResponse{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionDetailsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionDetailsResponse) ProtoMessage() {}
+
+func (x *GetSessionDetailsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionDetailsResponse.ProtoReflect.Descriptor instead.
+func (*GetSessionDetailsResponse) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetSessionDetailsResponse) GetSession() *Session {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *GetSessionDetailsResponse) GetAccessToken() string {
+	if x != nil && x.AccessToken != nil {
+		return *x.AccessToken
+	}
+	return ""
+}
+
+func (x *GetSessionDetailsResponse) GetRefreshToken() string {
+	if x != nil && x.RefreshToken != nil {
+		return *x.RefreshToken
+	}
+	return ""
+}
+
+func (x *GetSessionDetailsResponse) GetCsrfToken() string {
+	if x != nil && x.CsrfToken != nil {
+		return *x.CsrfToken
+	}
+	return ""
+}
+
+func (x *GetSessionDetailsResponse) GetActive() bool {
+	if x != nil && x.Active != nil {
+		return *x.Active
+	}
+	return false
+}
+
+func (x *GetSessionDetailsResponse) GetProviderSessionId() string {
+	if x != nil && x.ProviderSessionId != nil {
+		return *x.ProviderSessionId
+	}
+	return ""
+}
+
+func (x *GetSessionDetailsResponse) GetAcr() string {
+	if x != nil && x.Acr != nil {
+		return *x.Acr
+	}
+	return ""
+}
+
+func (x *GetSessionDetailsResponse) GetAmr() []string {
+	if x != nil {
+		return x.Amr
+	}
+	return nil
+}
+
+func (x *GetSessionDetailsResponse) GetAuthTime() int64 {
+	if x != nil && x.AuthTime != nil {
+		return *x.AuthTime
+	}
+	return 0
+}
+
+type RevokeSessionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identity of the operator on whose behalf the caller acts. It is required
+	// and recorded along with the caller in the audit events.
+	Operator      *string `protobuf:"bytes,1,opt,name=operator" json:"operator,omitempty"`
+	SessionId     *string `protobuf:"bytes,2,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSessionRequest) Reset() {
+	*x = RevokeSessionRequest{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionRequest) ProtoMessage() {}
+
+func (x *RevokeSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSessionRequest.ProtoReflect.Descriptor instead.
+func (*RevokeSessionRequest) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RevokeSessionRequest) GetOperator() string {
+	if x != nil && x.Operator != nil {
+		return *x.Operator
+	}
+	return ""
+}
+
+func (x *RevokeSessionRequest) GetSessionId() string {
+	if x != nil && x.SessionId != nil {
+		return *x.SessionId
+	}
+	return ""
+}
+
+type RevokeSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSessionResponse) Reset() {
+	*x = RevokeSessionResponse{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionResponse) ProtoMessage() {}
+
+func (x *RevokeSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSessionResponse.ProtoReflect.Descriptor instead.
+func (*RevokeSessionResponse) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{6}
+}
+
+type RevokeAllSessionsForUserRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identity of the operator on whose behalf the caller acts. It is required
+	// and recorded along with the caller in the audit events.
+	Operator *string `protobuf:"bytes,1,opt,name=operator" json:"operator,omitempty"`
+	Issuer   *string `protobuf:"bytes,2,opt,name=issuer" json:"issuer,omitempty"`
+	// Subject defined by the OIDC provider.
+	Subject *string `protobuf:"bytes,3,opt,name=subject" json:"subject,omitempty"`
+	// Optional tenant to restrict the revocation to.
+	TenantId      *string `protobuf:"bytes,4,opt,name=tenant_id,json=tenantId" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAllSessionsForUserRequest) Reset() {
+	*x = RevokeAllSessionsForUserRequest{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAllSessionsForUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAllSessionsForUserRequest) ProtoMessage() {}
+
+func (x *RevokeAllSessionsForUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAllSessionsForUserRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAllSessionsForUserRequest) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RevokeAllSessionsForUserRequest) GetOperator() string {
+	if x != nil && x.Operator != nil {
+		return *x.Operator
+	}
+	return ""
+}
+
+func (x *RevokeAllSessionsForUserRequest) GetIssuer() string {
+	if x != nil && x.Issuer != nil {
+		return *x.Issuer
+	}
+	return ""
+}
+
+func (x *RevokeAllSessionsForUserRequest) GetSubject() string {
+	if x != nil && x.Subject != nil {
+		return *x.Subject
+	}
+	return ""
+}
+
+func (x *RevokeAllSessionsForUserRequest) GetTenantId() string {
+	if x != nil && x.TenantId != nil {
+		return *x.TenantId
+	}
+	return ""
+}
+
+type RevokeAllSessionsForUserResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of revoked sessions.
+	RevokedSessions *int32 `protobuf:"varint,1,opt,name=revoked_sessions,json=revokedSessions" json:"revoked_sessions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RevokeAllSessionsForUserResponse) Reset() {
+	*x = RevokeAllSessionsForUserResponse{}
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAllSessionsForUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAllSessionsForUserResponse) ProtoMessage() {}
+
+func (x *RevokeAllSessionsForUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAllSessionsForUserResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAllSessionsForUserResponse) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RevokeAllSessionsForUserResponse) GetRevokedSessions() int32 {
+	if x != nil && x.RevokedSessions != nil {
+		return *x.RevokedSessions
+	}
+	return 0
+}
+
+var File_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto protoreflect.FileDescriptor
+
+const file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDesc = "" +
+	"\n" +
+	"=kms/api/cmk/sessionmanager/sessionadmin/v1/sessionadmin.proto\x12*kms.api.cmk.sessionmanager.sessionadmin.v1\"\xf4\x02\n" +
+	"\aSession\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06issuer\x18\x03 \x01(\tR\x06issuer\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x14\n" +
+	"\x05email\x18\x05 \x01(\tR\x05email\x12\x1d\n" +
+	"\n" +
+	"given_name\x18\x06 \x01(\tR\tgivenName\x12\x1f\n" +
+	"\vfamily_name\x18\a \x01(\tR\n" +
+	"familyName\x12\x16\n" +
+	"\x06groups\x18\b \x03(\tR\x06groups\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\x03R\texpiresAt\x125\n" +
+	"\x17access_token_expires_at\x18\n" +
+	" \x01(\x03R\x14accessTokenExpiresAt\x127\n" +
+	"\x18refresh_token_expires_at\x18\v \x01(\x03R\x15refreshTokenExpiresAt\"\xd2\x01\n" +
+	"\x13ListSessionsRequest\x12\x1a\n" +
+	"\boperator\x18\x01 \x01(\tR\boperator\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12\x16\n" +
+	"\x06issuer\x18\x05 \x01(\tR\x06issuer\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\a \x01(\tR\tpageToken\"\x8f\x01\n" +
+	"\x14ListSessionsResponse\x12O\n" +
+	"\bsessions\x18\x01 \x03(\v23.kms.api.cmk.sessionmanager.sessionadmin.v1.SessionR\bsessions\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"U\n" +
+	"\x18GetSessionDetailsRequest\x12\x1a\n" +
+	"\boperator\x18\x01 \x01(\tR\boperator\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\"\xda\x02\n" +
+	"\x19GetSessionDetailsResponse\x12M\n" +
+	"\asession\x18\x01 \x01(\v23.kms.api.cmk.sessionmanager.sessionadmin.v1.SessionR\asession\x12!\n" +
+	"\faccess_token\x18\x02 \x01(\tR\vaccessToken\x12#\n" +
+	"\rrefresh_token\x18\x03 \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"csrf_token\x18\x04 \x01(\tR\tcsrfToken\x12\x16\n" +
+	"\x06active\x18\x05 \x01(\bR\x06active\x12.\n" +
+	"\x13provider_session_id\x18\x06 \x01(\tR\x11providerSessionId\x12\x10\n" +
+	"\x03acr\x18\a \x01(\tR\x03acr\x12\x10\n" +
+	"\x03amr\x18\b \x03(\tR\x03amr\x12\x1b\n" +
+	"\tauth_time\x18\t \x01(\x03R\bauthTime\"Q\n" +
+	"\x14RevokeSessionRequest\x12\x1a\n" +
+	"\boperator\x18\x01 \x01(\tR\boperator\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\"\x17\n" +
+	"\x15RevokeSessionResponse\"\x8c\x01\n" +
+	"\x1fRevokeAllSessionsForUserRequest\x12\x1a\n" +
+	"\boperator\x18\x01 \x01(\tR\boperator\x12\x16\n" +
+	"\x06issuer\x18\x02 \x01(\tR\x06issuer\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\x12\x1b\n" +
+	"\ttenant_id\x18\x04 \x01(\tR\btenantId\"M\n" +
+	" RevokeAllSessionsForUserResponse\x12)\n" +
+	"\x10revoked_sessions\x18\x01 \x01(\x05R\x0frevokedSessions2\x8f\x05\n" +
+	"\aService\x12\x91\x01\n" +
+	"\fListSessions\x12?.kms.api.cmk.sessionmanager.sessionadmin.v1.ListSessionsRequest\x1a@.kms.api.cmk.sessionmanager.sessionadmin.v1.ListSessionsResponse\x12\xa0\x01\n" +
+	"\x11GetSessionDetails\x12D.kms.api.cmk.sessionmanager.sessionadmin.v1.GetSessionDetailsRequest\x1aE.kms.api.cmk.sessionmanager.sessionadmin.v1.GetSessionDetailsResponse\x12\x94\x01\n" +
+	"\rRevokeSession\x12@.kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeSessionRequest\x1aA.kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeSessionResponse\x12\xb5\x01\n" +
+	"\x18RevokeAllSessionsForUser\x12K.kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeAllSessionsForUserRequest\x1aL.kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeAllSessionsForUserResponseBdZbgithub.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/sessionadmin/v1;sessionadminv1b\beditionsp\xe8\a"
+
+var (
+	file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescOnce sync.Once
+	file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescData []byte
+)
+
+func file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescGZIP() []byte {
+	file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescOnce.Do(func() {
+		file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDesc)))
+	})
+	return file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDescData
+}
+
+var file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_goTypes = []any{
+	(*Session)(nil),                          // 0: kms.api.cmk.sessionmanager.sessionadmin.v1.Session
+	(*ListSessionsRequest)(nil),              // 1: kms.api.cmk.sessionmanager.sessionadmin.v1.ListSessionsRequest
+	(*ListSessionsResponse)(nil),             // 2: kms.api.cmk.sessionmanager.sessionadmin.v1.ListSessionsResponse
+	(*GetSessionDetailsRequest)(nil),         // 3: kms.api.cmk.sessionmanager.sessionadmin.v1.GetSessionDetailsRequest
+	(*GetSessionDetailsResponse)(nil),        // 4: kms.api.cmk.sessionmanager.sessionadmin.v1.GetSessionDetailsResponse
+	(*RevokeSessionRequest)(nil),             // 5: kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeSessionRequest
+	(*RevokeSessionResponse)(nil),            // 6: kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeSessionResponse
+	(*RevokeAllSessionsForUserRequest)(nil),  // 7: kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeAllSessionsForUserRequest
+	(*RevokeAllSessionsForUserResponse)(nil), // 8: kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeAllSessionsForUserResponse
+}
+var file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_depIdxs = []int32{
+	0, // 0: kms.api.cmk.sessionmanager.sessionadmin.v1.ListSessionsResponse.sessions:type_name -> kms.api.cmk.sessionmanager.sessionadmin.v1.Session
+	0, // 1: kms.api.cmk.sessionmanager.sessionadmin.v1.GetSessionDetailsResponse.session:type_name -> kms.api.cmk.sessionmanager.sessionadmin.v1.Session
+	1, // 2: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.ListSessions:input_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.ListSessionsRequest
+	3, // 3: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.GetSessionDetails:input_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.GetSessionDetailsRequest
+	5, // 4: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.RevokeSession:input_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeSessionRequest
+	7, // 5: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.RevokeAllSessionsForUser:input_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeAllSessionsForUserRequest
+	2, // 6: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.ListSessions:output_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.ListSessionsResponse
+	4, // 7: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.GetSessionDetails:output_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.GetSessionDetailsResponse
+	6, // 8: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.RevokeSession:output_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeSessionResponse
+	8, // 9: kms.api.cmk.sessionmanager.sessionadmin.v1.Service.RevokeAllSessionsForUser:output_type -> kms.api.cmk.sessionmanager.sessionadmin.v1.RevokeAllSessionsForUserResponse
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_init() }
+func file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_init() {
+	if File_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_goTypes,
+		DependencyIndexes: file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_depIdxs,
+		MessageInfos:      file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_msgTypes,
+	}.Build()
+	File_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto = out.File
+	file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_goTypes = nil
+	file_kms_api_cmk_sessionmanager_sessionadmin_v1_sessionadmin_proto_depIdxs = nil
+}
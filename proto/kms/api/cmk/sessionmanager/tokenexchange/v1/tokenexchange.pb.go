@@ -0,0 +1,228 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: kms/api/cmk/sessionmanager/tokenexchange/v1/tokenexchange.proto
+
+package tokenexchangev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ExchangeTokenRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId *string                `protobuf:"bytes,1,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	TenantId  *string                `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId" json:"tenant_id,omitempty"`
+	// Logical name of the downstream service the token is requested for. It is
+	// passed as the audience parameter of the token exchange.
+	Audience      *string `protobuf:"bytes,3,opt,name=audience" json:"audience,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExchangeTokenRequest) Reset() {
+	*x = ExchangeTokenRequest{}
+	mi := &file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExchangeTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExchangeTokenRequest) ProtoMessage() {}
+
+func (x *ExchangeTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExchangeTokenRequest.ProtoReflect.Descriptor instead.
+func (*ExchangeTokenRequest) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExchangeTokenRequest) GetSessionId() string {
+	if x != nil && x.SessionId != nil {
+		return *x.SessionId
+	}
+	return ""
+}
+
+func (x *ExchangeTokenRequest) GetTenantId() string {
+	if x != nil && x.TenantId != nil {
+		return *x.TenantId
+	}
+	return ""
+}
+
+func (x *ExchangeTokenRequest) GetAudience() string {
+	if x != nil && x.Audience != nil {
+		return *x.Audience
+	}
+	return ""
+}
+
+type ExchangeTokenResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AccessToken *string                `protobuf:"bytes,1,opt,name=access_token,json=accessToken" json:"access_token,omitempty"`
+	// Token type identifier of the issued token, e.g.
+	// urn:ietf:params:oauth:token-type:access_token.
+	IssuedTokenType *string `protobuf:"bytes,2,opt,name=issued_token_type,json=issuedTokenType" json:"issued_token_type,omitempty"`
+	// Usage of the issued token, e.g. Bearer.
+	TokenType *string `protobuf:"bytes,3,opt,name=token_type,json=tokenType" json:"token_type,omitempty"`
+	// Expiry time of the issued token in seconds since the Unix epoch. It is
+	// zero if the provider does not return the lifetime of the token.
+	ExpiresAt     *int64 `protobuf:"varint,4,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExchangeTokenResponse) Reset() {
+	*x = ExchangeTokenResponse{}
+	mi := &file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExchangeTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExchangeTokenResponse) ProtoMessage() {}
+
+func (x *ExchangeTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExchangeTokenResponse.ProtoReflect.Descriptor instead.
+func (*ExchangeTokenResponse) Descriptor() ([]byte, []int) {
+	return file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExchangeTokenResponse) GetAccessToken() string {
+	if x != nil && x.AccessToken != nil {
+		return *x.AccessToken
+	}
+	return ""
+}
+
+func (x *ExchangeTokenResponse) GetIssuedTokenType() string {
+	if x != nil && x.IssuedTokenType != nil {
+		return *x.IssuedTokenType
+	}
+	return ""
+}
+
+func (x *ExchangeTokenResponse) GetTokenType() string {
+	if x != nil && x.TokenType != nil {
+		return *x.TokenType
+	}
+	return ""
+}
+
+func (x *ExchangeTokenResponse) GetExpiresAt() int64 {
+	if x != nil && x.ExpiresAt != nil {
+		return *x.ExpiresAt
+	}
+	return 0
+}
+
+var File_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto protoreflect.FileDescriptor
+
+const file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDesc = "" +
+	"\n" +
+	"?kms/api/cmk/sessionmanager/tokenexchange/v1/tokenexchange.proto\x12+kms.api.cmk.sessionmanager.tokenexchange.v1\"n\n" +
+	"\x14ExchangeTokenRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x1a\n" +
+	"\baudience\x18\x03 \x01(\tR\baudience\"\xa4\x01\n" +
+	"\x15ExchangeTokenResponse\x12!\n" +
+	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12*\n" +
+	"\x11issued_token_type\x18\x02 \x01(\tR\x0fissuedTokenType\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x03 \x01(\tR\ttokenType\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt2\xa2\x01\n" +
+	"\aService\x12\x96\x01\n" +
+	"\rExchangeToken\x12A.kms.api.cmk.sessionmanager.tokenexchange.v1.ExchangeTokenRequest\x1aB.kms.api.cmk.sessionmanager.tokenexchange.v1.ExchangeTokenResponseBfZdgithub.com/openkcm/session-manager/proto/kms/api/cmk/sessionmanager/tokenexchange/v1;tokenexchangev1b\beditionsp\xe8\a"
+
+var (
+	file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescOnce sync.Once
+	file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescData []byte
+)
+
+func file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescGZIP() []byte {
+	file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescOnce.Do(func() {
+		file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDesc)))
+	})
+	return file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDescData
+}
+
+var file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_goTypes = []any{
+	(*ExchangeTokenRequest)(nil),  // 0: kms.api.cmk.sessionmanager.tokenexchange.v1.ExchangeTokenRequest
+	(*ExchangeTokenResponse)(nil), // 1: kms.api.cmk.sessionmanager.tokenexchange.v1.ExchangeTokenResponse
+}
+var file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_depIdxs = []int32{
+	0, // 0: kms.api.cmk.sessionmanager.tokenexchange.v1.Service.ExchangeToken:input_type -> kms.api.cmk.sessionmanager.tokenexchange.v1.ExchangeTokenRequest
+	1, // 1: kms.api.cmk.sessionmanager.tokenexchange.v1.Service.ExchangeToken:output_type -> kms.api.cmk.sessionmanager.tokenexchange.v1.ExchangeTokenResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_init() }
+func file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_init() {
+	if File_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDesc), len(file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_goTypes,
+		DependencyIndexes: file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_depIdxs,
+		MessageInfos:      file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_msgTypes,
+	}.Build()
+	File_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto = out.File
+	file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_goTypes = nil
+	file_kms_api_cmk_sessionmanager_tokenexchange_v1_tokenexchange_proto_depIdxs = nil
+}
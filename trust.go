@@ -21,4 +21,6 @@ type Trust interface {
 	Unblock(ctx context.Context, tenantID string) error
 	// Get returns a trust message with optional extensions set.
 	Get(ctx context.Context, tenantID string) (*trustv1.Trust, error)
+	// ListByIssuer returns the trusts of all tenants trusting the given issuer.
+	ListByIssuer(ctx context.Context, issuer string) ([]*trustv1.Trust, error)
 }